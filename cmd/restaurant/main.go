@@ -3,6 +3,7 @@ package restaurant
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 
@@ -12,8 +13,16 @@ import (
 )
 
 var (
-	helpFlag   = flag.Bool("help", false, "Show help message")
-	configPath = flag.String("config-path", "config.yaml", "Path to the config yaml file")
+	helpFlag        = flag.Bool("help", false, "Show help message")
+	configPath      = flag.String("config-path", "config.yaml", "Path to the config yaml file")
+	printConfigFlag = flag.Bool("print-config", false, "Print effective configuration and exit")
+	backfillFlag    = flag.Bool("backfill-customers", false, "Link existing orders to customer accounts by name and exit")
+
+	importFlag        = flag.String("import-orders", "", "Import orders of a JSONL file into a running order-service and exit")
+	importURL         = flag.String("import-url", "http://localhost:3000", "URL of the order-service orders are imported into")
+	importLocation    = flag.String("import-location", "", "Location of the imported orders (default: location of the order-service)")
+	importBatchSize   = flag.Int("import-batch-size", 100, "Orders sent in one batch request, at most 500")
+	importConcurrency = flag.Int("import-concurrency", 4, "Batch requests sent at the same time")
 )
 
 func Run() {
@@ -25,16 +34,37 @@ func Run() {
 
 	ctx := context.Background()
 
+	// Import only talks to the order-service API, it needs no config.
+	if *importFlag != "" {
+		runImport(ctx)
+		return
+	}
+
 	// Init config
 	cfg, err := config.New(*configPath)
 	if err != nil {
-		log.Fatal("failed to configure application", err)
 		config.PrintHelp()
+		log.Fatal("failed to configure application: ", err)
+	}
+
+	if *printConfigFlag {
+		config.PrintConfig(cfg)
+		return
 	}
 
 	// Init logger
 	logger := logger.InitLogger(string(cfg.Mode), cfg.LogLevel)
 
+	if *backfillFlag {
+		linked, err := app.BackfillCustomers(ctx, *cfg, logger)
+		if err != nil {
+			logger.Error(ctx, "backfill_customers", "failed to backfill customers", err)
+			os.Exit(1)
+		}
+		fmt.Printf("linked %d orders to customers\n", linked)
+		return
+	}
+
 	config.PrintConfig(cfg)
 
 	// Creating application
@@ -51,3 +81,24 @@ func Run() {
 		os.Exit(1)
 	}
 }
+
+func runImport(ctx context.Context) {
+	if *importBatchSize < 1 || *importBatchSize > 500 {
+		log.Fatal("--import-batch-size must be between 1 and 500")
+	}
+	if *importConcurrency < 1 {
+		log.Fatal("--import-concurrency must be positive")
+	}
+
+	logger := logger.InitLogger("order-import", logger.LevelInfo)
+
+	summary, err := app.ImportOrders(ctx, *importFlag, *importURL, *importLocation, *importBatchSize, *importConcurrency, logger)
+	if err != nil {
+		logger.Error(ctx, "import_orders", "failed to import orders", err)
+	}
+	fmt.Printf("imported %d of %d orders: %d invalid, %d failed\n", summary.Created, summary.Total, summary.Invalid, summary.Failed)
+
+	if err != nil || summary.Failed > 0 {
+		os.Exit(1)
+	}
+}
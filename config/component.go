@@ -0,0 +1,36 @@
+package config
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// Component returns config of a service run by --mode=all. It is the config of the same service run alone:
+// mode is the mode of the service, tracking-service listens on --tracking-port and a kitchen worker gets
+// name and order types of worker. worker is used only for kitchen-worker.
+func (c *Config) Component(mode types.ServiceMode, worker models.KitchenWorkerSpec) Config {
+	derive := func(cfg *Config) {
+		cfg.Mode = mode
+
+		switch mode {
+		case types.ModeTracking:
+			cfg.HTTPServer.Port = cfg.Services.All.TrackingPort
+		case types.ModeKitchenWorker:
+			cfg.Services.Kitchen.WorkerName = worker.Name
+			if len(worker.OrderTypes) > 0 {
+				cfg.Services.Kitchen.OrderTypes = strings.Join(worker.OrderTypes, ",")
+			}
+		}
+	}
+
+	component := *c
+	// Components are reloaded concurrently, each one keeps its own sources.
+	component.sources = maps.Clone(c.sources)
+	component.derive = derive
+	derive(&component)
+
+	return component
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/configparser"
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
@@ -18,135 +19,223 @@ const (
 	DefaultTrackingServicePort = 3002
 )
 
-var (
-	// General
-	modeFlag = flag.String("mode", "", "application mode")
-	portFlag = flag.Int("port", -1, "The HTTP port for the API")
-	logLevel = flag.String("log-level", logger.LevelDebug, "Logger level. (DEBUG, INFO, WARN, ERROR)")
-
-	// Order service
-	maxConcurrent = flag.Int("max-concurrent", 50, "Maximum number of concurrent orders to process.")
-
-	// Kitchen service
-	workerName   = flag.String("worker-name", "", "unique name for the worker (e.g., chef_mario) (required)")
-	orderTypes   = flag.String("order-types", "", "comma-separated list of order types the worker can handle (e.g., dine_in,takeout)")
-	heartbeatInt = flag.Int("heartbeat-interval", 30, "interval (seconds) between heartbeats")
-	prefetch     = flag.Int("prefetch", 1, "RabbitMQ prefetch count")
-)
-
 var (
 	ErrModeNotProvided = errors.New("mode flag not provided")
 	ErrInvalidModeFlag = errors.New("invalid mode flag")
 )
 
+// Every field with `env` tag is a config option. Its value is taken from (in order of precedence)
+// the `flag` command line flag, the `env` environment variable, the yaml file (the `env` key
+// flattened, e.g. kitchen.reconnect.delay -> KITCHEN_RECONNECT_DELAY) and the `default` tag.
 type (
 	// Config
 	Config struct {
-		Mode       types.ServiceMode
+		Mode       types.ServiceMode `env:"MODE" flag:"mode" usage:"Application mode (order-service, kitchen-worker, tracking-service, notification-subscriber, payment-service, loadgen, all)"`
 		Services   Services
 		HTTPServer HTTPServer
 		Postgres   postgres.Config
 		RabbitMQ   RabbitMQ
 
-		LogLevel string
+		Location string `env:"LOCATION" flag:"location" default:"main" usage:"Restaurant location id the service works for (a-z, 0-9, '_', '-')"`
+
+		// ConfigWatch enables polling of the config file for changes. Changes are applied the same way as on SIGHUP.
+		ConfigWatch time.Duration `env:"CONFIG_WATCH" flag:"config-watch" usage:"Interval to check config file for changes, 0 disables (SIGHUP always reloads)"`
+
+		LogLevel string `env:"LOG_LEVEL" flag:"log-level" default:"DEBUG" reload:"true" usage:"Logger level (DEBUG, INFO, WARN, ERROR)"`
+
+		path    string                         // path to the yaml file, used on reload
+		sources map[string]configparser.Source // field path -> source of the effective value
+		derive  func(cfg *Config)              // sets component settings of --mode=all, applied on reload too
 	}
 
 	Services struct {
 		Order    OrderService
 		Kitchen  KitchenService
 		Tracking TrackingService
+		Payment  PaymentService
+		LoadGen  LoadGenService
+		All      AllService
 	}
 
 	// HTTP service
 	HTTPServer struct {
-		Port int
+		Port int `env:"HTTP_PORT" flag:"port" usage:"HTTP port (default: 3000 for order-service, 3002 for tracking-service)"`
 	}
 
 	OrderService struct {
-		MaxConcurrent int
-		SemWait       time.Duration `env:"ORDER_SEMWAIT" default:"1s"`
+		MaxConcurrent     int           `env:"ORDER_MAX_CONCURRENT" flag:"max-concurrent" default:"50" reload:"true" usage:"Max concurrent orders"`
+		SemWait           time.Duration `env:"ORDER_SEMWAIT" flag:"order-semwait" default:"1s" reload:"true" usage:"How long an order waits for a free slot"`
+		AgingInterval     time.Duration `env:"ORDER_AGING_INTERVAL" flag:"aging-interval" default:"15s" usage:"How often waiting orders are checked for priority aging, 0 disables"`
+		PriorityRules     string        `env:"ORDER_PRIORITY_RULES" flag:"priority-rules" usage:"YAML file with order priority rules (default: by total amount)"`
+		StationsFile      string        `env:"ORDER_STATIONS_FILE" flag:"stations-file" usage:"YAML file with kitchen stations and their menu items (default: orders are cooked whole)"`
+		VIPCustomers      string        `env:"ORDER_VIP_CUSTOMERS" flag:"vip-customers" usage:"Comma-separated names of VIP customers for priority rules"`
+		AgingRules        string        `env:"ORDER_AGING_RULES" flag:"aging-rules" default:"3m:5,6m:10" reload:"true" usage:"Comma-separated <wait>:<priority> rules to promote waiting orders"`
+		SchedulerInterval time.Duration `env:"ORDER_SCHEDULER_INTERVAL" flag:"scheduler-interval" default:"5s" usage:"How often scheduled orders are checked for release to the kitchen, 0 disables"`
+		ScheduleLead      time.Duration `env:"ORDER_SCHEDULE_LEAD" flag:"schedule-lead" default:"5m" reload:"true" usage:"Extra time before expected cook time a scheduled order is released to the kitchen"`
+		LoyaltyEarnRate   float64       `env:"ORDER_LOYALTY_EARN_RATE" flag:"loyalty-earn-rate" default:"1" usage:"Loyalty points a customer earns per 1.00 of net order amount"`
+		LoyaltyPointValue float64       `env:"ORDER_LOYALTY_POINT_VALUE" flag:"loyalty-point-value" default:"0.01" usage:"Amount one redeemed loyalty point pays"`
+		TaxFile           string        `env:"ORDER_TAX_FILE" flag:"tax-file" usage:"YAML file with tax categories, their rates and menu items (default: every item is taxed with --tax-rate)"`
+		TaxRate           float64       `env:"ORDER_TAX_RATE" flag:"tax-rate" default:"0" usage:"Tax rate in percent of items of no tax category"`
+		ServiceCharge     float64       `env:"ORDER_SERVICE_CHARGE" flag:"service-charge" default:"0" usage:"Service charge of dine_in orders in percent of the net amount"`
+		DeliveryZones     string        `env:"ORDER_DELIVERY_ZONES" flag:"delivery-zones" usage:"YAML file with delivery zones, their fees, minimum orders and travel times (default: delivery to any address)"`
+		GeocoderFile      string        `env:"ORDER_GEOCODER_FILE" flag:"geocoder-file" usage:"YAML lookup table of addresses and postcodes for the offline geocoder, required with --delivery-zones"`
+		Origin            string        `env:"ORDER_ORIGIN" flag:"origin" usage:"Restaurant coordinate \"lat lng\" delivery distance of priority rules is measured from, requires --delivery-zones"`
+		RequirePayment    bool          `env:"ORDER_REQUIRE_PAYMENT" flag:"require-payment" default:"false" usage:"New orders wait in pending_payment until payment-service authorizes the payment"`
+	}
+
+	PaymentService struct {
+		Provider    string        `env:"PAYMENT_PROVIDER" flag:"payment-provider" default:"fake" usage:"Payment provider (fake)"`
+		FakeScript  string        `env:"PAYMENT_FAKE_SCRIPT" flag:"payment-fake-script" default:"approve" reload:"true" usage:"Comma-separated outcomes the fake provider repeats in turn: approve, decline, timeout"`
+		FakeDelay   time.Duration `env:"PAYMENT_FAKE_DELAY" flag:"payment-fake-delay" default:"500ms" reload:"true" usage:"Time the fake provider takes to answer"`
+		Timeout     time.Duration `env:"PAYMENT_TIMEOUT" flag:"payment-timeout" default:"5s" usage:"Time to wait for the provider to answer"`
+		MaxAttempts int           `env:"PAYMENT_MAX_ATTEMPTS" flag:"payment-max-attempts" default:"3" usage:"Authorization attempts before the payment fails and the order is cancelled"`
+		RetryDelay  time.Duration `env:"PAYMENT_RETRY_DELAY" flag:"payment-retry-delay" default:"5s" usage:"Delay before a timed out authorization or refund is retried"`
+		Interval    time.Duration `env:"PAYMENT_INTERVAL" flag:"payment-interval" default:"1s" usage:"How often pending payments and refunds are checked"`
+	}
+
+	LoadGenService struct {
+		Templates   string        `env:"LOADGEN_TEMPLATES" flag:"loadgen-templates" usage:"JSONL file of create order requests used as templates (required)"`
+		URL         string        `env:"LOADGEN_URL" flag:"loadgen-url" default:"http://localhost:3000" usage:"URL of the order-service under load"`
+		Profile     string        `env:"LOADGEN_PROFILE" flag:"loadgen-profile" default:"steady" usage:"Traffic shape: steady, ramp or burst"`
+		Rate        float64       `env:"LOADGEN_RATE" flag:"loadgen-rate" default:"10" usage:"Target requests per second, the peak rate of ramp and burst"`
+		Duration    time.Duration `env:"LOADGEN_DURATION" flag:"loadgen-duration" default:"1m" usage:"How long requests are sent"`
+		BurstEvery  time.Duration `env:"LOADGEN_BURST_EVERY" flag:"loadgen-burst-every" default:"10s" usage:"Period of the burst profile"`
+		BurstLength time.Duration `env:"LOADGEN_BURST_LENGTH" flag:"loadgen-burst-length" default:"2s" usage:"Time of every period the burst profile sends at full rate, a tenth of it otherwise"`
+		MaxInFlight int           `env:"LOADGEN_MAX_IN_FLIGHT" flag:"loadgen-max-in-flight" default:"100" usage:"Requests waiting for a response at most, requests over it are skipped"`
+		Drain       time.Duration `env:"LOADGEN_DRAIN" flag:"loadgen-drain" default:"30s" usage:"How long to wait for created orders to become ready after the last request"`
+		Report      string        `env:"LOADGEN_REPORT" flag:"loadgen-report" default:"loadgen-report.json" usage:"File the summary report is written to"`
+	}
+
+	// AllService configures --mode=all. Its services use their own sections, e.g. --port is the order-service port.
+	AllService struct {
+		KitchenWorkers  string        `env:"ALL_KITCHEN_WORKERS" flag:"kitchen-workers" default:"chef_1,chef_2" usage:"Comma-separated kitchen workers as <name>[:<type>+<type>], e.g. chef_mario:dine_in+takeout,chef_luigi"`
+		TrackingPort    int           `env:"ALL_TRACKING_PORT" flag:"tracking-port" default:"3002" usage:"HTTP port of the tracking-service"`
+		RestartAttempts int           `env:"ALL_RESTART_ATTEMPTS" flag:"restart-attempts" default:"5" usage:"Restarts of a failed service before the whole process is stopped"`
+		RestartDelay    time.Duration `env:"ALL_RESTART_DELAY" flag:"restart-delay" default:"2s" usage:"Delay before a failed service is restarted"`
 	}
 
 	TrackingService struct {
-		HeartbeatInterval int
+		HeartbeatInterval int           `env:"TRACKING_HEARTBEAT_INTERVAL" flag:"heartbeat-interval" default:"30" reload:"true" usage:"Worker heartbeat in seconds"`
+		SLA               time.Duration `env:"TRACKING_SLA" flag:"sla" default:"15m" reload:"true" usage:"Time from received to ready an order must fit in, used by analytics"`
 	}
 
 	KitchenService struct {
-		WorkerName        string
-		OrderTypes        string
-		Prefetch          int
-		HeartbeatInterval int
-		ReconnectAttempt  int           `env:"KITCHEN_RECONNECT_ATTEMPT" default:"5"`
-		ReconnectDelay    time.Duration `env:"KITCHEN_RECONNECT_DELAY" default:"1s"`
+		WorkerName        string        `env:"KITCHEN_WORKER_NAME" flag:"worker-name" usage:"Unique worker identifier (required)"`
+		OrderTypes        string        `env:"KITCHEN_ORDER_TYPES" flag:"order-types" usage:"Comma-separated order types (dine_in,takeout,delivery)"`
+		Stations          string        `env:"KITCHEN_STATIONS" flag:"stations" usage:"Comma-separated kitchen stations the worker cooks for (e.g. oven,grill)"`
+		Prefetch          int           `env:"KITCHEN_PREFETCH" flag:"prefetch" default:"1" reload:"true" usage:"RabbitMQ prefetch count"`
+		HeartbeatInterval int           `env:"KITCHEN_HEARTBEAT_INTERVAL" flag:"heartbeat-interval" default:"30" reload:"true" usage:"Worker heartbeat in seconds"`
+		ReconnectAttempt  int           `env:"KITCHEN_RECONNECT_ATTEMPT" flag:"kitchen-reconnect-attempt" default:"5" usage:"Attempts to recreate a stopped worker"`
+		ReconnectDelay    time.Duration `env:"KITCHEN_RECONNECT_DELAY" flag:"kitchen-reconnect-delay" default:"1s" usage:"Delay between worker recreate attempts"`
 	}
 
 	RabbitMQ struct {
 		Conn                  rabbit.Config
-		OrderExchange         string        `env:"RABBITMQ_ORDER_EXCHANGE" default:"orders_topic"`
-		NotificationsExchange string        `env:"RABBITMQ_NOTIFICATIONS_EXCHANGE" default:"notifications_fanout"`
-		ReconnectAttempt      int           `env:"RABBITMQ_RECONNECT_ATTEMPT" default:"5"`
-		ReconnectDelay        time.Duration `env:"RABBITMQ_RECONNECT_DELAY" default:"1s"`
+		OrderExchange         string        `env:"RABBITMQ_ORDER_EXCHANGE" flag:"rabbitmq-order-exchange" default:"orders_topic" usage:"Orders topic exchange"`
+		NotificationsExchange string        `env:"RABBITMQ_NOTIFICATIONS_EXCHANGE" flag:"rabbitmq-notifications-exchange" default:"notifications_fanout" usage:"Notifications fanout exchange"`
+		ReconnectAttempt      int           `env:"RABBITMQ_RECONNECT_ATTEMPT" flag:"rabbitmq-reconnect-attempt" default:"5" usage:"Attempts to reconnect to RabbitMQ"`
+		ReconnectDelay        time.Duration `env:"RABBITMQ_RECONNECT_DELAY" flag:"rabbitmq-reconnect-delay" default:"1s" usage:"Delay between RabbitMQ reconnect attempts"`
 	}
 )
 
+// flagValues holds raw values of the command line flags generated from the config schema.
+var flagValues = registerFlags(flag.CommandLine)
+
 func New(filepath string) (*Config, error) {
 	cfg := &Config{}
 
-	if err := configparser.LoadAndParseYaml(filepath, cfg); err != nil {
+	sources, err := configparser.ParseAll(filepath, setFlags(), cfg)
+	if err != nil {
 		return nil, err
 	}
+	cfg.path = filepath
+	cfg.sources = sources
 
-	if err := parseFlags(cfg); err != nil {
+	if err := validate(cfg); err != nil {
 		return nil, err
 	}
 
 	return cfg, nil
 }
 
-func parseFlags(cfg *Config) error {
-	if modeFlag == nil {
-		return ErrModeNotProvided
+// Path returns path to the yaml file the config was loaded from.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Source returns where the value of the field came from.
+func (c *Config) Source(path string) configparser.Source {
+	if src, ok := c.sources[path]; ok {
+		return src
 	}
+	return configparser.SourceDefault
+}
+
+// registerFlags defines a string flag for every config field with `flag` tag.
+// Values are parsed later by configparser, so all of them are kept as strings.
+func registerFlags(fs *flag.FlagSet) map[string]*string {
+	fields, err := configparser.Schema(&Config{})
+	if err != nil {
+		panic(err)
+	}
+
+	values := make(map[string]*string)
+	for _, f := range fields {
+		if f.Flag == "" {
+			continue
+		}
+		// Several fields may share one flag (e.g. heartbeat-interval).
+		if _, exists := values[f.Flag]; exists {
+			continue
+		}
+		values[f.Flag] = fs.String(f.Flag, "", f.Usage)
+	}
+
+	return values
+}
+
+// setFlags returns only flags that were explicitly set on the command line.
+func setFlags() map[string]string {
+	set := make(map[string]string)
+	flag.Visit(func(f *flag.Flag) {
+		if val, ok := flagValues[f.Name]; ok {
+			set[f.Name] = *val
+		}
+	})
+	return set
+}
 
-	cfg.LogLevel = *logLevel
-	if logLevel == nil || *logLevel == "" {
-		cfg.LogLevel = logger.LevelDebug
+func validate(cfg *Config) error {
+	if cfg.Mode == "" {
+		return ErrModeNotProvided
 	}
 
 	if err := validateLogLevel(cfg.LogLevel); err != nil {
 		return err
 	}
 
-	cfg.Mode = types.ServiceMode(*modeFlag)
+	if !types.IsValidLocation(cfg.Location) {
+		return fmt.Errorf("invalid location: %q", cfg.Location)
+	}
 
 	switch cfg.Mode {
 	case types.ModeOrder:
-		if portFlag == nil || *portFlag < 1024 || *portFlag > 65535 {
-			cfg.HTTPServer.Port = DefaultOrderServicePort
-		} else {
-			cfg.HTTPServer.Port = *portFlag
-		}
-
-		if maxConcurrent != nil && !(*maxConcurrent >= 0 && *maxConcurrent <= 1000) {
-			return errors.New("--max-concurrent flag must be between 1 and 1000")
-		}
-		cfg.Services.Order.MaxConcurrent = *maxConcurrent
+		return validateOrder(cfg)
 	case types.ModeKitchenWorker:
-		if workerName == nil || *workerName == "" {
+		if cfg.Services.Kitchen.WorkerName == "" {
 			return errors.New("missing required flag: --worker-name")
 		}
-
-		cfg.Services.Kitchen.WorkerName = *workerName
-		cfg.Services.Kitchen.OrderTypes = *orderTypes
-		cfg.Services.Kitchen.HeartbeatInterval = *heartbeatInt
-		cfg.Services.Kitchen.Prefetch = *prefetch
 	case types.ModeTracking:
-		if portFlag != nil {
-			cfg.HTTPServer.Port = *portFlag
-		} else {
-			cfg.HTTPServer.Port = DefaultTrackingServicePort
+		if err := validatePort(cfg, DefaultTrackingServicePort); err != nil {
+			return err
 		}
-		cfg.Services.Tracking.HeartbeatInterval = *heartbeatInt
+		return validateTracking(cfg)
+	case types.ModePayment:
+		return validatePayment(cfg)
+	case types.ModeLoadGen:
+		return validateLoadGen(cfg)
+	case types.ModeAll:
+		return validateAll(cfg)
 	case types.ModeNotificationSubscriber:
 	default:
 		return ErrInvalidModeFlag
@@ -155,6 +244,145 @@ func parseFlags(cfg *Config) error {
 	return nil
 }
 
+func validateOrder(cfg *Config) error {
+	if err := validatePort(cfg, DefaultOrderServicePort); err != nil {
+		return err
+	}
+
+	if !(cfg.Services.Order.MaxConcurrent >= 1 && cfg.Services.Order.MaxConcurrent <= 1000) {
+		return errors.New("--max-concurrent flag must be between 1 and 1000")
+	}
+
+	if _, err := models.ParseAgingRules(cfg.Services.Order.AgingRules); err != nil {
+		return fmt.Errorf("--aging-rules: %w", err)
+	}
+
+	if cfg.Services.Order.ScheduleLead < 0 {
+		return errors.New("--schedule-lead flag must not be negative")
+	}
+
+	if cfg.Services.Order.LoyaltyEarnRate < 0 {
+		return errors.New("--loyalty-earn-rate flag must not be negative")
+	}
+
+	if cfg.Services.Order.LoyaltyPointValue <= 0 {
+		return errors.New("--loyalty-point-value flag must be positive")
+	}
+
+	if cfg.Services.Order.TaxRate < 0 || cfg.Services.Order.TaxRate > 100 {
+		return errors.New("--tax-rate flag must be between 0 and 100")
+	}
+
+	if cfg.Services.Order.ServiceCharge < 0 || cfg.Services.Order.ServiceCharge > 100 {
+		return errors.New("--service-charge flag must be between 0 and 100")
+	}
+
+	if cfg.Services.Order.DeliveryZones != "" && cfg.Services.Order.GeocoderFile == "" {
+		return errors.New("--geocoder-file flag is required with --delivery-zones")
+	}
+
+	if cfg.Services.Order.Origin != "" {
+		if cfg.Services.Order.DeliveryZones == "" {
+			return errors.New("--origin flag requires --delivery-zones")
+		}
+		if _, err := models.ParseGeoPoint(cfg.Services.Order.Origin); err != nil {
+			return fmt.Errorf("--origin: %w", err)
+		}
+	}
+	return nil
+}
+
+func validateTracking(cfg *Config) error {
+	if cfg.Services.Tracking.SLA <= 0 {
+		return errors.New("--sla flag must be positive")
+	}
+	return nil
+}
+
+func validatePayment(cfg *Config) error {
+	if cfg.Services.Payment.Provider != types.PaymentProviderFake {
+		return errors.New("--payment-provider flag must be one of: fake")
+	}
+
+	if _, err := models.ParsePaymentScript(cfg.Services.Payment.FakeScript); err != nil {
+		return fmt.Errorf("--payment-fake-script: %w", err)
+	}
+
+	if cfg.Services.Payment.Timeout <= 0 || cfg.Services.Payment.Interval <= 0 || cfg.Services.Payment.RetryDelay < 0 || cfg.Services.Payment.FakeDelay < 0 {
+		return errors.New("--payment-timeout and --payment-interval flags must be positive, delays must not be negative")
+	}
+
+	if cfg.Services.Payment.MaxAttempts < 1 {
+		return errors.New("--payment-max-attempts flag must be at least 1")
+	}
+	return nil
+}
+
+func validateLoadGen(cfg *Config) error {
+	lg := cfg.Services.LoadGen
+	if lg.Templates == "" {
+		return errors.New("missing required flag: --loadgen-templates")
+	}
+
+	if !types.IsValidLoadProfile(lg.Profile) {
+		return errors.New("--loadgen-profile flag must be one of: steady, ramp, burst")
+	}
+
+	if lg.Rate <= 0 || lg.Duration <= 0 || lg.MaxInFlight < 1 || lg.Drain < 0 {
+		return errors.New("--loadgen-rate, --loadgen-duration and --loadgen-max-in-flight flags must be positive, --loadgen-drain must not be negative")
+	}
+
+	if lg.Profile == types.LoadProfileBurst && (lg.BurstLength <= 0 || lg.BurstEvery < lg.BurstLength) {
+		return errors.New("--loadgen-burst-length flag must be positive and not longer than --loadgen-burst-every")
+	}
+	return nil
+}
+
+// validateAll validates config of every service run by --mode=all.
+func validateAll(cfg *Config) error {
+	if err := validateOrder(cfg); err != nil {
+		return err
+	}
+
+	if err := validateTracking(cfg); err != nil {
+		return err
+	}
+
+	if cfg.Services.Order.RequirePayment {
+		if err := validatePayment(cfg); err != nil {
+			return err
+		}
+	}
+
+	all := cfg.Services.All
+	if all.TrackingPort < 1024 || all.TrackingPort > 65535 || all.TrackingPort == cfg.HTTPServer.Port {
+		return errors.New("--tracking-port flag must be between 1024 and 65535 and differ from --port")
+	}
+
+	if _, err := models.ParseKitchenWorkers(all.KitchenWorkers); err != nil {
+		return fmt.Errorf("--kitchen-workers: %w", err)
+	}
+
+	if all.RestartAttempts < 0 || all.RestartDelay < 0 {
+		return errors.New("--restart-attempts and --restart-delay flags must not be negative")
+	}
+
+	return nil
+}
+
+// validatePort sets default port of the mode if port was not provided.
+func validatePort(cfg *Config, defaultPort int) error {
+	if cfg.HTTPServer.Port == 0 {
+		cfg.HTTPServer.Port = defaultPort
+		return nil
+	}
+
+	if cfg.HTTPServer.Port < 1024 || cfg.HTTPServer.Port > 65535 {
+		return errors.New("--port flag must be between 1024 and 65535")
+	}
+	return nil
+}
+
 func validateLogLevel(lvl string) error {
 	switch lvl {
 	case logger.LevelDebug, logger.LevelError, logger.LevelWarn, logger.LevelInfo:
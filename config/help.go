@@ -1,38 +1,39 @@
 package config
 
 import (
-	"flag"
 	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/configparser"
 )
 
-const HelpMessage = `Usage: ./restaurant-system --mode=<service> [options]
+const helpHeader = `Usage: ./restaurant-system --mode=<service> [options]
 
 Available Services:
   order-service           - HTTP API for order management
   kitchen-worker          - Kitchen order processing service
   tracking-service        - Order tracking API
   notification-subscriber - Status update subscriber
+  payment-service         - Payment authorization of orders waiting for payment
+  loadgen                 - Load generator sending orders to a running order-service
+  all                     - Order, tracking, kitchen workers and notification subscriber in one process
 
-Common Flags:
-  --help                  - Show this help message
-  --config                - Path to config file (default: config.yaml)
-  --log-level             - Defines logger level (DEBUG, INFO, WARN, ERROR)
-
-Service-Specific Flags:
-
-Order Service:
-  --port           - HTTP port (default: 3000)
-  --max-concurrent - Max concurrent orders (default: 50)
-
-Kitchen Worker:
-  --worker-name        - Unique worker identifier (required)
-  --order-types        - Comma-separated order types (dine_in,takeout,delivery)
-  --heartbeat-interval - Worker heartbeat in seconds (default: 30)
-  --prefetch           - RabbitMQ prefetch count (default: 1)
+Every option can be set with a flag, an environment variable or a yaml key
+(the env name in lower case, nested sections joined by '_' e.g. kitchen: reconnect: delay:).
+Precedence: flag > env > yaml > default.
 
-Tracking Service:
-  --port - HTTP port (default: 3002)
+Common Flags:
+  --help          - Show this help message
+  --config-path   - Path to config file (default: config.yaml)
+  --print-config  - Print effective configuration with value sources and exit
+  --backfill-customers - Link existing orders to customer accounts by name and exit
+  --import-orders - Import orders of a JSONL file into a running order-service and exit
+                    (--import-url, --import-location, --import-batch-size, --import-concurrency)
+`
 
+const helpExamples = `
 Examples:
   ./restaurant-system --mode=order-service --port=3000 --max-concurrent 50
 
@@ -42,12 +43,64 @@ Examples:
 
   ./restaurant-system --mode=tracking-service --port=3002
   ./restaurant-system --mode=notification-subscriber
+  ./restaurant-system --mode=payment-service --payment-fake-script="approve,approve,decline,timeout"
+  ./restaurant-system --mode=loadgen --loadgen-templates=loadgen_templates_example.jsonl --loadgen-profile=ramp --loadgen-rate=50
+  ./restaurant-system --mode=all --kitchen-workers="chef_mario:dine_in+takeout,chef_luigi:delivery"
+  ./restaurant-system --import-orders=orders.jsonl --import-url=http://localhost:3000 --import-concurrency=8
 `
 
+// helpSections defines order and titles of the option groups in the help message.
+var helpSections = []struct {
+	title    string
+	sections []string
+}{
+	{"General", []string{""}},
+	{"HTTP Server", []string{"HTTPServer"}},
+	{"Order Service", []string{"Services.Order"}},
+	{"Kitchen Worker", []string{"Services.Kitchen"}},
+	{"Tracking Service", []string{"Services.Tracking"}},
+	{"Payment Service", []string{"Services.Payment"}},
+	{"Load Generator", []string{"Services.LoadGen"}},
+	{"All-in-one", []string{"Services.All"}},
+	{"PostgreSQL", []string{"Postgres"}},
+	{"RabbitMQ", []string{"RabbitMQ.Conn", "RabbitMQ"}},
+}
+
+// PrintHelp prints help message generated from the config schema.
 func PrintHelp() {
-	if HelpMessage != "" {
-		fmt.Printf("%s", HelpMessage)
-	} else {
-		flag.Usage()
+	fmt.Print(HelpMessage())
+}
+
+// HelpMessage returns help message generated from the config schema.
+func HelpMessage() string {
+	var sb strings.Builder
+	sb.WriteString(helpHeader)
+
+	fields, err := configparser.Schema(&Config{})
+	if err != nil {
+		return sb.String() + helpExamples
+	}
+
+	for _, s := range helpSections {
+		fmt.Fprintf(&sb, "\n%s:\n", s.title)
+
+		tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		for _, f := range fields {
+			if !slices.Contains(s.sections, f.Section()) {
+				continue
+			}
+			fmt.Fprintf(tw, "  --%s\t%s\t- %s%s\n", f.Flag, f.Env, f.Usage, defaultHint(f))
+		}
+		tw.Flush()
+	}
+
+	sb.WriteString(helpExamples)
+	return sb.String()
+}
+
+func defaultHint(f configparser.Field) string {
+	if f.Default == "" {
+		return ""
 	}
+	return fmt.Sprintf(" (default: %s)", f.Default)
 }
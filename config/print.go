@@ -4,41 +4,30 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
-	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/configparser"
 )
 
-// PrintConfig prints structure with field names and values in a clean format
-func PrintConfig(cfg any) {
+// PrintConfig prints structure with field names, effective values and their sources in a clean format
+func PrintConfig(cfg *Config) {
 	fmt.Println("Configuration:")
 	fmt.Println("--------------")
-	printReflected(reflect.ValueOf(cfg), "", 0)
-}
-
-func printReflected(v reflect.Value, fieldName string, depth int) {
-	// Handle pointers
-	if v.Kind() == reflect.Ptr {
-		if v.IsNil() {
-			fmt.Printf("%s%s: <nil>\n", strings.Repeat("  ", depth), fieldName)
-			return
-		}
-		v = v.Elem()
-	}
 
-	// Handle basic types directly
-	if v.Kind() != reflect.Struct {
-		if fieldName != "" {
-			fmt.Printf("%s%s: %v\n", strings.Repeat("  ", depth), fieldName, v.Interface())
-		} else {
-			fmt.Printf("%s%v\n", strings.Repeat("  ", depth), v.Interface())
-		}
+	fields, err := configparser.Schema(cfg)
+	if err != nil {
+		fmt.Println(err)
 		return
 	}
 
-	// If this is a nested struct (with name), print only field name, not type name
-	if fieldName != "" {
-		fmt.Printf("%s%s:\n", strings.Repeat("  ", depth), fieldName)
+	schema := make(map[string]configparser.Field, len(fields))
+	for _, f := range fields {
+		schema[f.Path] = f
 	}
 
+	printReflected(cfg, reflect.ValueOf(cfg).Elem(), schema, "", 0)
+}
+
+func printReflected(cfg *Config, v reflect.Value, schema map[string]configparser.Field, prefix string, depth int) {
 	t := v.Type()
 
 	for i := 0; i < v.NumField(); i++ {
@@ -50,26 +39,49 @@ func printReflected(v reflect.Value, fieldName string, depth int) {
 			continue
 		}
 
-		// Mask sensitive fields
-		nameLower := strings.ToLower(fieldType.Name)
-		if strings.Contains(nameLower, "password") || strings.Contains(nameLower, "secret") || strings.Contains(nameLower, "key") {
-			fmt.Printf("%s%s: ******\n", strings.Repeat("  ", depth+1), fieldType.Name)
-			continue
+		path := fieldType.Name
+		if prefix != "" {
+			path = prefix + "." + fieldType.Name
 		}
 
-		// Handle time.Duration specially
-		if field.Type().String() == "time.Duration" {
-			fmt.Printf("%s%s: %v\n", strings.Repeat("  ", depth+1), fieldType.Name, field.Interface().(time.Duration))
+		// Recurse for nested structs
+		f, ok := schema[path]
+		if !ok && field.Kind() == reflect.Struct {
+			fmt.Printf("%s%s:\n", strings.Repeat("  ", depth), fieldType.Name)
+			printReflected(cfg, field, schema, path, depth+1)
 			continue
 		}
 
-		// Recurse for nested structs or pointers to structs
-		if field.Kind() == reflect.Struct || (field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct) {
-			printReflected(field, fieldType.Name, depth+1)
-			continue
+		value := fmt.Sprintf("%v", field.Interface())
+		if f.Secret || isSecretName(fieldType.Name) {
+			value = "******"
 		}
 
-		// Print normal value
-		fmt.Printf("%s%s: %v\n", strings.Repeat("  ", depth+1), fieldType.Name, field.Interface())
+		fmt.Printf("%s%s: %s %s\n", strings.Repeat("  ", depth), fieldType.Name, value, describeSource(cfg.Source(path), f))
+	}
+}
+
+// describeSource returns human readable source of the value, e.g. "(flag --port)".
+func describeSource(src configparser.Source, f configparser.Field) string {
+	switch src {
+	case configparser.SourceFlag:
+		return fmt.Sprintf("(flag --%s)", f.Flag)
+	case configparser.SourceEnv:
+		return fmt.Sprintf("(env %s)", f.Env)
+	case configparser.SourceYAML:
+		return fmt.Sprintf("(yaml %s)", yamlKey(f))
+	default:
+		return fmt.Sprintf("(%s)", src)
 	}
 }
+
+// yamlKey returns the flattened yaml key of the field, e.g. "kitchen_reconnect_delay".
+func yamlKey(f configparser.Field) string {
+	return strings.ToLower(f.Env)
+}
+
+// Mask sensitive fields, even if they are not marked with `secret` tag
+func isSecretName(name string) bool {
+	nameLower := strings.ToLower(name)
+	return strings.Contains(nameLower, "password") || strings.Contains(nameLower, "secret") || strings.Contains(nameLower, "key")
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/configparser"
+)
+
+// Change is a single difference between two configs.
+type Change struct {
+	Path string `json:"path"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// Reload reads config again from the same yaml file, environment and command line flags.
+// Config of a --mode=all component gets the settings of its component again.
+func Reload(cfg *Config) (*Config, error) {
+	next, err := New(cfg.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.derive != nil {
+		cfg.derive(next)
+		next.derive = cfg.derive
+	}
+	return next, nil
+}
+
+// ApplyReloadable copies changed fields which are marked with `reload` tag from next into cfg.
+// It returns applied changes and changes that can't be applied without restart.
+func ApplyReloadable(cfg, next *Config) (applied, rejected []Change, err error) {
+	current, err := configparser.Schema(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := configparser.Schema(next)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, f := range current {
+		nf := updated[i]
+		if f.Value() == nf.Value() {
+			continue
+		}
+
+		change := Change{
+			Path: f.Path,
+			Old:  formatValue(f),
+			New:  formatValue(nf),
+		}
+
+		if !f.Reloadable {
+			rejected = append(rejected, change)
+			continue
+		}
+
+		f.Set(nf)
+		if cfg.sources == nil {
+			cfg.sources = make(map[string]configparser.Source)
+		}
+		cfg.sources[f.Path] = next.Source(f.Path)
+		applied = append(applied, change)
+	}
+
+	return applied, rejected, nil
+}
+
+func formatValue(f configparser.Field) string {
+	if f.Secret || isSecretName(f.Name()) {
+		return "******"
+	}
+	return fmt.Sprintf("%v", f.Value())
+}
@@ -0,0 +1,103 @@
+package geocoder
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/configparser"
+)
+
+// TableGeocoder is an offline geocoder looking addresses up in a table. Addresses are matched ignoring case,
+// punctuation and extra spaces. Addresses missing from the table are resolved by the postcodes they mention,
+// if the table lists the postcode.
+type TableGeocoder struct {
+	addresses map[string]models.GeoLocation // by normalized address
+	postcodes map[string]struct{}
+}
+
+// LoadTableGeocoder reads the lookup table from the YAML file. Every entry has an address with lat and lng
+// and an optional postcode, or a postcode only.
+func LoadTableGeocoder(path string) (*TableGeocoder, error) {
+	records, err := configparser.ReadYamlList(path)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := ParseTable(records)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return g, nil
+}
+
+func ParseTable(records []map[string]string) (*TableGeocoder, error) {
+	g := &TableGeocoder{
+		addresses: make(map[string]models.GeoLocation),
+		postcodes: make(map[string]struct{}),
+	}
+
+	for i, rec := range records {
+		address := normalize(rec["address"])
+		postcode := strings.ToUpper(strings.TrimSpace(rec["postcode"]))
+
+		if address == "" {
+			if postcode == "" {
+				return nil, fmt.Errorf("entry %d: address or postcode is required", i+1)
+			}
+			g.postcodes[postcode] = struct{}{}
+			continue
+		}
+
+		if _, ok := g.addresses[address]; ok {
+			return nil, fmt.Errorf("entry %d: address %q is listed twice", i+1, rec["address"])
+		}
+
+		lat, errLat := strconv.ParseFloat(strings.TrimSpace(rec["lat"]), 64)
+		lng, errLng := strconv.ParseFloat(strings.TrimSpace(rec["lng"]), 64)
+		if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+			return nil, fmt.Errorf("entry %d: lat and lng of %q must be valid coordinates", i+1, rec["address"])
+		}
+
+		g.addresses[address] = models.GeoLocation{
+			Point:    &models.GeoPoint{Lat: lat, Lng: lng},
+			Postcode: postcode,
+		}
+	}
+
+	return g, nil
+}
+
+// Geocode returns location of the address, models.ErrAddressNotFound if neither the address nor its postcode
+// is in the table.
+func (g *TableGeocoder) Geocode(ctx context.Context, address string) (models.GeoLocation, error) {
+	normalized := normalize(address)
+	if loc, ok := g.addresses[normalized]; ok {
+		return loc, nil
+	}
+
+	for word := range strings.FieldsSeq(normalized) {
+		postcode := strings.ToUpper(word)
+		if _, ok := g.postcodes[postcode]; ok {
+			return models.GeoLocation{Postcode: postcode}, nil
+		}
+	}
+
+	return models.GeoLocation{}, models.ErrAddressNotFound
+}
+
+// normalize lower-cases the address and replaces punctuation and repeated spaces with one space.
+func normalize(address string) string {
+	address = strings.Map(func(r rune) rune {
+		switch r {
+		case ',', '.', ';', '#', '\t':
+			return ' '
+		}
+		return r
+	}, strings.ToLower(address))
+
+	return strings.Join(strings.Fields(address), " ")
+}
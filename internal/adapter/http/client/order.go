@@ -0,0 +1,112 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/handler/dto"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// OrderClient calls the HTTP API of a running order-service.
+type OrderClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewOrderClient(baseURL string, timeout time.Duration) *OrderClient {
+	return &OrderClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: timeout},
+	}
+}
+
+// CreateBatch sends orders, JSON encoded create order requests, as one NDJSON batch import
+// and returns its summary with the orders which were not created.
+func (c *OrderClient) CreateBatch(ctx context.Context, locationID string, orders [][]byte) (models.BatchSummary, []models.BatchRejection, error) {
+	path := "/orders/batch"
+	if locationID != "" {
+		path = "/locations/" + url.PathEscape(locationID) + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(bytes.Join(orders, []byte("\n"))))
+	if err != nil {
+		return models.BatchSummary{}, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return models.BatchSummary{}, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return models.BatchSummary{}, nil, fmt.Errorf("order-service returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	var batch dto.BatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return models.BatchSummary{}, nil, fmt.Errorf("failed to decode batch response: %w", err)
+	}
+
+	var rejected []models.BatchRejection
+	for _, res := range batch.Results {
+		if res.Result == types.BatchCreated {
+			continue
+		}
+
+		reason := res.Error
+		if len(res.Errors) > 0 {
+			fields := make([]string, 0, len(res.Errors))
+			for field, msg := range res.Errors {
+				fields = append(fields, field+": "+msg)
+			}
+			sort.Strings(fields)
+			reason = strings.Join(fields, "; ")
+		}
+		rejected = append(rejected, models.BatchRejection{Index: res.Index, Reason: reason})
+	}
+
+	return batch.Summary, rejected, nil
+}
+
+// CreateOrder sends a JSON encoded create order request and returns the response status code,
+// with the order number if the order was created.
+func (c *OrderClient) CreateOrder(ctx context.Context, locationID string, order []byte) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/locations/"+url.PathEscape(locationID)+"/orders", bytes.NewReader(order))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, "", nil
+	}
+
+	var created struct {
+		OrderInfo dto.CreateOrderResponse `json:"order_info"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return resp.StatusCode, "", fmt.Errorf("failed to decode order response: %w", err)
+	}
+
+	return resp.StatusCode, created.OrderInfo.OrderNumber, nil
+}
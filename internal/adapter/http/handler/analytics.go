@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/handler/dto"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+type AnalyticsService interface {
+	Throughput(ctx context.Context, window models.AnalyticsWindow, bucket time.Duration) ([]models.ThroughputPoint, error)
+	Durations(ctx context.Context, window models.AnalyticsWindow) (models.KitchenDurations, error)
+	Workers(ctx context.Context, window models.AnalyticsWindow) ([]models.WorkerPerformance, error)
+	SLA(ctx context.Context, window models.AnalyticsWindow, sla time.Duration) (models.SLAReport, error)
+}
+
+type Analytics struct {
+	service  AnalyticsService
+	location string // default location for routes without location
+	log      logger.Logger
+}
+
+func NewAnalytics(service AnalyticsService, location string, log logger.Logger) *Analytics {
+	return &Analytics{
+		service:  service,
+		location: location,
+		log:      log,
+	}
+}
+
+// window reads location and time window of the request. It writes error response if they are invalid.
+func (h *Analytics) window(w http.ResponseWriter, r *http.Request, v *validator.Validator) (models.AnalyticsWindow, bool) {
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return models.AnalyticsWindow{}, false
+	}
+
+	window := dto.ParseAnalyticsWindow(v, r.URL.Query(), time.Now())
+	window.LocationID = location
+
+	return window, true
+}
+
+func (h *Analytics) Throughput(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	window, ok := h.window(w, r, v)
+	if !ok {
+		return
+	}
+	bucket := dto.ParseAnalyticsBucket(v, r.URL.Query(), window)
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	points, err := h.service.Throughput(r.Context(), window, bucket)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	response := envelope{
+		"location_id":    window.LocationID,
+		"from":           window.From,
+		"to":             window.To,
+		"bucket_seconds": bucket.Seconds(),
+		"throughput":     points,
+	}
+	if err := writeJSON(w, http.StatusOK, response, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+func (h *Analytics) Durations(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	window, ok := h.window(w, r, v)
+	if !ok {
+		return
+	}
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	durations, err := h.service.Durations(r.Context(), window)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	response := envelope{
+		"location_id": window.LocationID,
+		"from":        window.From,
+		"to":          window.To,
+		"queue_wait":  durations.QueueWait,
+		"cooking":     durations.Cooking,
+	}
+	if err := writeJSON(w, http.StatusOK, response, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+func (h *Analytics) Workers(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	window, ok := h.window(w, r, v)
+	if !ok {
+		return
+	}
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	workers, err := h.service.Workers(r.Context(), window)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	response := envelope{
+		"location_id": window.LocationID,
+		"from":        window.From,
+		"to":          window.To,
+		"workers":     workers,
+	}
+	if err := writeJSON(w, http.StatusOK, response, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+func (h *Analytics) SLA(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	window, ok := h.window(w, r, v)
+	if !ok {
+		return
+	}
+	sla := dto.ParseSLA(v, r.URL.Query())
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	report, err := h.service.SLA(r.Context(), window, sla)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	response := envelope{
+		"location_id": window.LocationID,
+		"from":        window.From,
+		"to":          window.To,
+		"sla":         report,
+	}
+	if err := writeJSON(w, http.StatusOK, response, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
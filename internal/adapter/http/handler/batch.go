@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/handler/dto"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+// maxBatchBytes limits size of a batch import body.
+const maxBatchBytes = 10 << 20
+
+// CreateBatch imports orders from a JSON array or NDJSON body. Every order is validated and created on its own,
+// the response has a result of every order and a summary. Invalid orders don't stop the others.
+func (h *Order) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	items, err := readBatch(w, r, maxBatchBytes)
+	if err != nil {
+		h.log.Error(ctx, types.ActionValidationFailed, "failed to read order batch", err)
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(items) == 0 || len(items) > dto.MaxBatchOrders {
+		failedValidationResponse(w, map[string]string{"orders": fmt.Sprintf("must contain between 1 and %d orders", dto.MaxBatchOrders)})
+		return
+	}
+
+	results := make([]dto.BatchOrderResult, len(items))
+
+	var (
+		orders  []*models.CreateOrder
+		indexes []int // indexes of the valid orders in the batch
+	)
+	for i, item := range items {
+		results[i].Index = i
+
+		var req dto.CreateOrderRequest
+		if err := decodeJSON(bytes.NewReader(item), &req); err != nil {
+			results[i].Result = types.BatchInvalid
+			results[i].Errors = map[string]string{"body": err.Error()}
+			continue
+		}
+
+		createOrder := dto.FromRequestToInternalCreateOrder(req)
+		createOrder.LocationID = location
+
+		v := validator.New()
+		dto.ValidateCreateOrderRequest(v, createOrder)
+		if !v.Valid() {
+			results[i].Result = types.BatchInvalid
+			results[i].Errors = v.Errors
+			continue
+		}
+
+		orders = append(orders, createOrder)
+		indexes = append(indexes, i)
+	}
+
+	for j, res := range h.service.CreateBatch(ctx, location, orders) {
+		result := &results[indexes[j]]
+
+		if res.Err != nil {
+			code, fields := createOrderError(res.Err)
+			if code == http.StatusUnprocessableEntity {
+				result.Result = types.BatchInvalid
+				result.Errors = fields
+			} else {
+				result.Result = types.BatchFailed
+				result.Error = res.Err.Error()
+			}
+			continue
+		}
+
+		result.Result = types.BatchCreated
+		result.OrderNumber = res.Info.Number
+		result.Status = res.Info.Status
+		result.TotalAmount = res.Info.TotalAmount
+	}
+
+	summary := models.BatchSummary{Total: len(results)}
+	for _, res := range results {
+		switch res.Result {
+		case types.BatchCreated:
+			summary.Created++
+		case types.BatchInvalid:
+			summary.Invalid++
+		default:
+			summary.Failed++
+		}
+	}
+
+	h.log.Info(ctx, types.ActionOrderBatchImported, "order batch processed", "total", summary.Total, "created", summary.Created, "invalid", summary.Invalid, "failed", summary.Failed)
+
+	if err := writeJSON(w, http.StatusOK, envelope{"summary": summary, "results": results}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
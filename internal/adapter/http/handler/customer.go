@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/handler/dto"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+type CustomerService interface {
+	Create(ctx context.Context, c models.Customer) (models.Customer, error)
+	Get(ctx context.Context, id int) (models.Customer, error)
+	FindByPhone(ctx context.Context, phone string) (models.Customer, error)
+}
+
+type Customer struct {
+	service CustomerService
+	log     logger.Logger
+}
+
+func NewCustomer(service CustomerService, log logger.Logger) *Customer {
+	return &Customer{
+		service: service,
+		log:     log,
+	}
+}
+
+// Create creates a customer account.
+func (h *Customer) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.CustomerRequest
+	if err := readJSON(w, r, &req); err != nil {
+		h.log.Error(ctx, types.ActionValidationFailed, "failed to decode request", err)
+		errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	customer := dto.FromCustomerRequest(req)
+
+	v := validator.New()
+	dto.ValidateCustomer(v, customer)
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	customer, err := h.service.Create(ctx, customer)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusCreated, envelope{"customer": customer}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// Get returns a customer by id.
+func (h *Customer) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := customerID(r)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid customer id")
+		return
+	}
+
+	customer, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"customer": customer}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// FindByPhone looks a customer up by phone, for the front desk.
+func (h *Customer) FindByPhone(w http.ResponseWriter, r *http.Request) {
+	phone := r.URL.Query().Get("phone")
+	if phone == "" {
+		failedValidationResponse(w, map[string]string{"phone": "must be provided"})
+		return
+	}
+
+	customer, err := h.service.FindByPhone(r.Context(), dto.NormalizePhone(phone))
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"customer": customer}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// customerID returns customer id from the path.
+func customerID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("customer_id"))
+	return id, err == nil && id >= 1
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+const (
+	DefaultAnalyticsWindow = 24 * time.Hour
+	MaxAnalyticsWindow     = 92 * 24 * time.Hour
+	DefaultAnalyticsBucket = time.Hour
+	MinAnalyticsBucket     = time.Minute
+	MaxAnalyticsBuckets    = 2000
+)
+
+// ParseAnalyticsWindow reads `from` and `to` query parameters. Window is the last day by default.
+func ParseAnalyticsWindow(v *validator.Validator, q url.Values, now time.Time) models.AnalyticsWindow {
+	window := models.AnalyticsWindow{To: now}
+
+	if s := q.Get("to"); s != "" {
+		to, err := parseTime(s)
+		v.Check(err == nil, "to", "must be RFC 3339 time or YYYY-MM-DD date")
+		window.To = to
+	}
+
+	window.From = window.To.Add(-DefaultAnalyticsWindow)
+	if s := q.Get("from"); s != "" {
+		from, err := parseTime(s)
+		v.Check(err == nil, "from", "must be RFC 3339 time or YYYY-MM-DD date")
+		window.From = from
+	}
+
+	v.Check(window.From.Before(window.To), "to", "must be after 'from'")
+	v.Check(window.To.Sub(window.From) <= MaxAnalyticsWindow, "from", "window must not be longer than 92 days")
+
+	return window
+}
+
+// ParseAnalyticsBucket reads `bucket` query parameter, a Go duration like 15m or 1h.
+func ParseAnalyticsBucket(v *validator.Validator, q url.Values, window models.AnalyticsWindow) time.Duration {
+	bucket := DefaultAnalyticsBucket
+
+	if s := q.Get("bucket"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < MinAnalyticsBucket {
+			v.AddError("bucket", "must be a duration of at least 1m, e.g. 15m or 1h")
+			return bucket
+		}
+		bucket = d
+	}
+
+	v.Check(window.To.Sub(window.From)/bucket <= MaxAnalyticsBuckets, "bucket", "too many buckets in the window, use a larger bucket")
+
+	return bucket
+}
+
+// ParseSLA reads `sla` query parameter. Zero means SLA from the configuration.
+func ParseSLA(v *validator.Validator, q url.Values) time.Duration {
+	s := q.Get("sla")
+	if s == "" {
+		return 0
+	}
+
+	sla, err := time.ParseDuration(s)
+	v.Check(err == nil && sla > 0, "sla", "must be a positive duration, e.g. 20m")
+
+	return sla
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+var phoneRX = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+
+type CustomerRequest struct {
+	Name           string  `json:"name"`
+	Phone          *string `json:"phone,omitempty"`
+	Email          *string `json:"email,omitempty"`
+	DefaultAddress *string `json:"default_address,omitempty"`
+}
+
+func FromCustomerRequest(req CustomerRequest) models.Customer {
+	c := models.Customer{
+		Name:           strings.TrimSpace(req.Name),
+		Email:          req.Email,
+		DefaultAddress: req.DefaultAddress,
+	}
+	if req.Phone != nil {
+		phone := NormalizePhone(*req.Phone)
+		c.Phone = &phone
+	}
+	return c
+}
+
+// NormalizePhone removes spaces, dashes, dots and parentheses, so the same number is always stored the same way.
+func NormalizePhone(phone string) string {
+	return strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(strings.TrimSpace(phone))
+}
+
+func ValidateCustomer(v *validator.Validator, c models.Customer) {
+	v.Check(
+		isValidCustomerName(c.Name),
+		"name",
+		"1-100 characters. Must not contain special characters other than spaces, hyphens, and apostrophes.",
+	)
+
+	if c.Phone != nil {
+		v.Check(validator.Matches(*c.Phone, phoneRX), "phone", "must be 7-15 digits with optional leading '+'")
+	}
+
+	if c.Email != nil {
+		v.Check(validator.Matches(*c.Email, validator.EmailRX), "email", "must be a valid email address")
+	}
+
+	if c.DefaultAddress != nil {
+		v.Check(len(*c.DefaultAddress) >= 10, "default_address", "must be at least 10 characters")
+	}
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+type RestockRequest struct {
+	Ingredient        string   `json:"ingredient"`
+	Quantity          float64  `json:"quantity"`
+	Unit              *string  `json:"unit,omitempty"`
+	LowStockThreshold *float64 `json:"low_stock_threshold,omitempty"`
+}
+
+type RecipeRequest struct {
+	Ingredients []models.RecipeIngredient `json:"ingredients"`
+}
+
+func FromRestockRequest(req RestockRequest, locationID string) models.Restock {
+	return models.Restock{
+		LocationID:        locationID,
+		Ingredient:        strings.TrimSpace(req.Ingredient),
+		Quantity:          req.Quantity,
+		Unit:              req.Unit,
+		LowStockThreshold: req.LowStockThreshold,
+	}
+}
+
+func ValidateRestock(v *validator.Validator, req models.Restock) {
+	v.Check(isValidItemName(req.Ingredient), "ingredient", "must be between 1-50 characters")
+	v.Check(req.Quantity >= 0 && req.Quantity <= 1_000_000, "quantity", "must be between 0 and 1000000")
+	if req.LowStockThreshold != nil {
+		v.Check(*req.LowStockThreshold >= 0, "low_stock_threshold", "must not be negative")
+	}
+}
+
+func ValidateRecipe(v *validator.Validator, recipe models.Recipe) {
+	v.Check(isValidItemName(recipe.ItemName), "item_name", "must be between 1-50 characters")
+	v.Check(len(recipe.Ingredients) >= 1 && len(recipe.Ingredients) <= 50, "ingredients", "must contain between 1 and 50 ingredients")
+
+	seen := make(map[string]bool, len(recipe.Ingredients))
+	for _, ing := range recipe.Ingredients {
+		v.Check(isValidItemName(ing.Ingredient), "ingredient", "must be between 1-50 characters")
+		v.Check(ing.Quantity > 0, "ingredient.quantity", "must be positive")
+		v.Check(!seen[ing.Ingredient], "ingredients", "must not contain duplicates")
+		seen[ing.Ingredient] = true
+	}
+}
@@ -1,14 +1,23 @@
 // dto - Data Transfer Obeject
 package dto
 
-import "github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+import (
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
 
 type CreateOrderRequest struct {
 	CustomerName    string      `json:"customer_name"`
+	CustomerID      *int        `json:"customer_id,omitempty"` // Optional customer account, name and delivery address default to it
 	OrderType       string      `json:"order_type"`
 	Items           []OrderItem `json:"items"`
 	TableNumber     *int        `json:"table_number,omitempty"`     // Only for dine_in
 	DeliveryAddress *string     `json:"delivery_address,omitempty"` // Only for delivery
+	ScheduledFor    *time.Time  `json:"scheduled_for,omitempty"`    // Pickup or delivery time of a scheduled order
+	PromoCode       string      `json:"promo_code,omitempty"`
+	RedeemPoints    int         `json:"redeem_points,omitempty"` // Loyalty points of the customer to pay with
+	Tip             float64     `json:"tip,omitempty"`
 }
 
 type OrderItem struct {
@@ -30,10 +39,15 @@ func FromRequestToInternalCreateOrder(req CreateOrderRequest) *models.CreateOrde
 
 	return &models.CreateOrder{
 		CustomerName:    req.CustomerName,
+		CustomerID:      req.CustomerID,
 		Type:            req.OrderType,
 		Items:           items,
 		TableNumber:     req.TableNumber,
 		DeliveryAddress: req.DeliveryAddress,
+		ScheduledFor:    req.ScheduledFor,
+		PromoCode:       NormalizePromoCode(req.PromoCode),
+		RedeemPoints:    req.RedeemPoints,
+		Tip:             req.Tip,
 		// These fields will be set later in the business logic
 		Number:      "",
 		TotalAmount: 0,
@@ -42,8 +56,192 @@ func FromRequestToInternalCreateOrder(req CreateOrderRequest) *models.CreateOrde
 	}
 }
 
+// ModifyOrderRequest changes items of a received order. Items are matched by name.
+type ModifyOrderRequest struct {
+	Add    []OrderItem    `json:"add,omitempty"`
+	Remove []string       `json:"remove,omitempty"`
+	Change []ItemQuantity `json:"change,omitempty"`
+	Note   string         `json:"note,omitempty"`
+}
+
+type ItemQuantity struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+func FromRequestToInternalModification(req ModifyOrderRequest) models.OrderModification {
+	mod := models.OrderModification{
+		Remove: req.Remove,
+		Note:   req.Note,
+	}
+	for _, item := range req.Add {
+		mod.Add = append(mod.Add, models.CreateOrderItem{
+			Name:     item.Name,
+			Quantity: item.Quantity,
+			Price:    item.Price,
+		})
+	}
+	for _, change := range req.Change {
+		mod.Change = append(mod.Change, models.ItemChange{Name: change.Name, Quantity: change.Quantity})
+	}
+	return mod
+}
+
+type ModifyOrderResponse struct {
+	LocationID  string `json:"location_id"`
+	OrderNumber string `json:"order_number"`
+	Status      string `json:"status"`
+	Version     int    `json:"version"`
+	Priority    int    `json:"priority"`
+
+	Subtotal       float64           `json:"subtotal"`
+	Discounts      []models.Discount `json:"discounts,omitempty"`
+	TaxAmount      float64           `json:"tax_amount"`
+	ServiceCharge  float64           `json:"service_charge,omitempty"`
+	DeliveryFee    float64           `json:"delivery_fee,omitempty"`
+	Tip            float64           `json:"tip,omitempty"`
+	TotalAmount    float64           `json:"total_amount"`
+	PointsEarned   int               `json:"points_earned,omitempty"`
+	PointsRedeemed int               `json:"points_redeemed,omitempty"`
+}
+
+func FromInternalModifiedOrder(m *models.Order) ModifyOrderResponse {
+	return ModifyOrderResponse{
+		LocationID:     m.LocationID,
+		OrderNumber:    m.Number,
+		Status:         m.Status,
+		Version:        m.Version,
+		Priority:       m.Priority,
+		Subtotal:       m.Subtotal,
+		Discounts:      m.Discounts,
+		TaxAmount:      m.TaxAmount,
+		ServiceCharge:  m.ServiceCharge,
+		DeliveryFee:    m.DeliveryFee,
+		Tip:            m.Tip,
+		TotalAmount:    m.TotalAmount,
+		PointsEarned:   m.PointsEarned,
+		PointsRedeemed: m.PointsRedeemed,
+	}
+}
+
 type CreateOrderResponse struct {
-	OrderNumber string  `json:"order_number"`
-	Status      string  `json:"status"`
-	TotalAmount float64 `json:"total_amount"`
+	LocationID  string      `json:"location_id"`
+	OrderNumber string      `json:"order_number"`
+	Status      string      `json:"status"`
+	TotalAmount float64     `json:"total_amount"`
+	ETA         *models.ETA `json:"eta,omitempty"`
+
+	Subtotal       float64           `json:"subtotal"`
+	Discounts      []models.Discount `json:"discounts,omitempty"`
+	TaxAmount      float64           `json:"tax_amount"`
+	ServiceCharge  float64           `json:"service_charge,omitempty"`
+	DeliveryFee    float64           `json:"delivery_fee,omitempty"`
+	DeliveryZone   string            `json:"delivery_zone,omitempty"`
+	Tip            float64           `json:"tip,omitempty"`
+	PointsEarned   int               `json:"points_earned,omitempty"`
+	PointsRedeemed int               `json:"points_redeemed,omitempty"`
+
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	ReleaseAt    *time.Time `json:"release_at,omitempty"`
+}
+
+type OrderResponse struct {
+	LocationID      string              `json:"location_id"`
+	OrderNumber     string              `json:"order_number"`
+	CustomerName    string              `json:"customer_name"`
+	CustomerID      *int                `json:"customer_id,omitempty"`
+	OrderType       string              `json:"order_type"`
+	TableNumber     *int                `json:"table_number,omitempty"`
+	DeliveryAddress *string             `json:"delivery_address,omitempty"`
+	DeliveryZone    *string             `json:"delivery_zone,omitempty"`
+	DeliveryFee     float64             `json:"delivery_fee,omitempty"`
+	Subtotal        float64             `json:"subtotal"`
+	Discounts       []models.Discount   `json:"discounts,omitempty"`
+	TaxAmount       float64             `json:"tax_amount"`
+	ServiceCharge   float64             `json:"service_charge,omitempty"`
+	Tip             float64             `json:"tip,omitempty"`
+	TotalAmount     float64             `json:"total_amount"`
+	PointsEarned    int                 `json:"points_earned,omitempty"`
+	PointsRedeemed  int                 `json:"points_redeemed,omitempty"`
+	Priority        int                 `json:"priority"`
+	Status          string              `json:"status"`
+	Version         int                 `json:"version"`
+	ProcessedBy     *string             `json:"processed_by"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	CompletedAt     *time.Time          `json:"completed_at"`
+	ScheduledFor    *time.Time          `json:"scheduled_for,omitempty"`
+	Payment         *models.Payment     `json:"payment,omitempty"`
+	Items           []OrderItemResponse `json:"items,omitempty"`
+}
+
+type OrderItemResponse struct {
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+	Station  *string `json:"station,omitempty"`
+	Status   string  `json:"status"`
+}
+
+func FromInternalOrder(m models.Order) OrderResponse {
+	resp := OrderResponse{
+		LocationID:      m.LocationID,
+		OrderNumber:     m.Number,
+		CustomerName:    m.CustomerName,
+		CustomerID:      m.CustomerID,
+		OrderType:       m.Type,
+		TableNumber:     m.TableNumber,
+		DeliveryAddress: m.DeliveryAddress,
+		DeliveryZone:    m.DeliveryZone,
+		DeliveryFee:     m.DeliveryFee,
+		Subtotal:        m.Subtotal,
+		Discounts:       m.Discounts,
+		TaxAmount:       m.TaxAmount,
+		ServiceCharge:   m.ServiceCharge,
+		Tip:             m.Tip,
+		TotalAmount:     m.TotalAmount,
+		PointsEarned:    m.PointsEarned,
+		PointsRedeemed:  m.PointsRedeemed,
+		Priority:        m.Priority,
+		Status:          m.Status,
+		Version:         m.Version,
+		ProcessedBy:     m.ProcessedBy,
+		CreatedAt:       m.CreatedAt,
+		UpdatedAt:       m.UpdatedAt,
+		CompletedAt:     m.CompletedAt,
+		ScheduledFor:    m.ScheduledFor,
+		Payment:         m.Payment,
+	}
+
+	for _, item := range m.Items {
+		resp.Items = append(resp.Items, OrderItemResponse{
+			Name:     item.Name,
+			Quantity: item.Quantity,
+			Price:    item.Price,
+			Station:  item.Station,
+			Status:   item.Status,
+		})
+	}
+
+	return resp
+}
+
+// MaxBatchOrders is the maximum number of orders in one batch import.
+const MaxBatchOrders = 500
+
+// BatchOrderResult is the result of one order of a batch import, in the order of the request.
+type BatchOrderResult struct {
+	Index       int               `json:"index"`
+	Result      string            `json:"result"` // 'created', 'invalid' or 'failed'
+	OrderNumber string            `json:"order_number,omitempty"`
+	Status      string            `json:"status,omitempty"`
+	TotalAmount float64           `json:"total_amount,omitempty"`
+	Errors      map[string]string `json:"errors,omitempty"` // validation errors of an invalid order
+	Error       string            `json:"error,omitempty"`  // error of a failed order
+}
+
+// BatchResponse is the response of a batch import.
+type BatchResponse struct {
+	Summary models.BatchSummary `json:"summary"`
+	Results []BatchOrderResult  `json:"results"`
 }
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+var promoCodeRX = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+type PromotionRequest struct {
+	Code        string     `json:"code"`
+	Kind        string     `json:"kind"`
+	Value       float64    `json:"value"`
+	ItemName    *string    `json:"item_name,omitempty"`
+	BuyQuantity int        `json:"buy_quantity"`
+	GetQuantity int        `json:"get_quantity"`
+	MaxUses     *int       `json:"max_uses,omitempty"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+func FromPromotionRequest(req PromotionRequest) models.Promotion {
+	return models.Promotion{
+		Code:        NormalizePromoCode(req.Code),
+		Kind:        req.Kind,
+		Value:       req.Value,
+		ItemName:    req.ItemName,
+		BuyQuantity: req.BuyQuantity,
+		GetQuantity: req.GetQuantity,
+		MaxUses:     req.MaxUses,
+		StartsAt:    req.StartsAt,
+		ExpiresAt:   req.ExpiresAt,
+	}
+}
+
+// NormalizePromoCode makes promo codes case insensitive.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func ValidatePromotion(v *validator.Validator, p models.Promotion) {
+	v.Check(validator.Matches(p.Code, promoCodeRX), "code", "must be 3-32 letters, digits, '_' or '-'")
+	v.Check(types.IsValidPromotionKind(p.Kind), "kind", "must be one of: "+strings.Join(types.AllPromotionKinds, ", "))
+
+	switch p.Kind {
+	case types.PromotionPercent:
+		v.Check(p.Value > 0 && p.Value <= 100, "value", "must be a percent between 0 and 100")
+	case types.PromotionFixed:
+		v.Check(p.Value > 0 && p.Value <= 999.99, "value", "must be an amount between 0.01 and 999.99")
+	case types.PromotionBuyXGetY:
+		v.Check(p.ItemName != nil && isValidItemName(*p.ItemName), "item_name", "menu item name is required, 1-50 characters")
+		v.Check(p.BuyQuantity >= 1 && p.BuyQuantity <= 50, "buy_quantity", "must be between 1 and 50")
+		v.Check(p.GetQuantity >= 1 && p.GetQuantity <= 50, "get_quantity", "must be between 1 and 50")
+	}
+
+	if p.MaxUses != nil {
+		v.Check(*p.MaxUses >= 1, "max_uses", "must be at least 1")
+	}
+
+	if p.StartsAt != nil && p.ExpiresAt != nil {
+		v.Check(p.ExpiresAt.After(*p.StartsAt), "expires_at", "must be after starts_at")
+	}
+}
@@ -0,0 +1,143 @@
+package dto
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+var ValidOrderStatuses = []string{
+	types.StatusOrderScheduled,
+	types.StatusOrderPendingPayment,
+	types.StatusOrderReceived,
+	types.StatusOrderCooking,
+	types.StatusOrderReady,
+	types.StatusOrderCompleted,
+	types.StatusOrderCancelled,
+}
+
+type ListOrdersResponse struct {
+	Orders     []OrderResponse `json:"orders"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+func FromInternalOrderPage(page models.OrderPage) ListOrdersResponse {
+	resp := ListOrdersResponse{
+		Orders:     make([]OrderResponse, 0, len(page.Orders)),
+		NextCursor: EncodeCursor(page.Next),
+	}
+	for _, o := range page.Orders {
+		resp.Orders = append(resp.Orders, FromInternalOrder(o))
+	}
+	return resp
+}
+
+// ParseOrderFilter reads filter of GET /orders from query parameters.
+func ParseOrderFilter(v *validator.Validator, q url.Values) models.OrderFilter {
+	filter := models.OrderFilter{
+		Status:         q.Get("status"),
+		Type:           q.Get("type"),
+		CustomerPrefix: q.Get("customer"),
+		Worker:         q.Get("worker"),
+		Limit:          models.DefaultOrdersLimit,
+	}
+
+	if filter.Status != "" {
+		v.Check(validator.PermittedValue(filter.Status, ValidOrderStatuses...), "status", "must be one of: "+strings.Join(ValidOrderStatuses, ", "))
+	}
+
+	if filter.Type != "" {
+		v.Check(validator.PermittedValue(filter.Type, ValidOrderTypes...), "type", "must be one of: 'dine_in', 'takeout', or 'delivery'")
+	}
+
+	if s := q.Get("from"); s != "" {
+		from, err := parseTime(s)
+		v.Check(err == nil, "from", "must be RFC 3339 time or YYYY-MM-DD date")
+		filter.From = &from
+	}
+
+	if s := q.Get("to"); s != "" {
+		to, err := parseTime(s)
+		v.Check(err == nil, "to", "must be RFC 3339 time or YYYY-MM-DD date")
+		filter.To = &to
+	}
+
+	if filter.From != nil && filter.To != nil {
+		v.Check(filter.From.Before(*filter.To), "to", "must be after 'from'")
+	}
+
+	if s := q.Get("priority"); s != "" {
+		priority, err := strconv.Atoi(s)
+		v.Check(err == nil && priority >= 1 && priority <= 10, "priority", "must be an integer between 1 and 10")
+		filter.Priority = &priority
+	}
+
+	if s := q.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		v.Check(err == nil && limit >= 1 && limit <= models.MaxOrdersLimit, "limit", fmt.Sprintf("must be between 1 and %d", models.MaxOrdersLimit))
+		filter.Limit = limit
+	}
+
+	switch q.Get("sort") {
+	case "", "-created_at":
+	case "created_at":
+		filter.Asc = true
+	default:
+		v.AddError("sort", "must be 'created_at' or '-created_at'")
+	}
+
+	if s := q.Get("cursor"); s != "" {
+		cursor, err := DecodeCursor(s)
+		v.Check(err == nil, "cursor", "invalid cursor")
+		filter.After = cursor
+	}
+
+	return filter
+}
+
+// EncodeCursor returns opaque string representation of the cursor.
+func EncodeCursor(c *models.OrderCursor) string {
+	if c == nil {
+		return ""
+	}
+	raw := fmt.Sprintf("%s|%d", c.CreatedAt.UTC().Format(time.RFC3339Nano), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(s string) (*models.OrderCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OrderCursor{CreatedAt: t, ID: n}, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
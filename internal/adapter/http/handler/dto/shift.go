@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+const maxShiftsPerWorker = 50
+
+type ShiftsRequest struct {
+	Shifts []models.Shift `json:"shifts"`
+}
+
+// FromShiftsRequest returns shifts of the worker from the request.
+func FromShiftsRequest(req ShiftsRequest, workerName string) []models.Shift {
+	shifts := make([]models.Shift, 0, len(req.Shifts))
+	for _, s := range req.Shifts {
+		s.WorkerName = workerName
+		shifts = append(shifts, s)
+	}
+	return shifts
+}
+
+func ValidateShifts(v *validator.Validator, shifts []models.Shift) {
+	v.Check(len(shifts) <= maxShiftsPerWorker, "shifts", "must contain at most 50 shifts")
+
+	for _, s := range shifts {
+		v.Check(s.Weekday >= time.Sunday && s.Weekday <= time.Saturday, "weekday", "must be between 0 (Sunday) and 6 (Saturday)")
+
+		_, err := models.ParseShiftTime(s.Start)
+		v.Check(err == nil, "start", "must be HH:MM")
+		_, err = models.ParseShiftTime(s.End)
+		v.Check(err == nil, "end", "must be HH:MM")
+
+		for _, ot := range s.OrderTypes {
+			v.Check(types.IsValidOrderType(ot), "order_types", "must contain only dine_in, takeout or delivery")
+		}
+	}
+}
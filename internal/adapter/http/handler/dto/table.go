@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+type TableRequest struct {
+	Number   int `json:"number"`
+	Capacity int `json:"capacity"`
+}
+
+type OpenTableRequest struct {
+	Guests int `json:"guests"`
+}
+
+func FromTableRequest(req TableRequest, locationID string) models.Table {
+	return models.Table{
+		LocationID: locationID,
+		Number:     req.Number,
+		Capacity:   req.Capacity,
+	}
+}
+
+func ValidateTable(v *validator.Validator, t models.Table) {
+	// Same range as table_number of dine-in orders
+	v.Check(t.Number >= 1 && t.Number <= 100, "number", "must be between 1 and 100")
+	v.Check(t.Capacity >= 1 && t.Capacity <= 50, "capacity", "must be between 1 and 50")
+}
+
+func ValidateOpenTable(v *validator.Validator, req OpenTableRequest) {
+	v.Check(req.Guests >= 1 && req.Guests <= 50, "guests", "must be between 1 and 50")
+}
@@ -2,6 +2,7 @@ package dto
 
 import (
 	"regexp"
+	"time"
 	"unicode/utf8"
 
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
@@ -20,6 +21,11 @@ import (
 // | `item.quantity` | integer          | must be between 1 and 10.                                                                          |
 // | `item.price`    | decimal          | must be between `0.01` and `999.99`.                                                               |
 
+// | `scheduled_for` | timestamp        | Optional. In the future, at most 7 days ahead.                                                     |
+// | `promo_code`    | string           | Optional. 3-32 letters, digits, '_' or '-', case insensitive.                                      |
+// | `redeem_points` | integer          | Optional. Positive, requires `customer_id`.                                                        |
+// | `tip`           | decimal          | Optional. Between `0` and `999.99`.                                                                |
+
 // - **Conditional Validation:**
 
 // | `order_type` | `required fields`                              | `description`                                 | `must not be present` |
@@ -27,6 +33,9 @@ import (
 // | `'dine_in'`  | `table_number` (integer, 1-100)                | Table number at which the customer is served. | `delivery_address`    |
 // | `'delivery'` | `delivery_address` (string, min 10 characters) | Address for delivery of the order by courier. | `table_number`        |
 
+// MaxScheduleAhead is how far ahead an order can be scheduled.
+const MaxScheduleAhead = 7 * 24 * time.Hour
+
 var ValidOrderTypes = []string{
 	types.OrderTypeDineIn,
 	types.OrderTypeDelivery,
@@ -38,11 +47,30 @@ func ValidateCreateOrderRequest(v *validator.Validator, req *models.CreateOrder)
 		return
 	}
 
-	v.Check(
-		isValidCustomerName(req.CustomerName),
-		"customer_name",
-		"1-100 characters. Must not contain special characters other than spaces, hyphens, and apostrophes.",
-	)
+	// Name of a customer account is taken from the account if omitted.
+	if req.CustomerID == nil || req.CustomerName != "" {
+		v.Check(
+			isValidCustomerName(req.CustomerName),
+			"customer_name",
+			"1-100 characters. Must not contain special characters other than spaces, hyphens, and apostrophes.",
+		)
+	}
+
+	if req.CustomerID != nil {
+		v.Check(*req.CustomerID >= 1, "customer_id", "must be a positive integer")
+	}
+
+	if req.PromoCode != "" {
+		v.Check(validator.Matches(req.PromoCode, promoCodeRX), "promo_code", "must be 3-32 letters, digits, '_' or '-'")
+	}
+
+	v.Check(req.Tip >= 0 && req.Tip <= 999.99, "tip", "must be between `0` and `999.99`")
+
+	// Loyalty points belong to a customer account.
+	if req.RedeemPoints != 0 {
+		v.Check(req.RedeemPoints > 0, "redeem_points", "must be a positive integer")
+		v.Check(req.CustomerID != nil, "redeem_points", "requires customer_id")
+	}
 
 	// Check if order_type in request contains in ValidOrderTypes
 	v.Check(
@@ -54,6 +82,12 @@ func ValidateCreateOrderRequest(v *validator.Validator, req *models.CreateOrder)
 	// Conditional validations based on order_type
 	vaildateOrdertype(v, req)
 
+	if req.ScheduledFor != nil {
+		now := time.Now()
+		v.Check(req.ScheduledFor.After(now), "scheduled_for", "must be in the future")
+		v.Check(req.ScheduledFor.Before(now.Add(MaxScheduleAhead)), "scheduled_for", "must be at most 7 days ahead")
+	}
+
 	v.Check(
 		len(req.Items) >= 1 && len(req.Items) <= 20,
 		"items",
@@ -81,6 +115,33 @@ func ValidateCreateOrderRequest(v *validator.Validator, req *models.CreateOrder)
 	}
 }
 
+func ValidateModifyOrderRequest(v *validator.Validator, mod models.OrderModification) {
+	v.Check(
+		len(mod.Add)+len(mod.Remove)+len(mod.Change) > 0,
+		"items",
+		"at least one of add, remove or change is required",
+	)
+
+	v.Check(len(mod.Add) <= 20, "add", "must contain at most 20 items")
+
+	for _, item := range mod.Add {
+		v.Check(isValidItemName(item.Name), "add.name", "must be between 1-50 characters")
+		v.Check(item.Quantity >= 1 && item.Quantity <= 10, "add.quantity", "must be between 1 and 10")
+		v.Check(item.Price >= 0.01 && item.Price <= 999.99, "add.price", "must be between `0.01` and `999.99`")
+	}
+
+	for _, name := range mod.Remove {
+		v.Check(isValidItemName(name), "remove", "must be item names of 1-50 characters")
+	}
+
+	for _, change := range mod.Change {
+		v.Check(isValidItemName(change.Name), "change.name", "must be between 1-50 characters")
+		v.Check(change.Quantity >= 1 && change.Quantity <= 10, "change.quantity", "must be between 1 and 10, remove the item instead of 0")
+	}
+
+	v.Check(utf8.RuneCountInString(mod.Note) <= 200, "note", "must be at most 200 characters")
+}
+
 // vaildateOrdertype does conditional validations based on order_type
 func vaildateOrdertype(v *validator.Validator, req *models.CreateOrder) {
 	// Conditional validations based on order_type
@@ -107,8 +168,9 @@ func vaildateOrdertype(v *validator.Validator, req *models.CreateOrder) {
 		)
 
 	case types.OrderTypeDelivery:
+		// Default address of a customer account is used if omitted.
 		v.Check(
-			req.DeliveryAddress != nil,
+			req.DeliveryAddress != nil || req.CustomerID != nil,
 			"delivery_address",
 			"required for delivery orders",
 		)
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
 )
 
 type envelope map[string]any
@@ -36,7 +38,12 @@ func readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
 	maxBytes := 1_048_576
 	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
 
-	dec := json.NewDecoder(r.Body)
+	return decodeJSON(r.Body, dst)
+}
+
+// decodeJSON decodes a single JSON value of the body to dst, unknown keys are not allowed.
+func decodeJSON(body io.Reader, dst any) error {
+	dec := json.NewDecoder(body)
 	dec.DisallowUnknownFields()
 
 	// Decode the request body to the destination.
@@ -95,10 +102,60 @@ func readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
 	return nil
 }
 
+// readBatch reads orders of a batch from a JSON array or, for other bodies, newline delimited JSON.
+// Lines of NDJSON are returned as is, so a malformed line is rejected alone when it is decoded.
+func readBatch(w http.ResponseWriter, r *http.Request, maxBytes int64) ([]json.RawMessage, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return nil, fmt.Errorf("body must not be larger than %d bytes", maxBytesError.Limit)
+		}
+		return nil, err
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, errors.New("body must not be empty")
+	}
+
+	var items []json.RawMessage
+	if body[0] == '[' {
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, errors.New("body contains badly-formed JSON array")
+		}
+		return items, nil
+	}
+
+	for line := range bytes.SplitSeq(body, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, json.RawMessage(line))
+	}
+	return items, nil
+}
+
+// locationID returns location from the request path, or the default one for routes without location.
+// ok is false if location in the path is invalid.
+func locationID(r *http.Request, defaultLocation string) (string, bool) {
+	loc := r.PathValue("location_id")
+	if loc == "" {
+		return defaultLocation, true
+	}
+	return loc, types.IsValidLocation(loc)
+}
+
 func getCode(err error) int {
 	switch err {
-	case models.ErrOrderNotFound, models.ErrWorkerNotFound:
+	case models.ErrOrderNotFound, models.ErrWorkerNotFound, models.ErrCustomerNotFound, models.ErrTableNotFound, models.ErrTableNoSessions:
 		return http.StatusNotFound
+	case models.ErrOrderNotCancellable, models.ErrOrderNotModifiable, models.ErrOrderPaid, models.ErrWorkerOffline, models.ErrCustomerExists, models.ErrPromotionExists,
+		models.ErrTableExists, models.ErrTableNotFree, models.ErrTableClosed, models.ErrTableNotCleaning:
+		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
 	}
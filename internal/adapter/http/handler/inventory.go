@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/handler/dto"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+type InventoryService interface {
+	ListStock(ctx context.Context, locationID string) ([]models.StockItem, error)
+	Restock(ctx context.Context, req models.Restock) (models.StockItem, error)
+	ListRecipes(ctx context.Context, locationID string) ([]models.Recipe, error)
+	SetRecipe(ctx context.Context, recipe models.Recipe) error
+}
+
+type Inventory struct {
+	service  InventoryService
+	location string // default location for routes without location
+	log      logger.Logger
+}
+
+func NewInventory(service InventoryService, location string, log logger.Logger) *Inventory {
+	return &Inventory{
+		service:  service,
+		location: location,
+		log:      log,
+	}
+}
+
+// ListStock returns ingredient stock of the location.
+func (h *Inventory) ListStock(w http.ResponseWriter, r *http.Request) {
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	stock, err := h.service.ListStock(r.Context(), location)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	response := envelope{
+		"location_id": location,
+		"inventory":   stock,
+	}
+	if err := writeJSON(w, http.StatusOK, response, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// Restock adds stock of an ingredient.
+func (h *Inventory) Restock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	var req dto.RestockRequest
+	if err := readJSON(w, r, &req); err != nil {
+		h.log.Error(ctx, types.ActionValidationFailed, "failed to decode request", err)
+		errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	restock := dto.FromRestockRequest(req, location)
+
+	v := validator.New()
+	dto.ValidateRestock(v, restock)
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	stock, err := h.service.Restock(ctx, restock)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"stock": stock}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// ListRecipes returns recipes of menu items and whether they are available at the location.
+func (h *Inventory) ListRecipes(w http.ResponseWriter, r *http.Request) {
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	recipes, err := h.service.ListRecipes(r.Context(), location)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	response := envelope{
+		"location_id": location,
+		"recipes":     recipes,
+	}
+	if err := writeJSON(w, http.StatusOK, response, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// SetRecipe replaces ingredients of a menu item.
+func (h *Inventory) SetRecipe(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.RecipeRequest
+	if err := readJSON(w, r, &req); err != nil {
+		h.log.Error(ctx, types.ActionValidationFailed, "failed to decode request", err)
+		errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	recipe := models.Recipe{
+		ItemName:    r.PathValue("item_name"),
+		Ingredients: req.Ingredients,
+	}
+
+	v := validator.New()
+	dto.ValidateRecipe(v, recipe)
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	if err := h.service.SetRecipe(ctx, recipe); err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"recipe": recipe}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
@@ -15,17 +15,26 @@ import (
 
 type OrderService interface {
 	CreateOrder(ctx context.Context, req *models.CreateOrder) (*models.OrderCreatedInfo, error)
+	ExplainPriority(ctx context.Context, req *models.CreateOrder) models.PriorityDecision
+	CancelOrder(ctx context.Context, locationID, orderNumber string) error
+	ModifyOrder(ctx context.Context, locationID, orderNumber string, mod models.OrderModification) (*models.Order, error)
+	CreateBatch(ctx context.Context, locationID string, reqs []*models.CreateOrder) []models.BatchResult
 }
 
+// coverageWarningHeader is set on created orders no kitchen shift covers.
+const coverageWarningHeader = "X-Kitchen-Coverage-Warning"
+
 type Order struct {
-	service OrderService
-	log     logger.Logger
+	service  OrderService
+	location string // default location for routes without location
+	log      logger.Logger
 }
 
-func NewOrder(service OrderService, log logger.Logger) *Order {
+func NewOrder(service OrderService, location string, log logger.Logger) *Order {
 	return &Order{
-		service: service,
-		log:     log,
+		service:  service,
+		location: location,
+		log:      log,
 	}
 }
 
@@ -33,6 +42,12 @@ func NewOrder(service OrderService, log logger.Logger) *Order {
 func (h *Order) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
 	var req dto.CreateOrderRequest
 	if err := readJSON(w, r, &req); err != nil {
 		h.log.Error(ctx, types.ActionValidationFailed, "failed to decode request", err)
@@ -41,6 +56,7 @@ func (h *Order) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	createOrder := dto.FromRequestToInternalCreateOrder(req)
+	createOrder.LocationID = location
 
 	v := validator.New()
 	dto.ValidateCreateOrderRequest(v, createOrder)
@@ -52,29 +68,201 @@ func (h *Order) CreateOrder(w http.ResponseWriter, r *http.Request) {
 
 	info, err := h.service.CreateOrder(ctx, createOrder)
 	if err != nil {
-		if errors.Is(err, order.ErrTooManyRequest) {
-			errorResponse(w, http.StatusTooManyRequests, err.Error())
+		code, fields := createOrderError(err)
+		if code == http.StatusUnprocessableEntity {
+			failedValidationResponse(w, fields)
 			return
 		}
-		internalErrorResponse(w, err.Error())
+		errorResponse(w, code, err.Error())
 		return
 	}
 
 	response := envelope{
-		"customer_name": req.CustomerName,
+		"customer_name": createOrder.CustomerName,
 		"order_info": dto.CreateOrderResponse{
+			LocationID:  info.LocationID,
 			OrderNumber: info.Number,
 			Status:      info.Status,
 			TotalAmount: info.TotalAmount,
+			ETA:         info.ETA,
+
+			Subtotal:       info.Subtotal,
+			Discounts:      info.Discounts,
+			TaxAmount:      info.TaxAmount,
+			ServiceCharge:  info.ServiceCharge,
+			DeliveryFee:    info.DeliveryFee,
+			DeliveryZone:   info.DeliveryZone,
+			Tip:            info.Tip,
+			PointsEarned:   info.PointsEarned,
+			PointsRedeemed: info.PointsRedeemed,
+
+			ScheduledFor: info.ScheduledFor,
+			ReleaseAt:    info.ReleaseAt,
 		},
 	}
 
-	if err := writeJSON(w, http.StatusCreated, response, nil); err != nil {
+	var headers http.Header
+	if info.CoverageWarning != "" {
+		headers = http.Header{coverageWarningHeader: []string{info.CoverageWarning}}
+	}
+
+	if err := writeJSON(w, http.StatusCreated, response, headers); err != nil {
 		h.log.Error(ctx, types.ActionValidationFailed, "failed to write response", err)
 		internalErrorResponse(w, err.Error())
 	}
 }
 
+// createOrderError returns status code of an order creation error and, for rejected orders, the fields it is about.
+func createOrderError(err error) (int, map[string]string) {
+	var outOfStock *models.OutOfStockError
+
+	switch {
+	case errors.Is(err, order.ErrTooManyRequest):
+		return http.StatusTooManyRequests, nil
+	case errors.As(err, &outOfStock):
+		return http.StatusUnprocessableEntity, map[string]string{"items": outOfStock.Error()}
+	case errors.Is(err, models.ErrTableNotFound):
+		return http.StatusUnprocessableEntity, map[string]string{"table_number": models.ErrTableNotFound.Error()}
+	case errors.Is(err, models.ErrTableClosed):
+		return http.StatusUnprocessableEntity, map[string]string{"table_number": models.ErrTableClosed.Error()}
+	case errors.Is(err, models.ErrCustomerNotFound):
+		return http.StatusUnprocessableEntity, map[string]string{"customer_id": err.Error()}
+	case errors.Is(err, models.ErrAddressNotFound), errors.Is(err, models.ErrOutsideDeliveryZone):
+		return http.StatusUnprocessableEntity, map[string]string{"delivery_address": err.Error()}
+	case errors.Is(err, models.ErrBelowZoneMinimum):
+		return http.StatusUnprocessableEntity, map[string]string{"items": err.Error()}
+	case errors.Is(err, models.ErrCustomerNoAddress):
+		return http.StatusUnprocessableEntity, map[string]string{"delivery_address": err.Error()}
+	case errors.Is(err, models.ErrPromotionNotFound), errors.Is(err, models.ErrPromotionInactive),
+		errors.Is(err, models.ErrPromotionExhausted), errors.Is(err, models.ErrPromotionNotApplicable):
+		return http.StatusUnprocessableEntity, map[string]string{"promo_code": err.Error()}
+	case errors.Is(err, models.ErrNotEnoughPoints):
+		return http.StatusUnprocessableEntity, map[string]string{"redeem_points": err.Error()}
+	default:
+		return http.StatusInternalServerError, nil
+	}
+}
+
+// ExplainPriority is a dry run of order creation. It returns priority the order would get
+// and explains which rule produced it. The order is not created.
+func (h *Order) ExplainPriority(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	var req dto.CreateOrderRequest
+	if err := readJSON(w, r, &req); err != nil {
+		h.log.Error(ctx, types.ActionValidationFailed, "failed to decode request", err)
+		errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	createOrder := dto.FromRequestToInternalCreateOrder(req)
+	createOrder.LocationID = location
+
+	v := validator.New()
+	dto.ValidateCreateOrderRequest(v, createOrder)
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	decision := h.service.ExplainPriority(ctx, createOrder)
+
+	response := envelope{
+		"total_amount": createOrder.TotalAmount,
+		"priority":     decision,
+	}
+
+	if err := writeJSON(w, http.StatusOK, response, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// CancelOrder cancels a scheduled order. Orders already released to the kitchen can't be cancelled.
+func (h *Order) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+	orderNumber := r.PathValue("order_number")
+
+	if err := h.service.CancelOrder(ctx, location, orderNumber); err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	response := envelope{
+		"location_id":  location,
+		"order_number": orderNumber,
+		"status":       types.StatusOrderCancelled,
+	}
+
+	if err := writeJSON(w, http.StatusOK, response, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// ModifyOrder adds, removes or changes items of an order the kitchen has not taken yet.
+// The order is recalculated and republished with a new version.
+func (h *Order) ModifyOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+	orderNumber := r.PathValue("order_number")
+
+	var req dto.ModifyOrderRequest
+	if err := readJSON(w, r, &req); err != nil {
+		h.log.Error(ctx, types.ActionValidationFailed, "failed to decode request", err)
+		errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	mod := dto.FromRequestToInternalModification(req)
+
+	v := validator.New()
+	dto.ValidateModifyOrderRequest(v, mod)
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	modified, err := h.service.ModifyOrder(ctx, location, orderNumber, mod)
+	if err != nil {
+		var outOfStock *models.OutOfStockError
+		switch {
+		case errors.As(err, &outOfStock):
+			failedValidationResponse(w, map[string]string{"items": outOfStock.Error()})
+		case errors.Is(err, models.ErrInvalidModification), errors.Is(err, models.ErrBelowZoneMinimum):
+			failedValidationResponse(w, map[string]string{"items": err.Error()})
+		case errors.Is(err, models.ErrAddressNotFound), errors.Is(err, models.ErrOutsideDeliveryZone):
+			failedValidationResponse(w, map[string]string{"delivery_address": err.Error()})
+		case errors.Is(err, models.ErrPromotionNotFound), errors.Is(err, models.ErrPromotionNotApplicable):
+			failedValidationResponse(w, map[string]string{"promo_code": err.Error()})
+		default:
+			errorResponse(w, getCode(err), err.Error())
+		}
+		return
+	}
+
+	response := envelope{"order_info": dto.FromInternalModifiedOrder(modified)}
+
+	if err := writeJSON(w, http.StatusOK, response, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
 // Post request to create order. TODO: delete
 //	{
 //	    "customer_name": "John",
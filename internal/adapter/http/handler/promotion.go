@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/handler/dto"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+type PromotionService interface {
+	Create(ctx context.Context, p models.Promotion) (models.Promotion, error)
+	List(ctx context.Context) ([]models.Promotion, error)
+}
+
+type Promotion struct {
+	service PromotionService
+	log     logger.Logger
+}
+
+func NewPromotion(service PromotionService, log logger.Logger) *Promotion {
+	return &Promotion{
+		service: service,
+		log:     log,
+	}
+}
+
+// Create creates a promo code.
+func (h *Promotion) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.PromotionRequest
+	if err := readJSON(w, r, &req); err != nil {
+		h.log.Error(ctx, types.ActionValidationFailed, "failed to decode request", err)
+		errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	promotion := dto.FromPromotionRequest(req)
+
+	v := validator.New()
+	dto.ValidatePromotion(v, promotion)
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	promotion, err := h.service.Create(ctx, promotion)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusCreated, envelope{"promotion": promotion}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// List returns all promo codes with their usage.
+func (h *Promotion) List(w http.ResponseWriter, r *http.Request) {
+	promotions, err := h.service.List(r.Context())
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"promotions": promotions}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
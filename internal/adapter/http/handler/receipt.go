@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Receipt formats
+const (
+	receiptJSON = "json"
+	receiptText = "text"
+	receiptHTML = "html"
+)
+
+// GetReceipt returns receipt of the order as JSON, plain text or printable HTML.
+// The format is taken from `format` query parameter, then from Accept header, JSON by default.
+func (h *Tracking) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	format, ok := receiptFormat(r)
+	if !ok {
+		failedValidationResponse(w, map[string]string{"format": "must be one of: json, text, html"})
+		return
+	}
+
+	receipt, err := h.service.GetReceipt(ctx, location, r.PathValue("order_number"))
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	switch format {
+	case receiptText:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writeReceiptText(w, receipt)
+	case receiptHTML:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := receiptTemplate.Execute(w, receipt); err != nil {
+			h.log.Error(ctx, "receipt_render", "failed to render receipt", err)
+		}
+	default:
+		if err := writeJSON(w, http.StatusOK, envelope{"receipt": receipt}, nil); err != nil {
+			internalErrorResponse(w, err.Error())
+		}
+	}
+}
+
+func receiptFormat(r *http.Request) (string, bool) {
+	switch format := r.URL.Query().Get("format"); format {
+	case receiptJSON, receiptText, receiptHTML:
+		return format, true
+	case "":
+	default:
+		return "", false
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, "text/html"):
+		return receiptHTML, true
+	case strings.Contains(accept, "text/plain"):
+		return receiptText, true
+	default:
+		return receiptJSON, true
+	}
+}
+
+// receiptWidth is the width of a plain text receipt in characters, as on a receipt printer.
+const receiptWidth = 42
+
+func writeReceiptText(w io.Writer, r models.Receipt) {
+	rule := strings.Repeat("-", receiptWidth) + "\n"
+	row := func(label string, amount float64) {
+		value := fmt.Sprintf("%.2f", amount)
+		fmt.Fprintf(w, "%-*s%s\n", receiptWidth-len(value), truncate(label, receiptWidth-len(value)-1), value)
+	}
+
+	fmt.Fprintf(w, "%s\n", r.OrderNumber)
+	fmt.Fprintf(w, "%s, %s\n", r.LocationID, r.CreatedAt.UTC().Format("2006-01-02 15:04 UTC"))
+	fmt.Fprintf(w, "%s, %s", r.CustomerName, r.OrderType)
+	if r.TableNumber != nil {
+		fmt.Fprintf(w, ", table %d", *r.TableNumber)
+	}
+	fmt.Fprint(w, "\n"+rule)
+
+	for _, line := range r.Lines {
+		row(fmt.Sprintf("%d x %s", line.Quantity, line.Name), line.Amount)
+		if line.Quantity > 1 {
+			fmt.Fprintf(w, "    @ %.2f\n", line.UnitPrice)
+		}
+	}
+	fmt.Fprint(w, rule)
+
+	row("Subtotal", r.Subtotal)
+	for _, d := range r.Discounts {
+		row(discountLabel(d), -d.Amount)
+	}
+	for _, t := range r.Taxes {
+		row(fmt.Sprintf("Tax %s %s%%", t.Category, formatRate(t.Rate)), t.Amount)
+	}
+	if r.ServiceCharge > 0 {
+		row("Service charge", r.ServiceCharge)
+	}
+	if r.DeliveryFee > 0 {
+		row("Delivery fee", r.DeliveryFee)
+	}
+	if r.Tip > 0 {
+		row("Tip", r.Tip)
+	}
+	fmt.Fprint(w, rule)
+	row("TOTAL", r.Total)
+
+	if r.PointsRedeemed > 0 {
+		fmt.Fprintf(w, "Loyalty points redeemed: %d\n", r.PointsRedeemed)
+	}
+	if r.PointsEarned > 0 {
+		fmt.Fprintf(w, "Loyalty points earned: %d\n", r.PointsEarned)
+	}
+}
+
+func discountLabel(d models.Discount) string {
+	if d.Code != "" {
+		return d.Code + " " + d.Description
+	}
+	return d.Description
+}
+
+func formatRate(rate float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", rate), "0"), ".")
+}
+
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+var receiptTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"money":    func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"rate":     formatRate,
+	"discount": discountLabel,
+	"date":     func(r models.Receipt) string { return r.CreatedAt.UTC().Format("2006-01-02 15:04 UTC") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Receipt {{.OrderNumber}}</title>
+<style>
+  body { font-family: monospace; width: 72mm; margin: 0 auto; }
+  table { width: 100%; border-collapse: collapse; }
+  td.amount { text-align: right; }
+  tr.total td { border-top: 1px dashed #000; font-weight: bold; }
+  hr { border: 0; border-top: 1px dashed #000; }
+  @media print { @page { margin: 0; } }
+</style>
+</head>
+<body>
+<h3>{{.OrderNumber}}</h3>
+<p>{{.LocationID}}, {{date .}}<br>{{.CustomerName}}, {{.OrderType}}{{with .TableNumber}}, table {{.}}{{end}}</p>
+<hr>
+<table>
+{{- range .Lines}}
+<tr><td>{{.Quantity}} x {{.Name}}{{if gt .Quantity 1}}<br><small>@ {{money .UnitPrice}}</small>{{end}}</td><td class="amount">{{money .Amount}}</td></tr>
+{{- end}}
+</table>
+<hr>
+<table>
+<tr><td>Subtotal</td><td class="amount">{{money .Subtotal}}</td></tr>
+{{- range .Discounts}}
+<tr><td>{{discount .}}</td><td class="amount">-{{money .Amount}}</td></tr>
+{{- end}}
+{{- range .Taxes}}
+<tr><td>Tax {{.Category}} {{rate .Rate}}%</td><td class="amount">{{money .Amount}}</td></tr>
+{{- end}}
+{{- if gt .ServiceCharge 0.0}}
+<tr><td>Service charge</td><td class="amount">{{money .ServiceCharge}}</td></tr>
+{{- end}}
+{{- if gt .DeliveryFee 0.0}}
+<tr><td>Delivery fee</td><td class="amount">{{money .DeliveryFee}}</td></tr>
+{{- end}}
+{{- if gt .Tip 0.0}}
+<tr><td>Tip</td><td class="amount">{{money .Tip}}</td></tr>
+{{- end}}
+<tr class="total"><td>TOTAL</td><td class="amount">{{money .Total}}</td></tr>
+</table>
+{{- if .PointsRedeemed}}
+<p>Loyalty points redeemed: {{.PointsRedeemed}}</p>
+{{- end}}
+{{- if .PointsEarned}}
+<p>Loyalty points earned: {{.PointsEarned}}</p>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/handler/dto"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
+)
+
+type TableService interface {
+	Create(ctx context.Context, t models.Table) (models.Table, error)
+	List(ctx context.Context, locationID string) ([]models.Table, error)
+	Floor(ctx context.Context, locationID string) (models.Floor, error)
+	Open(ctx context.Context, locationID string, number, guests int) (models.Table, error)
+	Close(ctx context.Context, locationID string, number int) (models.TableBill, error)
+	Clean(ctx context.Context, locationID string, number int) (models.Table, error)
+	Bill(ctx context.Context, locationID string, number int) (models.TableBill, error)
+}
+
+type Table struct {
+	service  TableService
+	location string // default location for routes without location
+	log      logger.Logger
+}
+
+func NewTable(service TableService, location string, log logger.Logger) *Table {
+	return &Table{
+		service:  service,
+		location: location,
+		log:      log,
+	}
+}
+
+// Create adds a table to the location.
+func (h *Table) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	var req dto.TableRequest
+	if err := readJSON(w, r, &req); err != nil {
+		h.log.Error(ctx, types.ActionValidationFailed, "failed to decode request", err)
+		errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	table := dto.FromTableRequest(req, location)
+
+	v := validator.New()
+	dto.ValidateTable(v, table)
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	table, err := h.service.Create(ctx, table)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusCreated, envelope{"table": table}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// List returns tables of the location.
+func (h *Table) List(w http.ResponseWriter, r *http.Request) {
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	tables, err := h.service.List(r.Context(), location)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	response := envelope{
+		"location_id": location,
+		"tables":      tables,
+	}
+	if err := writeJSON(w, http.StatusOK, response, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// Floor returns all tables of the location with their sessions and orders.
+func (h *Table) Floor(w http.ResponseWriter, r *http.Request) {
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	floor, err := h.service.Floor(r.Context(), location)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"floor": floor}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// Open seats guests at a free table.
+func (h *Table) Open(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, number, ok := h.table(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.OpenTableRequest
+	if err := readJSON(w, r, &req); err != nil {
+		h.log.Error(ctx, types.ActionValidationFailed, "failed to decode request", err)
+		errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	v := validator.New()
+	dto.ValidateOpenTable(v, req)
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	table, err := h.service.Open(ctx, location, number, req.Guests)
+	if err != nil {
+		if errors.Is(err, models.ErrTableCapacity) {
+			failedValidationResponse(w, map[string]string{"guests": err.Error()})
+			return
+		}
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"table": table}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// Close closes the table session and returns the combined bill.
+func (h *Table) Close(w http.ResponseWriter, r *http.Request) {
+	location, number, ok := h.table(w, r)
+	if !ok {
+		return
+	}
+
+	bill, err := h.service.Close(r.Context(), location, number)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"bill": bill}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// Clean marks a table of a closed session free.
+func (h *Table) Clean(w http.ResponseWriter, r *http.Request) {
+	location, number, ok := h.table(w, r)
+	if !ok {
+		return
+	}
+
+	table, err := h.service.Clean(r.Context(), location, number)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"table": table}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// Bill returns the combined bill of the current or the last session of the table.
+func (h *Table) Bill(w http.ResponseWriter, r *http.Request) {
+	location, number, ok := h.table(w, r)
+	if !ok {
+		return
+	}
+
+	bill, err := h.service.Bill(r.Context(), location, number)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"bill": bill}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// table returns location and table number from the path, it answers 400 if they are invalid.
+func (h *Table) table(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return "", 0, false
+	}
+
+	number, err := strconv.Atoi(r.PathValue("table_number"))
+	if err != nil || number < 1 || number > 100 {
+		errorResponse(w, http.StatusBadRequest, "invalid table number")
+		return "", 0, false
+	}
+
+	return location, number, true
+}
@@ -5,33 +5,51 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/handler/dto"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/validator"
 )
 
 type TrackingService interface {
-	GetOrderStatus(ctx context.Context, orderNumber string) (models.OrderStatus, error)
-	GetTrackingHistory(ctx context.Context, orderNumber string) ([]models.OrderHistory, error)
-	ListWorkers(ctx context.Context) ([]models.Worker, error)
+	GetOrderStatus(ctx context.Context, locationID, orderNumber string) (models.OrderStatus, error)
+	GetTrackingHistory(ctx context.Context, locationID, orderNumber string) ([]models.OrderHistory, error)
+	ListWorkers(ctx context.Context, locationID string) ([]models.Worker, error)
+	GetWorker(ctx context.Context, locationID, name string) (models.WorkerDetail, error)
+	CommandWorker(ctx context.Context, locationID, name, command string) (models.Worker, error)
+	ListShifts(ctx context.Context, locationID string) (models.ShiftSchedule, error)
+	SetShifts(ctx context.Context, locationID, workerName string, shifts []models.Shift) (models.ShiftSchedule, error)
+	GetOrder(ctx context.Context, locationID, orderNumber string) (models.Order, error)
+	GetReceipt(ctx context.Context, locationID, orderNumber string) (models.Receipt, error)
+	ListOrders(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error)
 }
 
 type Tracking struct {
-	service TrackingService
-	log     logger.Logger
+	service  TrackingService
+	location string // default location for routes without location
+	log      logger.Logger
 }
 
-func NewTracking(service TrackingService, log logger.Logger) *Tracking {
+func NewTracking(service TrackingService, location string, log logger.Logger) *Tracking {
 	return &Tracking{
-		service: service,
-		log:     log,
+		service:  service,
+		location: location,
+		log:      log,
 	}
 }
 
 func (h *Tracking) GetOrderStatus(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
 	orderNumber := r.PathValue("order_number")
 
-	orderStatus, err := h.service.GetOrderStatus(ctx, orderNumber)
+	orderStatus, err := h.service.GetOrderStatus(ctx, location, orderNumber)
 	if err != nil {
 		errorResponse(w, getCode(err), err.Error())
 		return
@@ -45,9 +63,15 @@ func (h *Tracking) GetOrderStatus(w http.ResponseWriter, r *http.Request) {
 
 func (h *Tracking) GetTrackingHistory(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
 	orderNumber := r.PathValue("order_number")
 
-	historyList, err := h.service.GetTrackingHistory(ctx, orderNumber)
+	historyList, err := h.service.GetTrackingHistory(ctx, location, orderNumber)
 	if err != nil {
 		errorResponse(w, getCode(err), err.Error())
 		return
@@ -62,7 +86,13 @@ func (h *Tracking) GetTrackingHistory(w http.ResponseWriter, r *http.Request) {
 func (h *Tracking) ListWorkers(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	workersList, err := h.service.ListWorkers(ctx)
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	workersList, err := h.service.ListWorkers(ctx, location)
 	if err != nil {
 		errorResponse(w, getCode(err), err.Error())
 		return
@@ -73,3 +103,193 @@ func (h *Tracking) ListWorkers(w http.ResponseWriter, r *http.Request) {
 		internalErrorResponse(w, err.Error())
 	}
 }
+
+// GetWorker returns the worker with its latest sessions.
+func (h *Tracking) GetWorker(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	worker, err := h.service.GetWorker(ctx, location, r.PathValue("worker_name"))
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(worker); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// CommandWorker gives pause, resume, drain or evict command to the kitchen worker.
+// The worker picks it up on its next heartbeat and reports the resulting status.
+func (h *Tracking) CommandWorker(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	command := r.PathValue("command")
+	if !types.IsValidWorkerCommand(command) {
+		errorResponse(w, http.StatusBadRequest, "invalid command, must be one of: pause, resume, drain, evict")
+		return
+	}
+
+	worker, err := h.service.CommandWorker(ctx, location, r.PathValue("worker_name"), command)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusAccepted, envelope{"worker": worker}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// ListCustomerOrders returns page of orders of the customer in all locations, with the filters of ListOrders.
+func (h *Tracking) ListCustomerOrders(w http.ResponseWriter, r *http.Request) {
+	id, ok := customerID(r)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid customer id")
+		return
+	}
+
+	v := validator.New()
+	filter := dto.ParseOrderFilter(v, r.URL.Query())
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+	filter.CustomerID = &id
+
+	page, err := h.service.ListOrders(r.Context(), filter)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{
+		"customer_id": id,
+		"orders":      dto.FromInternalOrderPage(page),
+	}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// ListShifts returns shifts of the location workers and weekly windows left uncovered for each order type.
+func (h *Tracking) ListShifts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	schedule, err := h.service.ListShifts(ctx, location)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"schedule": schedule}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// SetShifts replaces shifts of the worker. Empty list removes the schedule, so the worker is always on duty.
+func (h *Tracking) SetShifts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	var req dto.ShiftsRequest
+	if err := readJSON(w, r, &req); err != nil {
+		h.log.Error(ctx, types.ActionValidationFailed, "failed to decode request", err)
+		errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	shifts := dto.FromShiftsRequest(req, r.PathValue("worker_name"))
+
+	v := validator.New()
+	dto.ValidateShifts(v, shifts)
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+
+	schedule, err := h.service.SetShifts(ctx, location, r.PathValue("worker_name"), shifts)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, envelope{"schedule": schedule}, nil); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+func (h *Tracking) GetOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+	orderNumber := r.PathValue("order_number")
+
+	order, err := h.service.GetOrder(ctx, location, orderNumber)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dto.FromInternalOrder(order)); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
+
+// ListOrders returns page of orders matching query filters, newest first by default.
+func (h *Tracking) ListOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	location, ok := locationID(r, h.location)
+	if !ok {
+		errorResponse(w, http.StatusBadRequest, "invalid location")
+		return
+	}
+
+	v := validator.New()
+	filter := dto.ParseOrderFilter(v, r.URL.Query())
+	if !v.Valid() {
+		failedValidationResponse(w, v.Errors)
+		return
+	}
+	filter.LocationID = location
+
+	page, err := h.service.ListOrders(ctx, filter)
+	if err != nil {
+		errorResponse(w, getCode(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dto.FromInternalOrderPage(page)); err != nil {
+		internalErrorResponse(w, err.Error())
+	}
+}
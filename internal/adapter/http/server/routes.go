@@ -25,16 +25,91 @@ func (a *API) setupDefaultRoutes() {
 	a.mux.HandleFunc("/health", a.HealthCheck)
 }
 
-// setupOrderRoutes setups routes for order service
+// setupOrderRoutes setups routes for order service.
+// Routes without location work with the location of the service.
 func (a *API) setupOrderRoutes() {
 	a.mux.HandleFunc("POST /orders", a.routes.order.CreateOrder)
+	a.mux.HandleFunc("POST /orders/priority", a.routes.order.ExplainPriority)
+	a.mux.HandleFunc("POST /orders/batch", a.routes.order.CreateBatch)
+	a.mux.HandleFunc("POST /orders/{order_number}/cancel", a.routes.order.CancelOrder)
+	a.mux.HandleFunc("PATCH /orders/{order_number}", a.routes.order.ModifyOrder)
+	a.mux.HandleFunc("POST /locations/{location_id}/orders", a.routes.order.CreateOrder)
+	a.mux.HandleFunc("POST /locations/{location_id}/orders/priority", a.routes.order.ExplainPriority)
+	a.mux.HandleFunc("POST /locations/{location_id}/orders/batch", a.routes.order.CreateBatch)
+	a.mux.HandleFunc("POST /locations/{location_id}/orders/{order_number}/cancel", a.routes.order.CancelOrder)
+	a.mux.HandleFunc("PATCH /locations/{location_id}/orders/{order_number}", a.routes.order.ModifyOrder)
+
+	// Inventory admin
+	a.mux.HandleFunc("GET /inventory", a.routes.inventory.ListStock)
+	a.mux.HandleFunc("POST /inventory/restock", a.routes.inventory.Restock)
+	a.mux.HandleFunc("GET /recipes", a.routes.inventory.ListRecipes)
+	a.mux.HandleFunc("PUT /recipes/{item_name}", a.routes.inventory.SetRecipe)
+	a.mux.HandleFunc("GET /locations/{location_id}/inventory", a.routes.inventory.ListStock)
+	a.mux.HandleFunc("POST /locations/{location_id}/inventory/restock", a.routes.inventory.Restock)
+	a.mux.HandleFunc("GET /locations/{location_id}/recipes", a.routes.inventory.ListRecipes)
+
+	// Customer accounts, shared by all locations
+	a.mux.HandleFunc("POST /customers", a.routes.customer.Create)
+	a.mux.HandleFunc("GET /customers", a.routes.customer.FindByPhone)
+	a.mux.HandleFunc("GET /customers/{customer_id}", a.routes.customer.Get)
+
+	// Promo codes, shared by all locations
+	a.mux.HandleFunc("POST /promotions", a.routes.promotion.Create)
+	a.mux.HandleFunc("GET /promotions", a.routes.promotion.List)
+
+	// Dining tables
+	a.mux.HandleFunc("POST /tables", a.routes.table.Create)
+	a.mux.HandleFunc("GET /tables", a.routes.table.List)
+	a.mux.HandleFunc("POST /tables/{table_number}/open", a.routes.table.Open)
+	a.mux.HandleFunc("POST /tables/{table_number}/close", a.routes.table.Close)
+	a.mux.HandleFunc("POST /tables/{table_number}/clean", a.routes.table.Clean)
+	a.mux.HandleFunc("GET /tables/{table_number}/bill", a.routes.table.Bill)
+	a.mux.HandleFunc("POST /locations/{location_id}/tables", a.routes.table.Create)
+	a.mux.HandleFunc("GET /locations/{location_id}/tables", a.routes.table.List)
+	a.mux.HandleFunc("POST /locations/{location_id}/tables/{table_number}/open", a.routes.table.Open)
+	a.mux.HandleFunc("POST /locations/{location_id}/tables/{table_number}/close", a.routes.table.Close)
+	a.mux.HandleFunc("POST /locations/{location_id}/tables/{table_number}/clean", a.routes.table.Clean)
+	a.mux.HandleFunc("GET /locations/{location_id}/tables/{table_number}/bill", a.routes.table.Bill)
 }
 
 // setupTrackingRoutes setups routes for tracking service
+// Routes without location work with the location of the service.
 func (a *API) setupTrackingRoutes() {
+	a.mux.HandleFunc("GET /orders", a.routes.tracking.ListOrders)
+	a.mux.HandleFunc("GET /orders/{order_number}", a.routes.tracking.GetOrder)
 	a.mux.HandleFunc("GET /orders/{order_number}/status", a.routes.tracking.GetOrderStatus)
 	a.mux.HandleFunc("GET /orders/{order_number}/history", a.routes.tracking.GetTrackingHistory)
+	a.mux.HandleFunc("GET /orders/{order_number}/receipt", a.routes.tracking.GetReceipt)
 	a.mux.HandleFunc("GET /workers/status", a.routes.tracking.ListWorkers)
+	a.mux.HandleFunc("GET /workers/{worker_name}", a.routes.tracking.GetWorker)
+	a.mux.HandleFunc("POST /workers/{worker_name}/{command}", a.routes.tracking.CommandWorker)
+	a.mux.HandleFunc("PUT /workers/{worker_name}/shifts", a.routes.tracking.SetShifts)
+	a.mux.HandleFunc("GET /shifts", a.routes.tracking.ListShifts)
+	a.mux.HandleFunc("GET /customers/{customer_id}/orders", a.routes.tracking.ListCustomerOrders)
+	a.mux.HandleFunc("GET /floor", a.routes.table.Floor)
+
+	a.mux.HandleFunc("GET /locations/{location_id}/orders", a.routes.tracking.ListOrders)
+	a.mux.HandleFunc("GET /locations/{location_id}/orders/{order_number}", a.routes.tracking.GetOrder)
+	a.mux.HandleFunc("GET /locations/{location_id}/orders/{order_number}/status", a.routes.tracking.GetOrderStatus)
+	a.mux.HandleFunc("GET /locations/{location_id}/orders/{order_number}/history", a.routes.tracking.GetTrackingHistory)
+	a.mux.HandleFunc("GET /locations/{location_id}/orders/{order_number}/receipt", a.routes.tracking.GetReceipt)
+	a.mux.HandleFunc("GET /locations/{location_id}/workers/status", a.routes.tracking.ListWorkers)
+	a.mux.HandleFunc("GET /locations/{location_id}/workers/{worker_name}", a.routes.tracking.GetWorker)
+	a.mux.HandleFunc("POST /locations/{location_id}/workers/{worker_name}/{command}", a.routes.tracking.CommandWorker)
+	a.mux.HandleFunc("PUT /locations/{location_id}/workers/{worker_name}/shifts", a.routes.tracking.SetShifts)
+	a.mux.HandleFunc("GET /locations/{location_id}/shifts", a.routes.tracking.ListShifts)
+	a.mux.HandleFunc("GET /locations/{location_id}/floor", a.routes.table.Floor)
+
+	// Kitchen analytics
+	a.mux.HandleFunc("GET /analytics/throughput", a.routes.analytics.Throughput)
+	a.mux.HandleFunc("GET /analytics/durations", a.routes.analytics.Durations)
+	a.mux.HandleFunc("GET /analytics/workers", a.routes.analytics.Workers)
+	a.mux.HandleFunc("GET /analytics/sla", a.routes.analytics.SLA)
+
+	a.mux.HandleFunc("GET /locations/{location_id}/analytics/throughput", a.routes.analytics.Throughput)
+	a.mux.HandleFunc("GET /locations/{location_id}/analytics/durations", a.routes.analytics.Durations)
+	a.mux.HandleFunc("GET /locations/{location_id}/analytics/workers", a.routes.analytics.Workers)
+	a.mux.HandleFunc("GET /locations/{location_id}/analytics/sla", a.routes.analytics.SLA)
 }
 
 // HealthCheck - returns system information.
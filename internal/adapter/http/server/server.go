@@ -27,16 +27,26 @@ type API struct {
 }
 
 type handlers struct {
-	order    *handler.Order
-	tracking *handler.Tracking
+	order     *handler.Order
+	tracking  *handler.Tracking
+	analytics *handler.Analytics
+	inventory *handler.Inventory
+	customer  *handler.Customer
+	promotion *handler.Promotion
+	table     *handler.Table
 }
 
-func New(cfg config.Config, orderService handler.OrderService, trackingService handler.TrackingService, logger logger.Logger) *API {
+func New(cfg config.Config, orderService handler.OrderService, trackingService handler.TrackingService, analyticsService handler.AnalyticsService, inventoryService handler.InventoryService, customerService handler.CustomerService, promotionService handler.PromotionService, tableService handler.TableService, logger logger.Logger) *API {
 	addr := fmt.Sprintf(serverIPAddress, "0.0.0.0", cfg.HTTPServer.Port)
 
 	handlers := &handlers{
-		order:    handler.NewOrder(orderService, logger),
-		tracking: handler.NewTracking(trackingService, logger),
+		order:     handler.NewOrder(orderService, cfg.Location, logger),
+		tracking:  handler.NewTracking(trackingService, cfg.Location, logger),
+		analytics: handler.NewAnalytics(analyticsService, cfg.Location, logger),
+		inventory: handler.NewInventory(inventoryService, cfg.Location, logger),
+		customer:  handler.NewCustomer(customerService, logger),
+		promotion: handler.NewPromotion(promotionService, logger),
+		table:     handler.NewTable(tableService, cfg.Location, logger),
 	}
 
 	api := &API{
@@ -50,13 +60,13 @@ func New(cfg config.Config, orderService handler.OrderService, trackingService h
 		log:  logger,
 	}
 
+	api.setupRoutes()
+
 	api.server = &http.Server{
 		Addr:    api.addr,
-		Handler: api.mux,
+		Handler: api.withMiddleware(),
 	}
 
-	api.setupRoutes()
-
 	return api
 }
 
@@ -76,7 +86,7 @@ func (a *API) Stop(ctx context.Context) error {
 func (a *API) Run(ctx context.Context, errCh chan<- error) {
 	go func() {
 		a.log.Info(ctx, "http_server_run", "started http server", "address", a.addr)
-		if err := http.ListenAndServe(a.addr, a.withMiddleware()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errCh <- fmt.Errorf("failed to start HTTP server: %w", err)
 			return
 		}
@@ -0,0 +1,460 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Exchange kinds supported by the broker.
+const (
+	ExchangeTopic  = "topic"
+	ExchangeFanout = "fanout"
+)
+
+var (
+	ErrExchangeNotFound = errors.New("exchange is not found")
+	ErrQueueNotFound    = errors.New("queue is not found")
+	ErrDeclareMismatch  = errors.New("declared with different arguments")
+	ErrAlreadyAcked     = errors.New("delivery is already acknowledged")
+)
+
+// Broker is an in-memory message broker with RabbitMQ semantics used by the services:
+// topic and fanout exchanges, priority queues, prefetch, ack/nack with requeue and dead-lettering.
+// Messages are not persisted.
+type Broker struct {
+	mu        sync.Mutex
+	exchanges map[string]*exchange
+	queues    map[string]*queue
+	seq       uint64 // publish order of messages, keeps queues FIFO within a priority
+	anonymous int    // number of server-named queues
+}
+
+type exchange struct {
+	kind     string
+	bindings []binding
+}
+
+type binding struct {
+	queue string
+	key   string
+}
+
+// QueueOptions are arguments of a queue.
+type QueueOptions struct {
+	DeadLetterExchange   string // rejected messages are published to it, dropped if empty
+	DeadLetterRoutingKey string // routing key of dead-lettered messages, the original one if empty
+	MaxPriority          int    // messages are delivered by priority up to it, 0 disables priorities
+}
+
+// Message is a message stored in a queue.
+type Message struct {
+	Exchange    string
+	RoutingKey  string
+	Type        string
+	Priority    int
+	Body        []byte
+	Redelivered bool
+	Deaths      int // times the message was dead-lettered
+
+	seq uint64
+}
+
+type queue struct {
+	name    string
+	opts    QueueOptions
+	ready   []*Message
+	unacked int
+	changed chan struct{} // closed and replaced each time a message may have become deliverable
+}
+
+// notify wakes up consumers of the queue, must be called with the broker mutex held.
+func (q *queue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// pop removes the next message to deliver: the highest priority one, oldest first.
+func (q *queue) pop() *Message {
+	best := -1
+	for i, msg := range q.ready {
+		if best < 0 || q.priority(msg) > q.priority(q.ready[best]) ||
+			(q.priority(msg) == q.priority(q.ready[best]) && msg.seq < q.ready[best].seq) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+
+	msg := q.ready[best]
+	q.ready = append(q.ready[:best], q.ready[best+1:]...)
+	return msg
+}
+
+// priority returns priority of the message in the queue, capped by its maximum priority.
+func (q *queue) priority(msg *Message) int {
+	return max(0, min(msg.Priority, q.opts.MaxPriority))
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		exchanges: make(map[string]*exchange),
+		queues:    make(map[string]*queue),
+	}
+}
+
+// DeclareExchange creates the exchange if it does not exist. Declaring an existing exchange
+// with another kind fails.
+func (b *Broker) DeclareExchange(name, kind string) error {
+	if kind != ExchangeTopic && kind != ExchangeFanout {
+		return fmt.Errorf("exchange %s: unsupported kind %q", name, kind)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ex, ok := b.exchanges[name]; ok {
+		if ex.kind != kind {
+			return fmt.Errorf("exchange %s: %w", name, ErrDeclareMismatch)
+		}
+		return nil
+	}
+	b.exchanges[name] = &exchange{kind: kind}
+
+	return nil
+}
+
+// DeclareQueue creates the queue if it does not exist and returns its name. Queues with empty name
+// are named by the broker. Declaring an existing queue with other options fails.
+func (b *Broker) DeclareQueue(name string, opts QueueOptions) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if name == "" {
+		b.anonymous++
+		name = fmt.Sprintf("amq.gen-%d", b.anonymous)
+	}
+
+	if q, ok := b.queues[name]; ok {
+		if q.opts != opts {
+			return "", fmt.Errorf("queue %s: %w", name, ErrDeclareMismatch)
+		}
+		return name, nil
+	}
+	b.queues[name] = &queue{name: name, opts: opts, changed: make(chan struct{})}
+
+	return name, nil
+}
+
+// DeleteQueue deletes the queue with its messages and bindings. Consumers of the queue stop.
+func (b *Broker) DeleteQueue(name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[name]
+	if !ok {
+		return fmt.Errorf("queue %s: %w", name, ErrQueueNotFound)
+	}
+	delete(b.queues, name)
+	q.notify()
+
+	for _, ex := range b.exchanges {
+		bindings := ex.bindings[:0]
+		for _, bind := range ex.bindings {
+			if bind.queue != name {
+				bindings = append(bindings, bind)
+			}
+		}
+		ex.bindings = bindings
+	}
+
+	return nil
+}
+
+// Bind routes messages of the exchange matching the binding key to the queue.
+func (b *Broker) Bind(queueName, key, exchangeName string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ex, ok := b.exchanges[exchangeName]
+	if !ok {
+		return fmt.Errorf("exchange %s: %w", exchangeName, ErrExchangeNotFound)
+	}
+	if _, ok := b.queues[queueName]; !ok {
+		return fmt.Errorf("queue %s: %w", queueName, ErrQueueNotFound)
+	}
+
+	for _, bind := range ex.bindings {
+		if bind.queue == queueName && bind.key == key {
+			return nil
+		}
+	}
+	ex.bindings = append(ex.bindings, binding{queue: queueName, key: key})
+
+	return nil
+}
+
+// Publish routes a copy of the message to every queue bound to the exchange. Returns number of queues
+// the message was routed to, unroutable messages are dropped.
+func (b *Broker) Publish(exchangeName, routingKey string, msg Message) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	msg.Exchange = exchangeName
+	msg.RoutingKey = routingKey
+	msg.Redelivered = false
+
+	return b.route(msg)
+}
+
+// route delivers the message to queues of its exchange, must be called with the mutex held.
+func (b *Broker) route(msg Message) (int, error) {
+	ex, ok := b.exchanges[msg.Exchange]
+	if !ok {
+		return 0, fmt.Errorf("exchange %s: %w", msg.Exchange, ErrExchangeNotFound)
+	}
+
+	routed := 0
+	seen := make(map[string]struct{})
+	for _, bind := range ex.bindings {
+		if _, ok := seen[bind.queue]; ok {
+			continue
+		}
+		if ex.kind == ExchangeTopic && !matchTopic(bind.key, msg.RoutingKey) {
+			continue
+		}
+		seen[bind.queue] = struct{}{}
+
+		q := b.queues[bind.queue]
+		b.seq++
+		m := msg
+		m.Body = append([]byte(nil), msg.Body...)
+		m.seq = b.seq
+		q.ready = append(q.ready, &m)
+		q.notify()
+		routed++
+	}
+
+	return routed, nil
+}
+
+// matchTopic reports whether the routing key matches the binding key of a topic exchange.
+// '*' matches exactly one word, '#' matches zero or more words.
+func matchTopic(pattern, key string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(key, "."))
+}
+
+func matchWords(pattern, key []string) bool {
+	if len(pattern) == 0 {
+		return len(key) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(key); i++ {
+			if matchWords(pattern[1:], key[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(key) > 0 && matchWords(pattern[1:], key[1:])
+	default:
+		return len(key) > 0 && pattern[0] == key[0] && matchWords(pattern[1:], key[1:])
+	}
+}
+
+// Ready returns messages of the queue waiting for delivery, in order they will be delivered.
+func (b *Broker) Ready(queueName string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[queueName]
+	if !ok {
+		return nil
+	}
+
+	// Delivery order without changing the queue
+	rest := &queue{opts: q.opts, ready: append([]*Message(nil), q.ready...)}
+	messages := make([]Message, 0, len(q.ready))
+	for msg := rest.pop(); msg != nil; msg = rest.pop() {
+		messages = append(messages, *msg)
+	}
+
+	return messages
+}
+
+// Unacked returns number of messages of the queue delivered but not acknowledged yet.
+func (b *Broker) Unacked(queueName string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if q, ok := b.queues[queueName]; ok {
+		return q.unacked
+	}
+	return 0
+}
+
+// Channel is a channel of consumers sharing prefetch, like an AMQP channel with basic.qos.
+type Channel struct {
+	broker   *Broker
+	prefetch int // per consumer, 0 is unlimited
+}
+
+func (b *Broker) Channel() *Channel {
+	return &Channel{broker: b}
+}
+
+// Qos sets number of messages each consumer of the channel can hold unacknowledged.
+// It applies to deliveries made after the call.
+func (c *Channel) Qos(prefetch int) error {
+	if prefetch < 0 {
+		return fmt.Errorf("invalid prefetch %d", prefetch)
+	}
+
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+
+	c.prefetch = prefetch
+	for _, q := range c.broker.queues {
+		q.notify()
+	}
+
+	return nil
+}
+
+// Delivery is a message delivered to a consumer. It must be acknowledged with Ack or Nack.
+type Delivery struct {
+	Message
+
+	broker   *Broker
+	queue    *queue
+	consumer *consumer
+	done     bool
+}
+
+type consumer struct {
+	unacked int
+}
+
+// Consume delivers messages of the queue until ctx is cancelled or the queue is deleted,
+// then the channel is closed. Messages delivered but not acknowledged stay unacked.
+func (c *Channel) Consume(ctx context.Context, queueName string) (<-chan *Delivery, error) {
+	c.broker.mu.Lock()
+	q, ok := c.broker.queues[queueName]
+	c.broker.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("queue %s: %w", queueName, ErrQueueNotFound)
+	}
+
+	deliveries := make(chan *Delivery)
+	go c.deliver(ctx, q, deliveries)
+
+	return deliveries, nil
+}
+
+func (c *Channel) deliver(ctx context.Context, q *queue, deliveries chan<- *Delivery) {
+	defer close(deliveries)
+
+	b := c.broker
+	cons := &consumer{}
+
+	for ctx.Err() == nil {
+		b.mu.Lock()
+		if b.queues[q.name] != q {
+			b.mu.Unlock()
+			return
+		}
+
+		var msg *Message
+		if c.prefetch == 0 || cons.unacked < c.prefetch {
+			msg = q.pop()
+		}
+		if msg == nil {
+			changed := q.changed
+			b.mu.Unlock()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-changed:
+			}
+			continue
+		}
+		q.unacked++
+		cons.unacked++
+		b.mu.Unlock()
+
+		d := &Delivery{Message: *msg, broker: b, queue: q, consumer: cons}
+		select {
+		case deliveries <- d:
+		case <-ctx.Done():
+			// Not delivered, returned to the queue as is.
+			b.mu.Lock()
+			q.unacked--
+			cons.unacked--
+			q.ready = append(q.ready, msg)
+			q.notify()
+			b.mu.Unlock()
+			return
+		}
+	}
+}
+
+// Ack acknowledges the message, it is removed from the queue.
+func (d *Delivery) Ack() error {
+	return d.settle(func() {})
+}
+
+// Nack rejects the message. It is returned to the queue if requeue is set,
+// otherwise it is dead-lettered.
+func (d *Delivery) Nack(requeue bool) error {
+	return d.settle(func() {
+		msg := d.Message
+		if requeue {
+			msg.Redelivered = true
+			d.queue.ready = append(d.queue.ready, &msg)
+			return
+		}
+		d.broker.deadLetter(d.queue, msg)
+	})
+}
+
+// settle finishes the delivery with fn, called with the broker mutex held.
+func (d *Delivery) settle(fn func()) error {
+	d.broker.mu.Lock()
+	defer d.broker.mu.Unlock()
+
+	if d.done {
+		return ErrAlreadyAcked
+	}
+	d.done = true
+
+	d.queue.unacked--
+	d.consumer.unacked--
+	fn()
+	d.queue.notify()
+
+	return nil
+}
+
+// deadLetter publishes the rejected message to the dead letter exchange of the queue,
+// must be called with the mutex held.
+func (b *Broker) deadLetter(q *queue, msg Message) {
+	if q.opts.DeadLetterExchange == "" {
+		return
+	}
+
+	msg.Exchange = q.opts.DeadLetterExchange
+	if q.opts.DeadLetterRoutingKey != "" {
+		msg.RoutingKey = q.opts.DeadLetterRoutingKey
+	}
+	msg.Redelivered = false
+	msg.Deaths++
+
+	// Like RabbitMQ, messages of a missing dead letter exchange are dropped.
+	b.route(msg)
+}
@@ -0,0 +1,294 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"kitchen.loc.takeout.*", "kitchen.loc.takeout.5", true},
+		{"kitchen.loc.takeout.*", "kitchen.loc.takeout", false},
+		{"kitchen.loc.takeout.*", "kitchen.loc.delivery.5", false},
+		{"kitchen.loc.station.grill.*", "kitchen.loc.station.grill.1", true},
+		{"kitchen.loc.*.*", "kitchen.loc.station.grill.1", false},
+		{"kitchen.#", "kitchen.loc.station.grill.1", true},
+		{"kitchen.#", "kitchen", true},
+		{"#.grill.#", "kitchen.loc.station.grill.1", true},
+		{"dlq.kitchen_loc_takeout_queue", "dlq.kitchen_loc_takeout_queue", true},
+		{"dlq.kitchen_loc_takeout_queue", "dlq.kitchen_loc_delivery_queue", false},
+	}
+
+	for _, tt := range tests {
+		if got := matchTopic(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("matchTopic(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
+
+// newTestQueue declares topic exchange "ex" and queue "q" bound to it with the key, with DLQ "dlq".
+func newTestQueue(t *testing.T, bindingKey string, maxPriority int) *Broker {
+	t.Helper()
+
+	b := NewBroker()
+	must(t, b.DeclareExchange("ex", ExchangeTopic))
+	must(t, b.DeclareExchange("dlx", ExchangeTopic))
+	_, err := b.DeclareQueue("q", QueueOptions{DeadLetterExchange: "dlx", DeadLetterRoutingKey: "dlq", MaxPriority: maxPriority})
+	must(t, err)
+	_, err = b.DeclareQueue("dlq", QueueOptions{})
+	must(t, err)
+	must(t, b.Bind("q", bindingKey, "ex"))
+	must(t, b.Bind("dlq", "dlq", "dlx"))
+
+	return b
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func receive(t *testing.T, deliveries <-chan *Delivery) *Delivery {
+	t.Helper()
+
+	select {
+	case d, ok := <-deliveries:
+		if !ok {
+			t.Fatal("deliveries closed")
+		}
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("no delivery")
+		return nil
+	}
+}
+
+func TestBrokerPriorityOrder(t *testing.T) {
+	b := newTestQueue(t, "orders.*", 10)
+
+	for _, m := range []struct {
+		body     string
+		priority int
+	}{{"low-1", 1}, {"high-1", 10}, {"low-2", 1}, {"high-2", 10}, {"capped", 50}} {
+		n, err := b.Publish("ex", "orders.new", Message{Body: []byte(m.body), Priority: m.priority})
+		must(t, err)
+		if n != 1 {
+			t.Fatalf("routed to %d queues, want 1", n)
+		}
+	}
+
+	// Priorities above the queue maximum count as the maximum.
+	var got []string
+	for _, m := range b.Ready("q") {
+		got = append(got, string(m.Body))
+	}
+	want := []string{"high-1", "high-2", "capped", "low-1", "low-2"}
+	if len(got) != len(want) {
+		t.Fatalf("ready = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ready = %v, want %v", got, want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliveries, err := b.Channel().Consume(ctx, "q")
+	must(t, err)
+	for _, body := range want {
+		d := receive(t, deliveries)
+		if string(d.Body) != body {
+			t.Fatalf("delivered %s, want %s", d.Body, body)
+		}
+		must(t, d.Ack())
+	}
+}
+
+func TestBrokerUnroutable(t *testing.T) {
+	b := newTestQueue(t, "orders.*", 0)
+
+	n, err := b.Publish("ex", "payments.new", Message{Body: []byte("x")})
+	must(t, err)
+	if n != 0 {
+		t.Fatalf("routed to %d queues, want 0", n)
+	}
+
+	if _, err := b.Publish("missing", "orders.new", Message{}); !errors.Is(err, ErrExchangeNotFound) {
+		t.Fatalf("publish to missing exchange: %v, want %v", err, ErrExchangeNotFound)
+	}
+}
+
+func TestBrokerDeclareMismatch(t *testing.T) {
+	b := newTestQueue(t, "orders.*", 10)
+
+	if _, err := b.DeclareQueue("q", QueueOptions{MaxPriority: 5}); !errors.Is(err, ErrDeclareMismatch) {
+		t.Fatalf("redeclare queue: %v, want %v", err, ErrDeclareMismatch)
+	}
+	if err := b.DeclareExchange("ex", ExchangeFanout); !errors.Is(err, ErrDeclareMismatch) {
+		t.Fatalf("redeclare exchange: %v, want %v", err, ErrDeclareMismatch)
+	}
+}
+
+func TestBrokerAckNack(t *testing.T) {
+	b := newTestQueue(t, "orders.*", 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := b.Publish("ex", "orders.new", Message{Body: []byte("order")})
+	must(t, err)
+
+	deliveries, err := b.Channel().Consume(ctx, "q")
+	must(t, err)
+
+	// Requeued message is delivered again, marked as redelivered.
+	d := receive(t, deliveries)
+	if d.Redelivered {
+		t.Fatal("first delivery is marked redelivered")
+	}
+	if n := b.Unacked("q"); n != 1 {
+		t.Fatalf("unacked = %d, want 1", n)
+	}
+	must(t, d.Nack(true))
+
+	d = receive(t, deliveries)
+	if !d.Redelivered {
+		t.Fatal("requeued delivery is not marked redelivered")
+	}
+
+	// Rejected message goes to the DLQ through the dead letter exchange.
+	must(t, d.Nack(false))
+	if err := d.Ack(); !errors.Is(err, ErrAlreadyAcked) {
+		t.Fatalf("second settle: %v, want %v", err, ErrAlreadyAcked)
+	}
+
+	if n := b.Unacked("q"); n != 0 {
+		t.Fatalf("unacked = %d, want 0", n)
+	}
+	if n := len(b.Ready("q")); n != 0 {
+		t.Fatalf("ready = %d, want 0", n)
+	}
+
+	dead := b.Ready("dlq")
+	if len(dead) != 1 {
+		t.Fatalf("dlq has %d messages, want 1", len(dead))
+	}
+	if string(dead[0].Body) != "order" || dead[0].Deaths != 1 || dead[0].Redelivered {
+		t.Fatalf("dead message = %+v", dead[0])
+	}
+}
+
+func TestBrokerPrefetch(t *testing.T) {
+	b := newTestQueue(t, "orders.*", 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for range 3 {
+		_, err := b.Publish("ex", "orders.new", Message{Body: []byte("order")})
+		must(t, err)
+	}
+
+	ch := b.Channel()
+	must(t, ch.Qos(1))
+	deliveries, err := ch.Consume(ctx, "q")
+	must(t, err)
+
+	first := receive(t, deliveries)
+	select {
+	case <-deliveries:
+		t.Fatal("delivered over prefetch")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Acknowledging frees the slot, raising prefetch lets the consumer take the rest.
+	must(t, first.Ack())
+	second := receive(t, deliveries)
+
+	must(t, ch.Qos(2))
+	third := receive(t, deliveries)
+
+	if n := b.Unacked("q"); n != 2 {
+		t.Fatalf("unacked = %d, want 2", n)
+	}
+	must(t, second.Ack())
+	must(t, third.Ack())
+}
+
+func TestBrokerCancelConsumer(t *testing.T) {
+	b := newTestQueue(t, "orders.*", 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	deliveries, err := b.Channel().Consume(ctx, "q")
+	must(t, err)
+
+	_, err = b.Publish("ex", "orders.new", Message{Body: []byte("order")})
+	must(t, err)
+
+	// Let the consumer take the message without receiving it, then stop consuming.
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	for d := range deliveries {
+		must(t, d.Nack(true))
+	}
+
+	ready := b.Ready("q")
+	if len(ready) != 1 || b.Unacked("q") != 0 {
+		t.Fatalf("ready = %d, unacked = %d after cancel, want 1 and 0", len(ready), b.Unacked("q"))
+	}
+}
+
+func TestBrokerFanout(t *testing.T) {
+	b := NewBroker()
+	must(t, b.DeclareExchange("notifications", ExchangeFanout))
+
+	var queues []string
+	for range 2 {
+		q, err := b.DeclareQueue("", QueueOptions{})
+		must(t, err)
+		must(t, b.Bind(q, "", "notifications"))
+		queues = append(queues, q)
+	}
+	if queues[0] == queues[1] {
+		t.Fatalf("anonymous queues have the same name %s", queues[0])
+	}
+
+	n, err := b.Publish("notifications", "ignored", Message{Type: messageStatusUpdate, Body: []byte("{}")})
+	must(t, err)
+	if n != 2 {
+		t.Fatalf("routed to %d queues, want 2", n)
+	}
+
+	// Deleted queue no longer gets messages and its consumers stop.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	deliveries, err := b.Channel().Consume(ctx, queues[0])
+	must(t, err)
+	receive(t, deliveries)
+
+	must(t, b.DeleteQueue(queues[0]))
+	select {
+	case _, ok := <-deliveries:
+		if ok {
+			t.Fatal("delivery from deleted queue")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("consumer of deleted queue is not stopped")
+	}
+
+	n, err = b.Publish("notifications", "", Message{})
+	must(t, err)
+	if n != 1 {
+		t.Fatalf("routed to %d queues after delete, want 1", n)
+	}
+}
@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Estimator implements ETA estimators of the order and tracking services: every order is ready
+// a fixed time after it is queued.
+type Estimator struct {
+	cookingTime time.Duration
+}
+
+func NewEstimator(cookingTime time.Duration) *Estimator {
+	return &Estimator{cookingTime: cookingTime}
+}
+
+// Estimate predicts ready time of the order queued at queuedAt.
+func (e *Estimator) Estimate(_ context.Context, _, _ string, queuedAt time.Time) (models.ETA, error) {
+	return models.ETA{ReadyAt: queuedAt.Add(e.cookingTime)}, nil
+}
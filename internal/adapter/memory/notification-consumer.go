@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// NotificationSubscriber listens to the fanout exchange with its own queue like rabbit.NotificationSubscriber.
+// The channel of notifications is closed by Close.
+type NotificationSubscriber struct {
+	broker       *Broker
+	exchangeName string
+
+	mu        sync.Mutex
+	queueName string
+	stop      func()
+
+	log logger.Logger
+}
+
+func NewNotificationSubscriber(broker *Broker, exchange string, log logger.Logger) *NotificationSubscriber {
+	return &NotificationSubscriber{
+		broker:       broker,
+		exchangeName: exchange,
+		stop:         func() {},
+		log:          log,
+	}
+}
+
+func (s *NotificationSubscriber) StartListening(ctx context.Context) (chan models.Notification, error) {
+	if err := s.broker.DeclareExchange(s.exchangeName, ExchangeFanout); err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	queueName, err := s.broker.DeclareQueue("", QueueOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	if err := s.broker.Bind(queueName, "", s.exchangeName); err != nil {
+		return nil, fmt.Errorf("failed to bind queue: %w", err)
+	}
+
+	// Like the RabbitMQ subscriber, listening stops on Close only.
+	consumeCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	msgs, err := s.broker.Channel().Consume(consumeCtx, queueName)
+	if err != nil {
+		stop()
+		return nil, fmt.Errorf("failed to consume messages: %w", err)
+	}
+
+	s.mu.Lock()
+	s.queueName = queueName
+	s.stop = stop
+	s.mu.Unlock()
+
+	updateCh := make(chan models.Notification, 1)
+	go s.startConsuming(consumeCtx, msgs, updateCh)
+
+	return updateCh, nil
+}
+
+func (s *NotificationSubscriber) startConsuming(ctx context.Context, msgs <-chan *Delivery, outCh chan models.Notification) {
+	defer close(outCh)
+
+	for msg := range msgs {
+		notification, err := decodeNotification(msg.Type, msg.Body)
+		if err != nil {
+			s.log.Error(ctx, "notification_decode", "Failed to decode notification", err)
+			msg.Nack(false)
+			continue
+		}
+		msg.Ack()
+
+		select {
+		case outCh <- notification:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Close stops listening and deletes the queue of the subscriber.
+func (s *NotificationSubscriber) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stop()
+	if s.queueName == "" {
+		return nil
+	}
+
+	err := s.broker.DeleteQueue(s.queueName)
+	s.queueName = ""
+
+	return err
+}
+
+func decodeNotification(messageType string, body []byte) (models.Notification, error) {
+	switch messageType {
+	case messageLowStock:
+		var alert models.LowStockAlert
+		if err := json.Unmarshal(body, &alert); err != nil {
+			return models.Notification{}, err
+		}
+		return models.Notification{LowStock: &alert}, nil
+	default:
+		var update models.StatusUpdate
+		if err := json.Unmarshal(body, &update); err != nil {
+			return models.Notification{}, err
+		}
+		return models.Notification{StatusUpdate: &update}, nil
+	}
+}
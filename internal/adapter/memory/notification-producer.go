@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Types of messages of the notifications exchange, the same as of the RabbitMQ one.
+const (
+	messageStatusUpdate = "status_update"
+	messageLowStock     = "low_stock"
+)
+
+// NotificationProducer publishes notifications to the fanout exchange like rabbit.NotificationProducer.
+type NotificationProducer struct {
+	broker       *Broker
+	exchangeName string
+}
+
+func NewNotificationProducer(broker *Broker, exchange string) (*NotificationProducer, error) {
+	if err := broker.DeclareExchange(exchange, ExchangeFanout); err != nil {
+		return nil, fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+	}
+
+	return &NotificationProducer{
+		broker:       broker,
+		exchangeName: exchange,
+	}, nil
+}
+
+// StatusUpdate publishes event about status change.
+func (p *NotificationProducer) StatusUpdate(ctx context.Context, req *models.StatusUpdate) error {
+	return p.publish(ctx, messageStatusUpdate, req)
+}
+
+// LowStock publishes event about low stock of an ingredient.
+func (p *NotificationProducer) LowStock(ctx context.Context, alert *models.LowStockAlert) error {
+	return p.publish(ctx, messageLowStock, alert)
+}
+
+func (p *NotificationProducer) publish(ctx context.Context, messageType string, event any) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to publish %s: %w", messageType, err)
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", messageType, err)
+	}
+
+	if _, err := p.broker.Publish(p.exchangeName, "", Message{Type: messageType, Body: body}); err != nil {
+		return fmt.Errorf("failed to publish %s: %w", messageType, err)
+	}
+
+	return nil
+}
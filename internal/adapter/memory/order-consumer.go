@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/rabbit"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/kitchen"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// OrderConsumer consumes kitchen queues of the broker like rabbit.OrderConsumer:
+// stale orders are acknowledged, recoverable failures are requeued and other failures go to the DLQ.
+type OrderConsumer struct {
+	channel  *Channel
+	location string
+
+	log logger.Logger
+}
+
+func NewOrderConsumer(broker *Broker, exchange, location string, prefetchCount int, orderTypes, stations []string, log logger.Logger) (*OrderConsumer, error) {
+	if len(orderTypes) == 0 && len(stations) == 0 {
+		return nil, errors.New("neither orderTypes nor stations provided")
+	}
+
+	if err := broker.DeclareExchange(exchange, ExchangeTopic); err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	if err := InitQueuesForOrderTypes(broker, exchange, location, orderTypes); err != nil {
+		return nil, err
+	}
+
+	if err := InitQueuesForStations(broker, exchange, location, stations); err != nil {
+		return nil, err
+	}
+
+	channel := broker.Channel()
+	if err := channel.Qos(prefetchCount); err != nil {
+		return nil, fmt.Errorf("failed to set QoS: %w", err)
+	}
+
+	return &OrderConsumer{
+		channel:  channel,
+		location: location,
+		log:      log,
+	}, nil
+}
+
+// Consume consumes created order messages.
+func (c *OrderConsumer) Consume(ctx context.Context, orderType string, handler func(ctx context.Context, req *models.CreateOrder) error) error {
+	return c.consume(ctx, QueueByOrderType(c.location, orderType), handler)
+}
+
+// ConsumeStation consumes station parts of created orders.
+func (c *OrderConsumer) ConsumeStation(ctx context.Context, station string, handler func(ctx context.Context, req *models.CreateOrder) error) error {
+	return c.consume(ctx, QueueByStation(c.location, station), handler)
+}
+
+func (c *OrderConsumer) consume(ctx context.Context, queueName string, handler func(ctx context.Context, req *models.CreateOrder) error) error {
+	// Deliveries stop when ctx is cancelled, the one not handled yet is returned to the queue by the broker.
+	msgs, err := c.channel.Consume(ctx, queueName)
+	if err != nil {
+		c.log.Error(ctx, types.ActionMessageProcessingFailed, "failed to consume queue", err, "queue", queueName)
+		return fmt.Errorf("failed to start consuming: %w", err)
+	}
+
+	for msg := range msgs {
+		req, err := rabbit.ToInternalOrder(msg.Body)
+		if err != nil {
+			msg.Nack(false)
+			c.log.Error(ctx, types.ActionValidationFailed, "failed to validate message", err)
+			continue
+		}
+
+		msgCtx := ctx
+		if len(req.RequestID) != 0 {
+			msgCtx = logger.WithRequestID(ctx, req.RequestID)
+		}
+
+		if err := handler(msgCtx, rabbit.FromPublishToInternalOrder(req)); err != nil {
+			// Stale copy of a republished order, the current one is still in the queue.
+			if errors.Is(err, kitchen.ErrStaleOrder) {
+				msg.Ack()
+				continue
+			}
+
+			msg.Nack(isRecoverableError(err))
+			c.log.Error(msgCtx, types.ActionMessageProcessingFailed, "failed to handle message", err, "requeue", isRecoverableError(err))
+			continue
+		}
+		msg.Ack()
+	}
+
+	return nil
+}
+
+// SetPrefetch changes number of unacknowledged orders each queue of the worker can hold.
+func (c *OrderConsumer) SetPrefetch(prefetch int) error {
+	if err := c.channel.Qos(prefetch); err != nil {
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+	return nil
+}
+
+// isRecoverableError returns true if the provided error must be requeued
+func isRecoverableError(err error) bool {
+	return errors.Is(err, kitchen.ErrNilOrder) || errors.Is(err, kitchen.ErrWorkerStopping)
+}
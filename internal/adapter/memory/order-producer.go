@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/rabbit"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// OrderProducer publishes orders to the broker like rabbit.OrderProducer, with the same message format.
+type OrderProducer struct {
+	broker *Broker
+
+	exchangeOrder string
+
+	mu        sync.Mutex
+	locations map[string]struct{} // locations whose queues are already declared
+	stations  map[string]struct{} // <location>/<station> station queues already declared
+}
+
+func NewOrderProducer(broker *Broker, exchange, location string) (*OrderProducer, error) {
+	if err := broker.DeclareExchange(exchange, ExchangeTopic); err != nil {
+		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+	}
+
+	p := &OrderProducer{
+		broker:        broker,
+		exchangeOrder: exchange,
+		locations:     make(map[string]struct{}),
+		stations:      make(map[string]struct{}),
+	}
+
+	if err := p.initLocation(location); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// initLocation declares kitchen queues of the location once.
+func (r *OrderProducer) initLocation(location string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.locations[location]; ok {
+		return nil
+	}
+
+	if err := InitQueuesForOrderTypes(r.broker, r.exchangeOrder, location, types.AllOrderTypes); err != nil {
+		return fmt.Errorf("failed to init order queues: %w", err)
+	}
+	r.locations[location] = struct{}{}
+
+	return nil
+}
+
+// initStation declares station queue of the location once.
+func (r *OrderProducer) initStation(location, station string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := location + "/" + station
+	if _, ok := r.stations[key]; ok {
+		return nil
+	}
+
+	if err := InitQueuesForStations(r.broker, r.exchangeOrder, location, []string{station}); err != nil {
+		return fmt.Errorf("failed to init station queue: %w", err)
+	}
+	r.stations[key] = struct{}{}
+
+	return nil
+}
+
+// PublishCreateOrder publishes an order message to the orders exchange. Orders with kitchen stations
+// are published as one message per station with items of the station only.
+func (r *OrderProducer) PublishCreateOrder(ctx context.Context, order *models.CreateOrder) error {
+	if order == nil {
+		return errors.New("nil order")
+	}
+
+	if err := r.initLocation(order.LocationID); err != nil {
+		return err
+	}
+
+	stations, items := order.Stations()
+	if len(stations) == 0 {
+		return r.publish(ctx, order, createOrderPublishedKey(order))
+	}
+
+	for _, station := range stations {
+		if err := r.initStation(order.LocationID, station); err != nil {
+			return err
+		}
+
+		part := *order
+		part.Station = station
+		part.Items = items[station]
+
+		if err := r.publish(ctx, &part, createStationPublishedKey(&part)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *OrderProducer) publish(ctx context.Context, order *models.CreateOrder, routingKey string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to publish order: %w", err)
+	}
+
+	body, err := json.Marshal(rabbit.FromInternalToPublishOrder(ctx, order))
+	if err != nil {
+		return fmt.Errorf("failed to marshal order: %w", err)
+	}
+
+	if _, err := r.broker.Publish(r.exchangeOrder, routingKey, Message{
+		Priority: order.Priority,
+		Body:     body,
+	}); err != nil {
+		return fmt.Errorf("failed to publish order: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,482 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// OrderRepo implements order repositories of the order, kitchen and tracking services.
+// Tables, payments, inventory, customers and promotions are not stored: orders are never attached
+// to a table, have no payment, reserve no stock and earn or redeem no loyalty points.
+type OrderRepo struct {
+	store *Store
+}
+
+func NewOrderRepo(store *Store) *OrderRepo {
+	return &OrderRepo{store: store}
+}
+
+func (r *OrderRepo) Create(ctx context.Context, req *models.CreateOrder, changedBy, notes string) (*models.Order, error) {
+	if err := checkCtx(ctx, "orderRepository.Create"); err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(req.LocationID, req.Number)
+	if _, ok := s.orders[k]; ok {
+		return nil, fmt.Errorf("failed to create order: duplicate order number %s", req.Number)
+	}
+
+	now := time.Now()
+	s.orderID++
+	rec := &orderRecord{
+		order: models.Order{
+			ID:              s.orderID,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+			LocationID:      req.LocationID,
+			Number:          req.Number,
+			CustomerName:    req.CustomerName,
+			CustomerID:      req.CustomerID,
+			Type:            req.Type,
+			TableNumber:     req.TableNumber,
+			DeliveryAddress: req.DeliveryAddress,
+			DeliveryFee:     req.DeliveryFee,
+			Subtotal:        req.Subtotal,
+			TaxAmount:       req.TaxAmount,
+			ServiceCharge:   req.ServiceCharge,
+			Tip:             req.Tip,
+			TotalAmount:     req.TotalAmount,
+			PointsEarned:    req.PointsEarned,
+			PointsRedeemed:  req.RedeemPoints,
+			Priority:        req.Priority,
+			Status:          req.Status,
+			Version:         1,
+			ScheduledFor:    req.ScheduledFor,
+			Discounts:       slices.Clone(req.Discounts),
+		},
+		travelTime:   req.TravelTime,
+		deliveryZone: req.DeliveryZone,
+		promoCode:    req.PromoCode,
+	}
+	if req.DeliveryZone != "" {
+		zone := req.DeliveryZone
+		rec.order.DeliveryZone = &zone
+	}
+	if req.Status != types.StatusOrderScheduled && req.Status != types.StatusOrderPendingPayment {
+		rec.queuedAt = &now
+	}
+	rec.order.Items = s.newItems(rec.order.ID, now, req.Items)
+
+	s.orders[k] = rec
+	s.logStatus(rec, now, req.Status, changedBy, notes)
+
+	order := cloneOrder(rec.order)
+	return &order, nil
+}
+
+// newItems returns items of the order, must be called with the mutex held.
+func (s *Store) newItems(orderID int, now time.Time, items []models.CreateOrderItem) []models.OrderItem {
+	orderItems := make([]models.OrderItem, 0, len(items))
+	for _, item := range items {
+		s.itemID++
+		orderItem := models.OrderItem{
+			ID:        s.itemID,
+			CreatedAt: now,
+			OrderID:   orderID,
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+			Category:  item.Category,
+			TaxRate:   item.TaxRate,
+			Status:    types.StatusOrderReceived,
+		}
+		if item.Station != "" {
+			station := item.Station
+			orderItem.Station = &station
+		}
+		orderItems = append(orderItems, orderItem)
+	}
+	return orderItems
+}
+
+// GetAndIncrementSequence returns next order sequence of the location for the date.
+func (r *OrderRepo) GetAndIncrementSequence(ctx context.Context, locationID, date string) (int, error) {
+	return r.ReserveSequences(ctx, locationID, date, 1)
+}
+
+// ReserveSequences reserves a block of n order sequences of the location for the date and returns the first of them.
+func (r *OrderRepo) ReserveSequences(ctx context.Context, locationID, date string, n int) (int, error) {
+	if err := checkCtx(ctx, "orderRepository.ReserveSequences"); err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	k := key(locationID, date)
+	r.store.sequences[k] += n
+
+	return r.store.sequences[k] - n + 1, nil
+}
+
+// CancelScheduled cancels the scheduled or waiting for payment order of the location.
+func (r *OrderRepo) CancelScheduled(ctx context.Context, locationID, orderNumber, changedBy, notes string) error {
+	const op = "orderRepository.CancelScheduled"
+	if err := checkCtx(ctx, op); err != nil {
+		return err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.orders[key(locationID, orderNumber)]
+	if !ok {
+		return models.ErrOrderNotFound
+	}
+
+	if rec.order.Status != types.StatusOrderScheduled && rec.order.Status != types.StatusOrderPendingPayment {
+		return models.ErrOrderNotCancellable
+	}
+
+	now := time.Now()
+	rec.order.Status = types.StatusOrderCancelled
+	rec.order.UpdatedAt = now
+	s.logStatus(rec, now, types.StatusOrderCancelled, changedBy, notes)
+
+	return nil
+}
+
+// Modify changes the received order of the location. The order is changed only if publish succeeds.
+func (r *OrderRepo) Modify(ctx context.Context, locationID, orderNumber, changedBy string, modify func(order *models.CreateOrder) (string, error), publish func(order *models.CreateOrder) error) (*models.Order, error) {
+	const op = "orderRepository.Modify"
+	if err := checkCtx(ctx, op); err != nil {
+		return nil, err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.orders[key(locationID, orderNumber)]
+	if !ok {
+		return nil, models.ErrOrderNotFound
+	}
+
+	if rec.order.Status != types.StatusOrderReceived {
+		return nil, models.ErrOrderNotModifiable
+	}
+
+	req := rec.modifiable()
+	notes, err := modify(req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	order := cloneOrder(rec.order)
+	order.Items = s.newItems(order.ID, now, req.Items)
+	order.Discounts = slices.Clone(req.Discounts)
+	order.Subtotal = req.Subtotal
+	order.TotalAmount = req.TotalAmount
+	order.TaxAmount = req.TaxAmount
+	order.ServiceCharge = req.ServiceCharge
+	order.DeliveryFee = req.DeliveryFee
+	order.Priority = req.Priority
+	order.PointsEarned = req.PointsEarned
+	order.PointsRedeemed = req.RedeemPoints
+	order.Version++
+	order.UpdatedAt = now
+
+	req.Version = order.Version
+	if err := publish(req); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rec.order = order
+	s.logStatus(rec, now, types.StatusOrderReceived, changedBy, fmt.Sprintf("modified to version %d: %s", order.Version, notes))
+
+	order = cloneOrder(rec.order)
+	return &order, nil
+}
+
+// modifiable returns the order as it was requested.
+func (rec *orderRecord) modifiable() *models.CreateOrder {
+	o := rec.order
+	req := &models.CreateOrder{
+		LocationID:      o.LocationID,
+		Number:          o.Number,
+		CustomerName:    o.CustomerName,
+		CustomerID:      o.CustomerID,
+		Type:            o.Type,
+		TableNumber:     o.TableNumber,
+		DeliveryAddress: o.DeliveryAddress,
+		DeliveryZone:    rec.deliveryZone,
+		DeliveryFee:     o.DeliveryFee,
+		TravelTime:      rec.travelTime,
+		PromoCode:       rec.promoCode,
+		RedeemPoints:    o.PointsRedeemed,
+		Tip:             o.Tip,
+		Priority:        o.Priority,
+		Status:          o.Status,
+		Version:         o.Version,
+	}
+
+	for _, item := range o.Items {
+		createItem := models.CreateOrderItem{
+			Name:     item.Name,
+			Quantity: item.Quantity,
+			Price:    item.Price,
+			Category: item.Category,
+			TaxRate:  item.TaxRate,
+		}
+		if item.Station != nil {
+			createItem.Station = *item.Station
+		}
+		req.Items = append(req.Items, createItem)
+	}
+
+	return req
+}
+
+// SetStatus updates order status of the location and logs it.
+func (r *OrderRepo) SetStatus(ctx context.Context, locationID, orderNumber, workerName, status, notes string) (string, error) {
+	return r.setStatus(ctx, locationID, orderNumber, workerName, status, notes, 0, 0, nil)
+}
+
+// StartCooking sets 'cooking' status if the order still has the given priority and version and is in the kitchen.
+func (r *OrderRepo) StartCooking(ctx context.Context, locationID, orderNumber, workerName string, priority, version int) (string, error) {
+	return r.setStatus(ctx, locationID, orderNumber, workerName, types.StatusOrderCooking, "", priority, version,
+		[]string{types.StatusOrderReceived, types.StatusOrderCooking})
+}
+
+// setStatus updates status and logs it. If priority or version is not 0, the order is updated only if it has them;
+// if from is not empty, only if it has one of these statuses.
+func (r *OrderRepo) setStatus(ctx context.Context, locationID, orderNumber, workerName, status, notes string, priority, version int, from []string) (string, error) {
+	const op = "orderRepository.SetStatus"
+	if err := checkCtx(ctx, op); err != nil {
+		return "", err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.orders[key(locationID, orderNumber)]
+	if !ok {
+		return "", models.ErrOrderNotFound
+	}
+
+	if (priority != 0 && rec.order.Priority != priority) || (version != 0 && rec.order.Version != version) {
+		return "", models.ErrOrderSuperseded
+	}
+	if len(from) > 0 && !slices.Contains(from, rec.order.Status) {
+		return "", models.ErrOrderSuperseded
+	}
+
+	now := time.Now()
+	oldStatus := rec.order.Status
+	rec.order.Status = status
+	rec.order.ProcessedBy = &workerName
+	rec.order.UpdatedAt = now
+	if status == types.StatusOrderReady {
+		rec.order.CompletedAt = &now
+	}
+	s.logStatus(rec, now, status, workerName, notes)
+
+	// Items of orders cooked whole follow the order status
+	if status == types.StatusOrderCooking || status == types.StatusOrderReady {
+		for i := range rec.order.Items {
+			if rec.order.Items[i].Station == nil {
+				rec.order.Items[i].Status = status
+			}
+		}
+	}
+
+	return oldStatus, nil
+}
+
+// StartStation sets 'cooking' status of the station items of the order. The first started station moves
+// the order to 'cooking' too. Old status of the order is returned.
+func (r *OrderRepo) StartStation(ctx context.Context, locationID, orderNumber, station, workerName string, priority, version int) (string, error) {
+	const op = "orderRepository.StartStation"
+	if err := checkCtx(ctx, op); err != nil {
+		return "", err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.orders[key(locationID, orderNumber)]
+	if !ok {
+		return "", models.ErrOrderNotFound
+	}
+
+	// Old copy of an order republished by aging or modification
+	if rec.order.Priority != priority || (version != 0 && rec.order.Version != version) {
+		return "", models.ErrOrderSuperseded
+	}
+	// Message of a release or payment which did not commit, the order is not in the kitchen
+	if rec.order.Status != types.StatusOrderReceived && rec.order.Status != types.StatusOrderCooking {
+		return "", models.ErrOrderSuperseded
+	}
+
+	started := 0
+	for i, item := range rec.order.Items {
+		if item.Station != nil && *item.Station == station && item.Status != types.StatusOrderReady {
+			rec.order.Items[i].Status = types.StatusOrderCooking
+			started++
+		}
+	}
+	if started == 0 {
+		return "", models.ErrStationDone
+	}
+
+	now := time.Now()
+	s.logStation(rec, now, station, types.StatusOrderCooking, workerName)
+
+	status := rec.order.Status
+	if status == types.StatusOrderReceived {
+		rec.order.Status = types.StatusOrderCooking
+		rec.order.ProcessedBy = &workerName
+		rec.order.UpdatedAt = now
+		s.logStatus(rec, now, types.StatusOrderCooking, workerName, "started at "+station)
+	}
+
+	return status, nil
+}
+
+// FinishStation sets 'ready' status of the station items of the order. When every item is ready,
+// the order becomes 'ready' and its old status is returned, otherwise the empty status is returned.
+func (r *OrderRepo) FinishStation(ctx context.Context, locationID, orderNumber, station, workerName string) (string, error) {
+	const op = "orderRepository.FinishStation"
+	if err := checkCtx(ctx, op); err != nil {
+		return "", err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.orders[key(locationID, orderNumber)]
+	if !ok {
+		return "", models.ErrOrderNotFound
+	}
+
+	pending := 0
+	for i, item := range rec.order.Items {
+		if item.Station != nil && *item.Station == station {
+			rec.order.Items[i].Status = types.StatusOrderReady
+		}
+		if rec.order.Items[i].Status != types.StatusOrderReady {
+			pending++
+		}
+	}
+
+	now := time.Now()
+	s.logStation(rec, now, station, types.StatusOrderReady, workerName)
+
+	status := rec.order.Status
+	if pending > 0 || status == types.StatusOrderReady {
+		return "", nil
+	}
+
+	rec.order.Status = types.StatusOrderReady
+	rec.order.CompletedAt = &now
+	rec.order.UpdatedAt = now
+	s.logStatus(rec, now, types.StatusOrderReady, workerName, "all stations ready")
+
+	return status, nil
+}
+
+// GetByNumber returns order of the location with its items.
+func (r *OrderRepo) GetByNumber(ctx context.Context, locationID, orderNumber string) (models.Order, error) {
+	if err := checkCtx(ctx, "orderRepository.GetByNumber"); err != nil {
+		return models.Order{}, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.orders[key(locationID, orderNumber)]
+	if !ok {
+		return models.Order{}, models.ErrOrderNotFound
+	}
+
+	return cloneOrder(rec.order), nil
+}
+
+// List returns orders matching the filter, ordered by (created_at, id).
+// It returns at most filter.Limit orders, starting after filter.After.
+func (r *OrderRepo) List(ctx context.Context, filter models.OrderFilter) ([]models.Order, error) {
+	if err := checkCtx(ctx, "orderRepository.List"); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	orders := []models.Order{}
+	for _, rec := range r.store.orders {
+		if matchOrder(rec.order, filter) {
+			orders = append(orders, rec.order)
+		}
+	}
+
+	slices.SortFunc(orders, func(a, b models.Order) int {
+		c := cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+		if !filter.Asc {
+			c = -c
+		}
+		return c
+	})
+
+	if filter.After != nil {
+		orders = slices.DeleteFunc(orders, func(o models.Order) bool {
+			c := cmp.Or(o.CreatedAt.Compare(filter.After.CreatedAt), cmp.Compare(o.ID, filter.After.ID))
+			if filter.Asc {
+				return c <= 0
+			}
+			return c >= 0
+		})
+	}
+
+	if len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+	}
+
+	for i := range orders {
+		orders[i] = cloneOrder(orders[i])
+		orders[i].Items = nil // list queries return orders without items
+	}
+
+	return orders, nil
+}
+
+func matchOrder(o models.Order, f models.OrderFilter) bool {
+	switch {
+	case f.LocationID != "" && o.LocationID != f.LocationID,
+		f.CustomerID != nil && (o.CustomerID == nil || *o.CustomerID != *f.CustomerID),
+		f.Status != "" && o.Status != f.Status,
+		f.Type != "" && o.Type != f.Type,
+		f.From != nil && o.CreatedAt.Before(*f.From),
+		f.To != nil && !o.CreatedAt.Before(*f.To),
+		f.CustomerPrefix != "" && !strings.HasPrefix(o.CustomerName, f.CustomerPrefix),
+		f.Worker != "" && (o.ProcessedBy == nil || *o.ProcessedBy != f.Worker),
+		f.Priority != nil && o.Priority != *f.Priority:
+		return false
+	}
+	return true
+}
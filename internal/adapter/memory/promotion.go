@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// PromotionRepo implements promotion repositories of the order and promotion services.
+type PromotionRepo struct {
+	store *Store
+}
+
+func NewPromotionRepo(store *Store) *PromotionRepo {
+	return &PromotionRepo{store: store}
+}
+
+// Create stores a new promotion. Returns models.ErrPromotionExists if the code is taken.
+func (r *PromotionRepo) Create(ctx context.Context, p models.Promotion) (models.Promotion, error) {
+	if err := checkCtx(ctx, "promotionRepository.Create"); err != nil {
+		return models.Promotion{}, err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p.Code = strings.ToUpper(p.Code)
+	if _, ok := s.promotions[p.Code]; ok {
+		return models.Promotion{}, models.ErrPromotionExists
+	}
+
+	s.promotionID++
+	p.ID = s.promotionID
+	p.Uses = 0
+	p.CreatedAt = time.Now()
+	s.promotions[p.Code] = p
+
+	return p, nil
+}
+
+// GetByCode returns promotion by upper case code.
+func (r *PromotionRepo) GetByCode(ctx context.Context, code string) (models.Promotion, error) {
+	if err := checkCtx(ctx, "promotionRepository.GetByCode"); err != nil {
+		return models.Promotion{}, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	p, ok := r.store.promotions[code]
+	if !ok {
+		return models.Promotion{}, models.ErrPromotionNotFound
+	}
+	return p, nil
+}
+
+// List returns all promotions, newest first.
+func (r *PromotionRepo) List(ctx context.Context) ([]models.Promotion, error) {
+	if err := checkCtx(ctx, "promotionRepository.List"); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	promotions := make([]models.Promotion, 0, len(r.store.promotions))
+	for _, p := range r.store.promotions {
+		promotions = append(promotions, p)
+	}
+	slices.SortFunc(promotions, func(a, b models.Promotion) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	return promotions, nil
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// Queues are named and bound like the RabbitMQ ones, so tests see the same topology.
+
+const dlxExchange = "dlx_exchange"
+
+// InitQueuesForOrderTypes creates queues of the location for each type of order with their DLQs.
+func InitQueuesForOrderTypes(broker *Broker, exchange, location string, orderTypes []string) error {
+	if err := broker.DeclareExchange(dlxExchange, ExchangeTopic); err != nil {
+		return fmt.Errorf("failed to declare DLX exchange: %w", err)
+	}
+
+	for _, ot := range orderTypes {
+		if err := declareKitchenQueue(broker, exchange, QueueByOrderType(location, ot), fmt.Sprintf("kitchen.%s.%s.*", location, ot)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InitQueuesForStations creates queues of the location for each kitchen station with their DLQs.
+func InitQueuesForStations(broker *Broker, exchange, location string, stations []string) error {
+	if err := broker.DeclareExchange(dlxExchange, ExchangeTopic); err != nil {
+		return fmt.Errorf("failed to declare DLX exchange: %w", err)
+	}
+
+	for _, station := range stations {
+		if err := declareKitchenQueue(broker, exchange, QueueByStation(location, station), fmt.Sprintf("kitchen.%s.station.%s.*", location, station)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// declareKitchenQueue creates priority queue bound to the exchange and its DLQ.
+func declareKitchenQueue(broker *Broker, exchange, queueName, bindingKey string) error {
+	deadLQueue := DLQByQueue(queueName)
+
+	if _, err := broker.DeclareQueue(queueName, QueueOptions{
+		DeadLetterExchange:   dlxExchange,
+		DeadLetterRoutingKey: deadLQueue,
+		MaxPriority:          types.MaxOrderPriority,
+	}); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", queueName, err)
+	}
+
+	if err := broker.Bind(queueName, bindingKey, exchange); err != nil {
+		return fmt.Errorf("failed to bind queue %s: %w", queueName, err)
+	}
+
+	if _, err := broker.DeclareQueue(deadLQueue, QueueOptions{}); err != nil {
+		return fmt.Errorf("failed to declare DLQ: %w", err)
+	}
+
+	if err := broker.Bind(deadLQueue, deadLQueue, dlxExchange); err != nil {
+		return fmt.Errorf("failed to bind DLQ: %w", err)
+	}
+
+	return nil
+}
+
+// QueueByOrderType returns kitchen queue of the location for the order type.
+func QueueByOrderType(location, ot string) string {
+	return fmt.Sprintf("kitchen_%s_%s_queue", location, ot)
+}
+
+// QueueByStation returns kitchen queue of the location for the station.
+func QueueByStation(location, station string) string {
+	return fmt.Sprintf("kitchen_%s_station_%s_queue", location, station)
+}
+
+// DLQByQueue returns dead letter queue of the kitchen queue.
+func DLQByQueue(queueName string) string {
+	return fmt.Sprintf("dlq.%s", queueName)
+}
+
+// Create the routing key of a station part of the order: kitchen.<location>.station.<station>.<priority>
+func createStationPublishedKey(order *models.CreateOrder) string {
+	return fmt.Sprintf("kitchen.%s.station.%s.%d", order.LocationID, order.Station, order.Priority)
+}
+
+// Create the routing key: kitchen.<location>.<type>.<priority>
+func createOrderPublishedKey(order *models.CreateOrder) string {
+	return fmt.Sprintf("kitchen.%s.%s.%d", order.LocationID, order.Type, order.Priority)
+}
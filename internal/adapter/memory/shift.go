@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// ShiftRepo implements shift repositories of the order, kitchen and tracking services.
+type ShiftRepo struct {
+	store *Store
+}
+
+func NewShiftRepo(store *Store) *ShiftRepo {
+	return &ShiftRepo{store: store}
+}
+
+// List returns shifts of all workers of the location.
+func (r *ShiftRepo) List(ctx context.Context, locationID string) ([]models.Shift, error) {
+	if err := checkCtx(ctx, "shiftRepository.List"); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var shifts []models.Shift
+	for k, workerShifts := range r.store.shifts {
+		if strings.HasPrefix(k, locationID+"/") {
+			shifts = append(shifts, workerShifts...)
+		}
+	}
+	slices.SortFunc(shifts, func(a, b models.Shift) int {
+		return cmp.Or(cmp.Compare(a.WorkerName, b.WorkerName), cmp.Compare(a.Weekday, b.Weekday), cmp.Compare(a.Start, b.Start))
+	})
+
+	return cloneShifts(shifts), nil
+}
+
+// ListByWorker returns shifts of the worker of the location.
+func (r *ShiftRepo) ListByWorker(ctx context.Context, locationID, workerName string) ([]models.Shift, error) {
+	if err := checkCtx(ctx, "shiftRepository.ListByWorker"); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	return cloneShifts(r.store.shifts[key(locationID, workerName)]), nil
+}
+
+// Replace replaces all shifts of the worker of the location. Empty shifts remove the schedule.
+func (r *ShiftRepo) Replace(ctx context.Context, locationID, workerName string, shifts []models.Shift) error {
+	if err := checkCtx(ctx, "shiftRepository.Replace"); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if len(shifts) == 0 {
+		delete(r.store.shifts, key(locationID, workerName))
+		return nil
+	}
+
+	stored := cloneShifts(shifts)
+	for i := range stored {
+		stored[i].WorkerName = workerName
+	}
+	slices.SortFunc(stored, func(a, b models.Shift) int {
+		return cmp.Or(cmp.Compare(a.Weekday, b.Weekday), cmp.Compare(a.Start, b.Start))
+	})
+	r.store.shifts[key(locationID, workerName)] = stored
+
+	return nil
+}
+
+func cloneShifts(shifts []models.Shift) []models.Shift {
+	cloned := slices.Clone(shifts)
+	for i := range cloned {
+		cloned[i].OrderTypes = slices.Clone(cloned[i].OrderTypes)
+	}
+	return cloned
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// StatusRepo implements status repository of the tracking service.
+type StatusRepo struct {
+	store *Store
+}
+
+func NewStatusRepo(store *Store) *StatusRepo {
+	return &StatusRepo{store: store}
+}
+
+func (r *StatusRepo) GetCurrent(ctx context.Context, locationID, orderNumber string) (models.OrderStatus, error) {
+	if err := checkCtx(ctx, "statusRepository.GetCurrent"); err != nil {
+		return models.OrderStatus{}, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.orders[key(locationID, orderNumber)]
+	if !ok {
+		return models.OrderStatus{}, models.ErrOrderNotFound
+	}
+
+	status := models.OrderStatus{
+		LocationID:   rec.order.LocationID,
+		OrderNumber:  rec.order.Number,
+		Completion:   rec.order.CompletedAt,
+		ProcessedBy:  rec.order.ProcessedBy,
+		OrderType:    rec.order.Type,
+		QueuedAt:     rec.order.CreatedAt,
+		ScheduledFor: rec.order.ScheduledFor,
+		TravelTime:   rec.travelTime,
+	}
+	if rec.queuedAt != nil {
+		status.QueuedAt = *rec.queuedAt
+	}
+
+	// Latest entry of the order status log, station progress is not a status of the order.
+	for i := len(rec.history) - 1; i >= 0; i-- {
+		if rec.history[i].Station == "" {
+			status.Status = rec.history[i].Status
+			status.UpdatedAt = rec.history[i].Timestamp
+			break
+		}
+	}
+
+	return status, nil
+}
+
+// ListOrderHistory returns statuses of the order with progress of its kitchen stations.
+func (r *StatusRepo) ListOrderHistory(ctx context.Context, locationID, orderNumber string) ([]models.OrderHistory, error) {
+	if err := checkCtx(ctx, "statusRepository.ListStatusHistory"); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.orders[key(locationID, orderNumber)]
+	if !ok || len(rec.history) == 0 {
+		return nil, models.ErrOrderNotFound
+	}
+
+	// Ordered like the log tables: by time, statuses of the order before stations changed at the same time.
+	entries := slices.Clone(rec.history)
+	slices.SortStableFunc(entries, func(a, b historyEntry) int {
+		return cmp.Or(
+			a.Timestamp.Compare(b.Timestamp),
+			cmp.Compare(kind(a), kind(b)),
+			cmp.Compare(a.id, b.id),
+		)
+	})
+
+	history := make([]models.OrderHistory, 0, len(entries))
+	for _, e := range entries {
+		history = append(history, e.OrderHistory)
+	}
+
+	return history, nil
+}
+
+func kind(e historyEntry) int {
+	if e.Station == "" {
+		return 0
+	}
+	return 1
+}
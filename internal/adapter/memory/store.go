@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Store holds data of the in-memory repositories, like the database tables they replace.
+// Repositories created on the same store see each other's changes.
+type Store struct {
+	mu sync.Mutex
+
+	orders     map[string]*orderRecord // by <location>/<number>
+	sequences  map[string]int          // last order sequence by <location>/<date>
+	workers    map[string]*models.Worker
+	sessions   map[string][]*models.WorkerSession // by <location>/<worker>, oldest first
+	shifts     map[string][]models.Shift          // by <location>/<worker>
+	promotions map[string]models.Promotion        // by code
+
+	orderID     int
+	itemID      int
+	sessionID   int
+	logID       int
+	promotionID int
+}
+
+// orderRecord is an order with its status log.
+type orderRecord struct {
+	order        models.Order
+	queuedAt     *time.Time
+	travelTime   time.Duration
+	deliveryZone string
+	promoCode    string
+	history      []historyEntry
+}
+
+// historyEntry is a row of the order or station status log.
+type historyEntry struct {
+	models.OrderHistory
+	id int
+}
+
+func NewStore() *Store {
+	return &Store{
+		orders:     make(map[string]*orderRecord),
+		sequences:  make(map[string]int),
+		workers:    make(map[string]*models.Worker),
+		sessions:   make(map[string][]*models.WorkerSession),
+		shifts:     make(map[string][]models.Shift),
+		promotions: make(map[string]models.Promotion),
+	}
+}
+
+func key(location, name string) string {
+	return location + "/" + name
+}
+
+// logStatus adds a status log entry of the order, must be called with the mutex held.
+func (s *Store) logStatus(rec *orderRecord, at time.Time, status, changedBy, notes string) {
+	s.logID++
+	rec.history = append(rec.history, historyEntry{
+		OrderHistory: models.OrderHistory{
+			Status:    status,
+			Timestamp: at,
+			ChangedBy: changedBy,
+			Priority:  rec.order.Priority,
+			Notes:     notes,
+		},
+		id: s.logID,
+	})
+}
+
+// logStation adds a station status log entry of the order, must be called with the mutex held.
+func (s *Store) logStation(rec *orderRecord, at time.Time, station, status, changedBy string) {
+	s.logID++
+	rec.history = append(rec.history, historyEntry{
+		OrderHistory: models.OrderHistory{
+			Status:    status,
+			Timestamp: at,
+			ChangedBy: changedBy,
+			Priority:  rec.order.Priority,
+			Station:   station,
+		},
+		id: s.logID,
+	})
+}
+
+// cloneOrder returns a copy of the order, so callers can't change the store.
+func cloneOrder(order models.Order) models.Order {
+	order.Items = slices.Clone(order.Items)
+	order.Discounts = slices.Clone(order.Discounts)
+	order.LowStock = nil
+	return order
+}
+
+// checkCtx returns error of the cancelled context, queries of the database fail the same way.
+func checkCtx(ctx context.Context, op string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// Reasons worker sessions end with.
+const (
+	sessionGraceful         = "graceful"
+	sessionEvicted          = "evicted"
+	sessionHeartbeatTimeout = "heartbeat_timeout"
+)
+
+// WorkerRepo implements worker repositories of the kitchen and tracking services.
+type WorkerRepo struct {
+	store *Store
+}
+
+func NewWorkerRepo(store *Store) *WorkerRepo {
+	return &WorkerRepo{store: store}
+}
+
+// List returns workers of the location.
+func (r *WorkerRepo) List(ctx context.Context, locationID string) ([]models.Worker, error) {
+	if err := checkCtx(ctx, "workerRepository.List"); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var workers []models.Worker
+	for _, w := range r.store.workers {
+		if w.LocationID == locationID {
+			workers = append(workers, cloneWorker(w))
+		}
+	}
+
+	if len(workers) == 0 {
+		return nil, models.ErrWorkerNotFound
+	}
+	slices.SortFunc(workers, func(a, b models.Worker) int { return cmp.Compare(a.Name, b.Name) })
+
+	return workers, nil
+}
+
+// Get returns the worker of the location.
+func (r *WorkerRepo) Get(ctx context.Context, locationID, name string) (models.Worker, error) {
+	if err := checkCtx(ctx, "workerRepository.Get"); err != nil {
+		return models.Worker{}, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	w, ok := r.store.workers[key(locationID, name)]
+	if !ok {
+		return models.Worker{}, models.ErrWorkerNotFound
+	}
+
+	return cloneWorker(w), nil
+}
+
+// Sessions returns latest online periods of the worker, newest first.
+func (r *WorkerRepo) Sessions(ctx context.Context, locationID, name string, limit int) ([]models.WorkerSession, error) {
+	if err := checkCtx(ctx, "workerRepository.Sessions"); err != nil {
+		return nil, err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored := r.store.sessions[key(locationID, name)]
+	sessions := []models.WorkerSession{}
+	for i := len(stored) - 1; i >= 0 && len(sessions) < limit; i-- {
+		sessions = append(sessions, *stored[i])
+	}
+
+	return sessions, nil
+}
+
+// EndTimedOutSessions ends open sessions of workers of the location not seen for two of their heartbeat
+// intervals, or for timeout if they did not report one, as timed out when the worker was last seen.
+func (r *WorkerRepo) EndTimedOutSessions(ctx context.Context, locationID string, timeout time.Duration) error {
+	if err := checkCtx(ctx, "workerRepository.EndTimedOutSessions"); err != nil {
+		return err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, w := range s.workers {
+		threshold := timeout
+		if w.HeartbeatInterval > 0 {
+			threshold = 2 * time.Duration(w.HeartbeatInterval) * time.Second
+		}
+		if w.LocationID == locationID && now.Sub(w.LastSeen) > threshold {
+			s.endSession(k, w.LastSeen, sessionHeartbeatTimeout)
+		}
+	}
+
+	return nil
+}
+
+// MarkOnline marks a worker as online and opens a new session. A worker online and seen within
+// two heartbeat intervals can't be registered again, otherwise its open session ends as timed out.
+// Returns id of the new session.
+func (r *WorkerRepo) MarkOnline(ctx context.Context, locationID, name string, hb models.Heartbeat) (int, error) {
+	const op = "workerRepository.MarkOnline"
+	if err := checkCtx(ctx, op); err != nil {
+		return 0, err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(locationID, name)
+	now := time.Now()
+
+	w, ok := s.workers[k]
+	if ok && w.Status != types.WorkerOffline && !w.LastSeen.Before(now.Add(-2*hb.Interval)) {
+		return 0, fmt.Errorf("%s: %v", op, models.ErrWorkerAlreadyOnline)
+	}
+
+	if ok {
+		lastSeen := w.LastSeen
+		s.endSession(k, lastSeen, sessionHeartbeatTimeout)
+	} else {
+		w = &models.Worker{Name: name, LocationID: locationID}
+		s.workers[k] = w
+	}
+
+	w.Status = types.WorkerOnline
+	w.Command = nil
+	w.ActiveOrders = 0
+	w.CurrentOrder = nil
+	w.LastSeen = now
+	applyHeartbeat(w, hb)
+
+	s.sessionID++
+	s.sessions[k] = append(s.sessions[k], &models.WorkerSession{
+		ID:         s.sessionID,
+		Version:    hb.Version,
+		Host:       hb.Host,
+		PID:        hb.PID,
+		OrderTypes: hb.OrderTypes,
+		Stations:   hb.Stations,
+		StartedAt:  now,
+	})
+
+	return s.sessionID, nil
+}
+
+// Heartbeat updates last seen timestamp with the state reported by the worker instance of the session and returns
+// admin command waiting for the worker, if any. Returns models.ErrWorkerReplaced if a newer instance registered.
+func (r *WorkerRepo) Heartbeat(ctx context.Context, locationID, name string, sessionID int, hb models.Heartbeat) (string, error) {
+	if err := checkCtx(ctx, "workerRepository.Heartbeat"); err != nil {
+		return "", err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	k := key(locationID, name)
+	w, ok := r.store.workers[k]
+	if !ok {
+		return "", models.ErrWorkerNotFound
+	}
+	if r.store.latestSession(k) != sessionID {
+		return "", models.ErrWorkerReplaced
+	}
+
+	w.LastSeen = time.Now()
+	applyHeartbeat(w, hb)
+	w.ActiveOrders = hb.ActiveOrders
+	w.CurrentOrder = nil
+	if hb.CurrentOrder != "" {
+		current := hb.CurrentOrder
+		w.CurrentOrder = &current
+	}
+
+	if w.Command == nil {
+		return "", nil
+	}
+	return *w.Command, nil
+}
+
+// AckCommand clears the command picked up by the worker and reports the resulting worker status.
+// A newer command given meanwhile is kept.
+func (r *WorkerRepo) AckCommand(ctx context.Context, locationID, name, command, status string) error {
+	if err := checkCtx(ctx, "workerRepository.AckCommand"); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	w, ok := r.store.workers[key(locationID, name)]
+	if ok && w.Command != nil && *w.Command == command {
+		w.Status = status
+		w.Command = nil
+	}
+
+	return nil
+}
+
+// SetStatus reports status of the working worker, e.g. off duty outside its shifts.
+func (r *WorkerRepo) SetStatus(ctx context.Context, locationID, name, status string) error {
+	if err := checkCtx(ctx, "workerRepository.SetStatus"); err != nil {
+		return err
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	w, ok := r.store.workers[key(locationID, name)]
+	if !ok {
+		return models.ErrWorkerNotFound
+	}
+	w.Status = status
+
+	return nil
+}
+
+// SetCommand gives an admin command to the worker. Evict marks the worker offline at once and ends its
+// session. Other commands are accepted only for workers seen after onlineSince.
+func (r *WorkerRepo) SetCommand(ctx context.Context, locationID, name, command string, onlineSince time.Time) (models.Worker, error) {
+	if err := checkCtx(ctx, "workerRepository.SetCommand"); err != nil {
+		return models.Worker{}, err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(locationID, name)
+	w, ok := s.workers[k]
+	if !ok {
+		return models.Worker{}, models.ErrWorkerNotFound
+	}
+
+	if command != types.WorkerCommandEvict && (w.Status == types.WorkerOffline || w.LastSeen.Before(onlineSince)) {
+		return models.Worker{}, models.ErrWorkerOffline
+	}
+
+	w.Command = &command
+	if command == types.WorkerCommandEvict {
+		w.Status = types.WorkerOffline
+		s.endSession(k, time.Now(), sessionEvicted)
+	}
+
+	return cloneWorker(w), nil
+}
+
+// IncrOrdersProcessed increments number of orders processed by the worker and by its current session.
+func (r *WorkerRepo) IncrOrdersProcessed(ctx context.Context, locationID, name string) error {
+	if err := checkCtx(ctx, "workerRepository.IncrOrdersProcessed"); err != nil {
+		return err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(locationID, name)
+	w, ok := s.workers[k]
+	if !ok {
+		return models.ErrOrderNotFound
+	}
+	w.ProcessedOrders++
+
+	if session := s.openSession(k); session != nil {
+		session.ProcessedOrders++
+	}
+
+	return nil
+}
+
+// MarkOffline marks the worker offline and ends the session of its instance gracefully. A worker registered
+// by a newer instance meanwhile is left alone.
+func (r *WorkerRepo) MarkOffline(ctx context.Context, locationID, name string, sessionID int) error {
+	if err := checkCtx(ctx, "workerRepository.MarkOffline"); err != nil {
+		return err
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key(locationID, name)
+	w, ok := s.workers[k]
+	if !ok {
+		return models.ErrWorkerNotFound
+	}
+	if s.latestSession(k) != sessionID {
+		return nil
+	}
+
+	now := time.Now()
+	s.endSession(k, now, sessionGraceful)
+
+	w.Status = types.WorkerOffline
+	w.Command = nil
+	w.ActiveOrders = 0
+	w.CurrentOrder = nil
+	w.LastSeen = now
+
+	return nil
+}
+
+// openSession returns the open session of the worker, must be called with the mutex held.
+func (s *Store) openSession(k string) *models.WorkerSession {
+	sessions := s.sessions[k]
+	if n := len(sessions); n > 0 && sessions[n-1].EndedAt == nil {
+		return sessions[n-1]
+	}
+	return nil
+}
+
+// latestSession returns id of the session of the latest worker instance, must be called with the mutex held.
+func (s *Store) latestSession(k string) int {
+	sessions := s.sessions[k]
+	if n := len(sessions); n > 0 {
+		return sessions[n-1].ID
+	}
+	return 0
+}
+
+// endSession ends the open session of the worker, must be called with the mutex held.
+func (s *Store) endSession(k string, at time.Time, reason string) {
+	session := s.openSession(k)
+	if session == nil {
+		return
+	}
+	session.EndedAt = &at
+	session.EndReason = &reason
+}
+
+func applyHeartbeat(w *models.Worker, hb models.Heartbeat) {
+	w.OrderTypes = hb.OrderTypes
+	w.Stations = hb.Stations
+	w.Version = hb.Version
+	w.Host = hb.Host
+	w.PID = hb.PID
+	w.HeartbeatInterval = int(hb.Interval.Seconds())
+}
+
+func cloneWorker(w *models.Worker) models.Worker {
+	worker := *w
+	if w.Command != nil {
+		command := *w.Command
+		worker.Command = &command
+	}
+	if w.CurrentOrder != nil {
+		current := *w.CurrentOrder
+		worker.CurrentOrder = &current
+	}
+	return worker
+}
@@ -0,0 +1,98 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// FakeProvider is a local payment provider for development and testing. It answers authorizations by
+// the script of outcomes in turn, repeating the script from the start when it ends.
+type FakeProvider struct {
+	mu     sync.Mutex
+	script []string
+	next   int
+	delay  time.Duration
+}
+
+func NewFakeProvider(script []string, delay time.Duration) *FakeProvider {
+	return &FakeProvider{
+		script: script,
+		delay:  delay,
+	}
+}
+
+// SetScript replaces the script, it is played from the start.
+func (p *FakeProvider) SetScript(script []string, delay time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.script = script
+	p.next = 0
+	p.delay = delay
+}
+
+func (p *FakeProvider) Name() string {
+	return types.PaymentProviderFake
+}
+
+// Authorize answers with the next outcome of the script after the delay.
+func (p *FakeProvider) Authorize(ctx context.Context, req models.PaymentRequest) (string, error) {
+	outcome, n, delay := p.outcome()
+
+	if err := wait(ctx, delay); err != nil {
+		return "", err
+	}
+
+	switch outcome {
+	case types.FakePaymentDecline:
+		return "", fmt.Errorf("%w: card declined by fake provider", models.ErrPaymentDeclined)
+	case types.FakePaymentTimeout:
+		<-ctx.Done()
+		return "", ctx.Err()
+	default:
+		return fmt.Sprintf("fake-%s-%d", strings.ReplaceAll(req.Reference, "/", "-"), n), nil
+	}
+}
+
+// Refund always succeeds after the delay.
+func (p *FakeProvider) Refund(ctx context.Context, ref string, amount float64) error {
+	p.mu.Lock()
+	delay := p.delay
+	p.mu.Unlock()
+
+	return wait(ctx, delay)
+}
+
+// outcome takes the next outcome of the script and its sequence number.
+func (p *FakeProvider) outcome() (string, int, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.next++
+	if len(p.script) == 0 {
+		return types.FakePaymentApprove, p.next, p.delay
+	}
+	return p.script[(p.next-1)%len(p.script)], p.next, p.delay
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/jackc/pgx/v5"
+)
+
+// PromoteWaiting raises priority of up to limit orders which have been waiting in the queue for at least
+// waited and have lower priority. Every promotion is logged in order_status_log with the new priority.
+//
+// publish is called for every promoted order inside the transaction, so priority is not changed if the
+// order could not be republished. Orders locked by another order-service are skipped.
+func (r *orderRepository) PromoteWaiting(ctx context.Context, waited time.Duration, priority int, changedBy string, limit int, publish func(order *models.CreateOrder) error) (int, error) {
+	const op = "orderRepository.PromoteWaiting"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	ids, orders, err := lockOrders(ctx, tx, `
+	WHERE
+		status = 'received'
+		AND priority < $1
+		AND queued_at <= now() - $2::float8 * INTERVAL '1 second'
+	ORDER BY
+		queued_at
+	LIMIT $3`, priority, waited.Seconds(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+
+	for i, o := range orders {
+		notes := fmt.Sprintf("priority aged from %d to %d after waiting %s", o.Priority, priority, waited)
+		o.Priority = priority
+
+		if _, err := tx.Exec(ctx,
+			`UPDATE orders SET priority = $1, updated_at = now() WHERE id = $2`,
+			priority, ids[i],
+		); err != nil {
+			return 0, fmt.Errorf("%s: %v", op, err)
+		}
+
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO order_status_log (order_id, status, changed_by, notes, priority) VALUES ($1, $2, $3, $4, $5)`,
+			ids[i], types.StatusOrderReceived, changedBy, notes, priority,
+		); err != nil {
+			return 0, fmt.Errorf("%s: %v", op, err)
+		}
+
+		if err := publish(o); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return len(orders), nil
+}
+
+// lockOrders selects orders matching the condition with their items and locks them until the end of
+// the transaction. Orders locked by another transaction are skipped. It returns ids of the orders too.
+func lockOrders(ctx context.Context, tx pgx.Tx, condition string, args ...any) ([]int, []*models.CreateOrder, error) {
+	rows, err := tx.Query(ctx, `
+	SELECT
+		id, location_id, number, customer_name, type,
+		table_number, delivery_address, total_amount, priority, status, scheduled_for, version
+	FROM
+		orders
+	`+condition+`
+	FOR UPDATE SKIP LOCKED;`, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ids []int
+	orders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.CreateOrder, error) {
+		var (
+			id int
+			o  models.CreateOrder
+		)
+		err := row.Scan(&id, &o.LocationID, &o.Number, &o.CustomerName, &o.Type,
+			&o.TableNumber, &o.DeliveryAddress, &o.TotalAmount, &o.Priority, &o.Status, &o.ScheduledFor, &o.Version)
+		ids = append(ids, id)
+		return &o, err
+	})
+	if err != nil || len(orders) == 0 {
+		return nil, nil, err
+	}
+
+	rows, err = tx.Query(ctx, `
+	SELECT
+		order_id, name, quantity, price, COALESCE(station, '')
+	FROM
+		order_items
+	WHERE
+		order_id = ANY($1)
+	ORDER BY
+		id;`, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := make(map[int][]models.CreateOrderItem)
+	_, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (struct{}, error) {
+		var (
+			orderID int
+			item    models.CreateOrderItem
+		)
+		err := row.Scan(&orderID, &item.Name, &item.Quantity, &item.Price, &item.Station)
+		items[orderID] = append(items[orderID], item)
+		return struct{}{}, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, o := range orders {
+		o.Items = items[ids[i]]
+	}
+
+	return ids, orders, nil
+}
@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type analyticsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAnalyticsRepo(pool *pgxpool.Pool) *analyticsRepository {
+	return &analyticsRepository{
+		pool: pool,
+	}
+}
+
+// timingsCTE selects status transition times of the orders created in the window ($1 location, $2 from, $3 to).
+const timingsCTE = `
+	WITH timings AS (
+		SELECT
+			o.id,
+			o.type,
+			o.processed_by,
+			MIN(l.changed_at) FILTER (WHERE l.status = 'received') AS received_at,
+			MIN(l.changed_at) FILTER (WHERE l.status = 'cooking')  AS cooking_at,
+			MIN(l.changed_at) FILTER (WHERE l.status = 'ready')    AS ready_at,
+			BOOL_OR(l.status = 'cancelled')                        AS cancelled
+		FROM
+			orders o
+		INNER JOIN order_status_log l ON l.order_id = o.id
+		WHERE
+			o.location_id = $1
+			AND o.created_at >= $2
+			AND o.created_at < $3
+		GROUP BY
+			o.id
+	)`
+
+// percentiles selects p50, p90 and p99 of the interval in seconds, nulls are ignored.
+const percentiles = `COALESCE(
+		percentile_cont(ARRAY[0.5, 0.9, 0.99]) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM %s)::float8),
+		ARRAY[0, 0, 0]::float8[]
+	)`
+
+// Throughput returns number of orders which became ready within every bucket of the window, by order type.
+func (r *analyticsRepository) Throughput(ctx context.Context, window models.AnalyticsWindow, bucket time.Duration) ([]models.ThroughputPoint, error) {
+	const op = "analyticsRepository.Throughput"
+
+	query := `
+	SELECT
+		date_bin($4::float8 * INTERVAL '1 second', l.changed_at, $2) AS bucket,
+		o.type,
+		COUNT(*)
+	FROM
+		order_status_log l
+	INNER JOIN orders o ON o.id = l.order_id
+	WHERE
+		l.status = 'ready'
+		AND l.changed_at >= $2
+		AND l.changed_at < $3
+		AND o.location_id = $1
+	GROUP BY
+		bucket, o.type
+	ORDER BY
+		bucket, o.type;`
+
+	rows, err := r.pool.Query(ctx, query, window.LocationID, window.From, window.To, bucket.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	points, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ThroughputPoint, error) {
+		var p models.ThroughputPoint
+		err := row.Scan(&p.BucketStart, &p.OrderType, &p.Orders)
+		return p, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return points, nil
+}
+
+// Durations returns queue wait (received -> cooking) and cooking (cooking -> ready) percentiles.
+func (r *analyticsRepository) Durations(ctx context.Context, window models.AnalyticsWindow) (models.KitchenDurations, error) {
+	const op = "analyticsRepository.Durations"
+
+	query := timingsCTE + `
+	SELECT
+		COUNT(cooking_at - received_at),
+		` + fmt.Sprintf(percentiles, "cooking_at - received_at") + `,
+		COUNT(ready_at - cooking_at),
+		` + fmt.Sprintf(percentiles, "ready_at - cooking_at") + `
+	FROM
+		timings;`
+
+	var (
+		d                  models.KitchenDurations
+		queueWait, cooking []float64
+	)
+	err := r.pool.QueryRow(ctx, query, window.LocationID, window.From, window.To).
+		Scan(&d.QueueWait.Count, &queueWait, &d.Cooking.Count, &cooking)
+	if err != nil {
+		return models.KitchenDurations{}, fmt.Errorf("%s: %v", op, err)
+	}
+	setPercentiles(&d.QueueWait, queueWait)
+	setPercentiles(&d.Cooking, cooking)
+
+	return d, nil
+}
+
+// Workers returns number of processed orders and cooking percentiles of every worker.
+func (r *analyticsRepository) Workers(ctx context.Context, window models.AnalyticsWindow) ([]models.WorkerPerformance, error) {
+	const op = "analyticsRepository.Workers"
+
+	query := timingsCTE + `
+	SELECT
+		processed_by,
+		COUNT(ready_at),
+		COUNT(ready_at - cooking_at),
+		` + fmt.Sprintf(percentiles, "ready_at - cooking_at") + `
+	FROM
+		timings
+	WHERE
+		processed_by IS NOT NULL
+	GROUP BY
+		processed_by
+	ORDER BY
+		COUNT(ready_at) DESC, processed_by;`
+
+	rows, err := r.pool.Query(ctx, query, window.LocationID, window.From, window.To)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	workers, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WorkerPerformance, error) {
+		var (
+			w       models.WorkerPerformance
+			cooking []float64
+		)
+		err := row.Scan(&w.Worker, &w.Orders, &w.Cooking.Count, &cooking)
+		setPercentiles(&w.Cooking, cooking)
+		return w, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return workers, nil
+}
+
+// SLA returns number of received orders and how many of them were not ready within sla.
+// Cancelled orders are not counted.
+func (r *analyticsRepository) SLA(ctx context.Context, window models.AnalyticsWindow, sla time.Duration) (models.SLAReport, error) {
+	const op = "analyticsRepository.SLA"
+
+	query := timingsCTE + `
+	SELECT
+		COUNT(*),
+		COUNT(*) FILTER (WHERE COALESCE(ready_at, now()) - received_at > $4::float8 * INTERVAL '1 second')
+	FROM
+		timings
+	WHERE
+		received_at IS NOT NULL
+		AND NOT cancelled;`
+
+	report := models.SLAReport{SLASeconds: sla.Seconds()}
+	err := r.pool.QueryRow(ctx, query, window.LocationID, window.From, window.To, sla.Seconds()).
+		Scan(&report.Orders, &report.Breached)
+	if err != nil {
+		return models.SLAReport{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return report, nil
+}
+
+func setPercentiles(stats *models.DurationStats, p []float64) {
+	if len(p) != 3 {
+		return
+	}
+	stats.P50, stats.P90, stats.P99 = p[0], p[1], p[2]
+}
@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type customerRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCustomerRepo(pool *pgxpool.Pool) *customerRepository {
+	return &customerRepository{
+		pool: pool,
+	}
+}
+
+const customerColumns = `
+		id, name, phone, email, default_address, loyalty_points, created_at, updated_at`
+
+func scanCustomer(row pgx.Row, c *models.Customer) error {
+	return row.Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.DefaultAddress, &c.LoyaltyPoints, &c.CreatedAt, &c.UpdatedAt)
+}
+
+// Create stores a new customer. Returns models.ErrCustomerExists if the phone is taken.
+func (r *customerRepository) Create(ctx context.Context, c models.Customer) (models.Customer, error) {
+	const op = "customerRepository.Create"
+
+	var customer models.Customer
+	err := scanCustomer(r.pool.QueryRow(ctx, `
+	INSERT INTO customers (name, phone, email, default_address)
+	VALUES ($1, $2, $3, $4)
+	RETURNING`+customerColumns,
+		c.Name, c.Phone, c.Email, c.DefaultAddress,
+	), &customer)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.Customer{}, models.ErrCustomerExists
+		}
+		return models.Customer{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return customer, nil
+}
+
+// Get returns customer by id.
+func (r *customerRepository) Get(ctx context.Context, id int) (models.Customer, error) {
+	const op = "customerRepository.Get"
+
+	var customer models.Customer
+	err := scanCustomer(r.pool.QueryRow(ctx, `
+	SELECT`+customerColumns+`
+	FROM
+		customers
+	WHERE
+		id = $1;`, id), &customer)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Customer{}, models.ErrCustomerNotFound
+		}
+		return models.Customer{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return customer, nil
+}
+
+// GetByPhone returns customer by normalized phone.
+func (r *customerRepository) GetByPhone(ctx context.Context, phone string) (models.Customer, error) {
+	const op = "customerRepository.GetByPhone"
+
+	var customer models.Customer
+	err := scanCustomer(r.pool.QueryRow(ctx, `
+	SELECT`+customerColumns+`
+	FROM
+		customers
+	WHERE
+		phone = $1;`, phone), &customer)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Customer{}, models.ErrCustomerNotFound
+		}
+		return models.Customer{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return customer, nil
+}
+
+// BackfillOrders links anonymous orders to customers by case-insensitive name match.
+// Names shared by several customers are ambiguous and skipped. Returns number of linked orders.
+func (r *customerRepository) BackfillOrders(ctx context.Context) (int, error) {
+	const op = "customerRepository.BackfillOrders"
+
+	res, err := r.pool.Exec(ctx, `
+	WITH unique_names AS (
+		SELECT
+			lower(name) AS name,
+			min(id) AS id
+		FROM
+			customers
+		GROUP BY
+			lower(name)
+		HAVING
+			count(*) = 1
+	)
+	UPDATE
+		orders o
+	SET
+		customer_id = u.id
+	FROM
+		unique_names u
+	WHERE
+		o.customer_id IS NULL
+		AND lower(o.customer_name) = u.name;`)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return int(res.RowsAffected()), nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Number of the latest cook times ETA is based on, and how old they can be.
+const (
+	etaCookTimeSamples = 100
+	etaCookTimeMaxAge  = 7 * 24 * time.Hour
+)
+
+type etaRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewETARepo(pool *pgxpool.Pool) *etaRepository {
+	return &etaRepository{
+		pool: pool,
+	}
+}
+
+// Inputs returns queue depth, online workers and recent cook times for the order of the given type queued at queuedAt.
+// Workers which were not seen after onlineSince are treated as offline.
+func (r *etaRepository) Inputs(ctx context.Context, locationID, orderType string, queuedAt, onlineSince time.Time) (models.ETAInputs, error) {
+	const op = "etaRepository.Inputs"
+
+	query := `
+	SELECT
+		(
+			SELECT COUNT(*)
+			FROM orders
+			WHERE
+				location_id = $1
+				AND type = $2
+				AND status = 'received'
+				AND queued_at <= $3
+		),
+		(
+			SELECT COUNT(*)
+			FROM workers
+			WHERE
+				location_id = $1
+				AND status = 'online'
+				AND last_seen >= $4
+				AND $2 = ANY(string_to_array(type, ','))
+		),
+		s.count,
+		s.p[1],
+		s.p[2],
+		s.p[3]
+	FROM (
+		SELECT
+			COUNT(d) AS count,
+			` + fmt.Sprintf(percentiles, "d") + ` AS p
+		FROM (
+			SELECT
+				r.changed_at - c.changed_at AS d
+			FROM
+				order_status_log r
+			INNER JOIN orders o ON o.id = r.order_id
+			INNER JOIN order_status_log c ON c.order_id = r.order_id AND c.status = 'cooking'
+			WHERE
+				r.status = 'ready'
+				AND r.changed_at >= now() - $5::float8 * INTERVAL '1 second'
+				AND o.location_id = $1
+				AND o.type = $2
+			ORDER BY
+				r.changed_at DESC
+			LIMIT $6
+		) samples
+	) s;`
+
+	var in models.ETAInputs
+	err := r.pool.QueryRow(ctx, query, locationID, orderType, queuedAt, onlineSince, etaCookTimeMaxAge.Seconds(), etaCookTimeSamples).
+		Scan(&in.QueueAhead, &in.OnlineWorkers, &in.CookTime.Count, &in.CookTime.P50, &in.CookTime.P90, &in.CookTime.P99)
+	if err != nil {
+		return models.ETAInputs{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return in, nil
+}
@@ -0,0 +1,275 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type inventoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewInventoryRepo(pool *pgxpool.Pool) *inventoryRepository {
+	return &inventoryRepository{
+		pool: pool,
+	}
+}
+
+const stockColumns = `
+		location_id, ingredient, unit, quantity::float8, reserved::float8,
+		(quantity - reserved)::float8, low_stock_threshold::float8, updated_at`
+
+func scanStock(row pgx.Row, s *models.StockItem) error {
+	return row.Scan(&s.LocationID, &s.Ingredient, &s.Unit, &s.Quantity, &s.Reserved,
+		&s.Available, &s.LowStockThreshold, &s.UpdatedAt)
+}
+
+// List returns stock of all ingredients of the location.
+func (r *inventoryRepository) List(ctx context.Context, locationID string) ([]models.StockItem, error) {
+	const op = "inventoryRepository.List"
+
+	rows, err := r.pool.Query(ctx, `
+	SELECT`+stockColumns+`
+	FROM
+		inventory
+	WHERE
+		location_id = $1
+	ORDER BY
+		ingredient;`, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	stock, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.StockItem, error) {
+		var s models.StockItem
+		return s, scanStock(row, &s)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return stock, nil
+}
+
+// Restock adds quantity to the ingredient stock of the location, creating it if needed.
+func (r *inventoryRepository) Restock(ctx context.Context, req models.Restock) (models.StockItem, error) {
+	const op = "inventoryRepository.Restock"
+
+	var stock models.StockItem
+	err := scanStock(r.pool.QueryRow(ctx, `
+	INSERT INTO inventory (location_id, ingredient, quantity, unit, low_stock_threshold)
+	VALUES ($1, $2, $3, COALESCE($4, ''), COALESCE($5, 0))
+	ON CONFLICT (location_id, ingredient) DO UPDATE
+	SET
+		quantity = inventory.quantity + EXCLUDED.quantity,
+		unit = COALESCE($4, inventory.unit),
+		low_stock_threshold = COALESCE($5, inventory.low_stock_threshold),
+		updated_at = now()
+	RETURNING`+stockColumns,
+		req.LocationID, req.Ingredient, req.Quantity, req.Unit, req.LowStockThreshold,
+	), &stock)
+	if err != nil {
+		return models.StockItem{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return stock, nil
+}
+
+// Recipes returns all recipes. An item is available if the stock of the location is enough for one unit of it.
+func (r *inventoryRepository) Recipes(ctx context.Context, locationID string) ([]models.Recipe, error) {
+	const op = "inventoryRepository.Recipes"
+
+	rows, err := r.pool.Query(ctx, `
+	SELECT
+		r.item_name, r.ingredient, r.quantity::float8,
+		COALESCE(i.quantity - i.reserved, 0) >= r.quantity AS in_stock
+	FROM
+		recipes r
+	LEFT JOIN inventory i ON i.location_id = $1 AND i.ingredient = r.ingredient
+	ORDER BY
+		r.item_name, r.ingredient;`, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	defer rows.Close()
+
+	var recipes []models.Recipe
+	for rows.Next() {
+		var (
+			name    string
+			inStock bool
+			ing     models.RecipeIngredient
+		)
+		if err := rows.Scan(&name, &ing.Ingredient, &ing.Quantity, &inStock); err != nil {
+			return nil, fmt.Errorf("%s: %v", op, err)
+		}
+
+		if len(recipes) == 0 || recipes[len(recipes)-1].ItemName != name {
+			recipes = append(recipes, models.Recipe{ItemName: name, Available: true})
+		}
+		last := &recipes[len(recipes)-1]
+		last.Ingredients = append(last.Ingredients, ing)
+		last.Available = last.Available && inStock
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return recipes, nil
+}
+
+// SetRecipe replaces ingredients of the menu item.
+func (r *inventoryRepository) SetRecipe(ctx context.Context, recipe models.Recipe) error {
+	const op = "inventoryRepository.SetRecipe"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM recipes WHERE lower(item_name) = lower($1)`, recipe.ItemName); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	for _, ing := range recipe.Ingredients {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO recipes (item_name, ingredient, quantity) VALUES ($1, $2, $3)`,
+			recipe.ItemName, ing.Ingredient, ing.Quantity,
+		); err != nil {
+			return fmt.Errorf("%s: %v", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	return nil
+}
+
+// reserveStock reserves ingredients of the order items in the transaction. If the stock is not enough
+// for some items, *models.OutOfStockError with all of them is returned. It returns ingredients whose
+// available stock dropped below the low stock threshold by this order.
+func reserveStock(ctx context.Context, tx pgx.Tx, orderID int, locationID string, items []models.CreateOrderItem) ([]models.StockItem, error) {
+	quantities := make(map[string]int, len(items)) // by lower item name
+	names := make(map[string]string, len(items))
+	for _, item := range items {
+		key := strings.ToLower(item.Name)
+		quantities[key] += item.Quantity
+		names[key] = item.Name
+	}
+
+	rows, err := tx.Query(ctx, `
+	SELECT
+		lower(item_name), ingredient, quantity::float8
+	FROM
+		recipes
+	WHERE
+		lower(item_name) = ANY($1);`, slices.Collect(maps.Keys(quantities)))
+	if err != nil {
+		return nil, err
+	}
+
+	need := make(map[string]float64)    // by ingredient
+	usedBy := make(map[string][]string) // item names by ingredient
+	_, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (struct{}, error) {
+		var (
+			item, ingredient string
+			quantity         float64
+		)
+		err := row.Scan(&item, &ingredient, &quantity)
+		need[ingredient] += quantity * float64(quantities[item])
+		usedBy[ingredient] = append(usedBy[ingredient], names[item])
+		return struct{}{}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows are locked in the same order by all orders to avoid deadlocks.
+	ingredients := slices.Sorted(maps.Keys(need))
+
+	var (
+		low   []models.StockItem
+		short []string
+	)
+	for _, ingredient := range ingredients {
+		var stock models.StockItem
+		err := scanStock(tx.QueryRow(ctx, `
+		UPDATE inventory
+		SET
+			reserved = reserved + $3,
+			updated_at = now()
+		WHERE
+			location_id = $1
+			AND ingredient = $2
+			AND quantity - reserved >= $3
+		RETURNING`+stockColumns,
+			locationID, ingredient, need[ingredient],
+		), &stock)
+		if err == pgx.ErrNoRows {
+			short = append(short, usedBy[ingredient]...)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO inventory_reservations (order_id, location_id, ingredient, quantity) VALUES ($1, $2, $3, $4)`,
+			orderID, locationID, ingredient, need[ingredient],
+		); err != nil {
+			return nil, err
+		}
+
+		// Alert once, when the order takes stock below the threshold.
+		if stock.Available < stock.LowStockThreshold && stock.Available+need[ingredient] >= stock.LowStockThreshold {
+			low = append(low, stock)
+		}
+	}
+
+	if len(short) > 0 {
+		slices.Sort(short)
+		return nil, &models.OutOfStockError{Items: slices.Compact(short)}
+	}
+
+	return low, nil
+}
+
+// consumeStock removes stock reserved by the order, when it is ready.
+func consumeStock(ctx context.Context, tx pgx.Tx, orderID int) error {
+	return finishReservation(ctx, tx, orderID, "quantity = i.quantity - r.quantity,")
+}
+
+// releaseStock returns stock reserved by the order, when it is cancelled.
+func releaseStock(ctx context.Context, tx pgx.Tx, orderID int) error {
+	return finishReservation(ctx, tx, orderID, "")
+}
+
+func finishReservation(ctx context.Context, tx pgx.Tx, orderID int, consume string) error {
+	if _, err := tx.Exec(ctx, `
+	UPDATE inventory i
+	SET
+		`+consume+`
+		reserved = i.reserved - r.quantity,
+		updated_at = now()
+	FROM
+		inventory_reservations r
+	WHERE
+		r.order_id = $1
+		AND i.location_id = r.location_id
+		AND i.ingredient = r.ingredient;`, orderID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `DELETE FROM inventory_reservations WHERE order_id = $1`, orderID)
+	return err
+}
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/jackc/pgx/v5"
+)
+
+// Modify changes the received order of the location in one transaction. The order is loaded and passed to modify,
+// which changes its items and recalculates it, and returns notes for the status log. Items, discounts and stock
+// reservation are replaced, the version is incremented and publish is called with the new version, so the order
+// is not changed if it can't be republished. Only orders not taken by the kitchen yet can be modified,
+// models.ErrOrderNotModifiable is returned for others and models.ErrOrderPaid for orders with a payment.
+func (r *orderRepository) Modify(ctx context.Context, locationID, orderNumber, changedBy string, modify func(order *models.CreateOrder) (string, error), publish func(order *models.CreateOrder) error) (*models.Order, error) {
+	const op = "orderRepository.Modify"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	orderID, status, _, _, err := lockOrder(ctx, tx, locationID, orderNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != types.StatusOrderReceived {
+		return nil, models.ErrOrderNotModifiable
+	}
+
+	var paid bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM payments WHERE order_id = $1)`, orderID).Scan(&paid); err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	if paid {
+		return nil, models.ErrOrderPaid
+	}
+
+	req, err := modifiableOrder(ctx, tx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	redeemed := req.RedeemPoints
+
+	notes, err := modify(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM order_items WHERE order_id = $1`, orderID); err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	if err := insertItems(ctx, tx, orderID, req.Items); err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	// The promo code stays used by the order, only its discount amounts are replaced.
+	// Points no longer needed to pay the order are returned to the customer.
+	if _, err := tx.Exec(ctx, `DELETE FROM order_discounts WHERE order_id = $1`, orderID); err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	if err := insertDiscounts(ctx, tx, orderID, req.Discounts); err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	if req.CustomerID != nil && redeemed > req.RedeemPoints {
+		if _, err := tx.Exec(ctx,
+			`UPDATE customers SET loyalty_points = loyalty_points + $1, updated_at = now() WHERE id = $2`,
+			redeemed-req.RedeemPoints, *req.CustomerID,
+		); err != nil {
+			return nil, fmt.Errorf("%s: %v", op, err)
+		}
+	}
+
+	if err := releaseStock(ctx, tx, orderID); err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	lowStock, err := reserveStock(ctx, tx, orderID, locationID, req.Items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve stock: %w", err)
+	}
+
+	var order models.Order
+	err = scanOrder(tx.QueryRow(ctx, `
+	UPDATE orders
+	SET
+		subtotal = $1,
+		total_amount = $2,
+		tax_amount = $3,
+		service_charge = $4,
+		delivery_fee = $5,
+		priority = $6,
+		points_earned = $7,
+		points_redeemed = $8,
+		version = version + 1,
+		updated_at = now()
+	WHERE
+		id = $9
+	RETURNING`+orderColumns,
+		req.Subtotal, req.TotalAmount, req.TaxAmount, req.ServiceCharge, req.DeliveryFee,
+		req.Priority, req.PointsEarned, req.RedeemPoints, orderID,
+	), &order)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+	order.Discounts = req.Discounts
+	order.LowStock = lowStock
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO order_status_log (order_id, status, changed_by, notes, priority) VALUES ($1, $2, $3, $4, $5)`,
+		orderID, types.StatusOrderReceived, changedBy, fmt.Sprintf("modified to version %d: %s", order.Version, notes), order.Priority,
+	); err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	req.Version = order.Version
+	if err := publish(req); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return &order, nil
+}
+
+// modifiableOrder loads the order with its items and promo code as it was requested.
+func modifiableOrder(ctx context.Context, tx pgx.Tx, orderID int) (*models.CreateOrder, error) {
+	var (
+		o             models.CreateOrder
+		travelSeconds int
+	)
+	err := tx.QueryRow(ctx, `
+	SELECT
+		location_id, number, customer_name, customer_id, type, table_number, delivery_address,
+		COALESCE(delivery_zone, ''), delivery_fee, travel_seconds, tip_amount, points_redeemed,
+		priority, status, version,
+		COALESCE((SELECT code FROM order_discounts WHERE order_id = orders.id AND code IS NOT NULL LIMIT 1), '')
+	FROM
+		orders
+	WHERE
+		id = $1;`, orderID).Scan(
+		&o.LocationID, &o.Number, &o.CustomerName, &o.CustomerID, &o.Type, &o.TableNumber, &o.DeliveryAddress,
+		&o.DeliveryZone, &o.DeliveryFee, &travelSeconds, &o.Tip, &o.RedeemPoints,
+		&o.Priority, &o.Status, &o.Version,
+		&o.PromoCode,
+	)
+	if err != nil {
+		return nil, err
+	}
+	o.TravelTime = time.Duration(travelSeconds) * time.Second
+
+	rows, err := tx.Query(ctx, `
+	SELECT
+		name, quantity, price, COALESCE(station, ''), category, tax_rate
+	FROM
+		order_items
+	WHERE
+		order_id = $1
+	ORDER BY
+		id;`, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	o.Items, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.CreateOrderItem, error) {
+		var item models.CreateOrderItem
+		err := row.Scan(&item.Name, &item.Quantity, &item.Price, &item.Station, &item.Category, &item.TaxRate)
+		return item, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
@@ -30,9 +31,19 @@ func (r *orderRepository) Create(ctx context.Context, req *models.CreateOrder, c
 	}
 	defer tx.Rollback(ctx)
 
+	// Dine-in orders are placed on a table with an open session
+	var tableSessionID *int
+	if req.Type == types.OrderTypeDineIn && req.TableNumber != nil {
+		tableSessionID, err = attachTable(ctx, tx, req.LocationID, *req.TableNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to attach order to table: %w", err)
+		}
+	}
+
 	// Insert the order
-	err = tx.QueryRow(ctx,
+	err = scanOrder(tx.QueryRow(ctx,
 		`INSERT INTO orders (
+			location_id,
 			number, 
 			customer_name, 
 			type, 
@@ -40,12 +51,24 @@ func (r *orderRepository) Create(ctx context.Context, req *models.CreateOrder, c
 			delivery_address, 
 			total_amount, 
 			priority, 
-			status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING 
-			id, created_at, updated_at, number, customer_name, 
-			type, table_number, delivery_address, total_amount, 
-			priority, status, processed_by, completed_at`,
+			status,
+			scheduled_for,
+			release_at,
+			customer_id,
+			subtotal,
+			points_earned,
+			points_redeemed,
+			tax_amount,
+			service_charge,
+			tip_amount,
+			delivery_zone,
+			delivery_fee,
+			travel_seconds,
+			table_session_id,
+			queued_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NULLIF($19, ''), $20, $21, $22, CASE WHEN $9 IN ('scheduled', 'pending_payment') THEN NULL ELSE now() END)
+		RETURNING`+orderColumns,
+		req.LocationID,
 		req.Number,
 		req.CustomerName,
 		req.Type,
@@ -54,56 +77,65 @@ func (r *orderRepository) Create(ctx context.Context, req *models.CreateOrder, c
 		req.TotalAmount,
 		req.Priority,
 		req.Status,
-	).Scan(
-		&order.ID,
-		&order.CreatedAt,
-		&order.UpdatedAt,
-		&order.Number,
-		&order.CustomerName,
-		&order.Type,
-		&order.TableNumber,
-		&order.DeliveryAddress,
-		&order.TotalAmount,
-		&order.Priority,
-		&order.Status,
-		&order.ProcessedBy,
-		&order.CompletedAt,
-	)
+		req.ScheduledFor,
+		req.ReleaseAt,
+		req.CustomerID,
+		req.Subtotal,
+		req.PointsEarned,
+		req.RedeemPoints,
+		req.TaxAmount,
+		req.ServiceCharge,
+		req.Tip,
+		req.DeliveryZone,
+		req.DeliveryFee,
+		int(req.TravelTime.Seconds()),
+		tableSessionID,
+	), &order)
 	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, models.ErrCustomerNotFound
+		}
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
 
 	// Insert order items
-	for _, item := range req.Items {
-		_, err := tx.Exec(ctx,
-			`INSERT INTO order_items (
-				order_id, 
-				name, 
-				quantity, 
-				price
-			) VALUES ($1, $2, $3, $4)`,
-			order.ID,
-			item.Name,
-			item.Quantity,
-			item.Price,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create order item: %w", err)
+	if err := insertItems(ctx, tx, order.ID, req.Items); err != nil {
+		return nil, fmt.Errorf("failed to create order item: %w", err)
+	}
+
+	// Store discounts, use up the promo code and pay with loyalty points
+	if err := applyDiscounts(ctx, tx, order.ID, order.CustomerID, order.PointsRedeemed, req.Discounts); err != nil {
+		return nil, fmt.Errorf("failed to apply discounts: %w", err)
+	}
+	order.Discounts = req.Discounts
+
+	// Orders waiting for payment are paid by payment-service
+	if req.Status == types.StatusOrderPendingPayment {
+		if err := createPayment(ctx, tx, order.ID, order.TotalAmount); err != nil {
+			return nil, fmt.Errorf("failed to create payment: %w", err)
 		}
 	}
 
+	// Reserve ingredients, the order is rejected if the stock is not enough
+	order.LowStock, err = reserveStock(ctx, tx, order.ID, order.LocationID, req.Items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve stock: %w", err)
+	}
+
 	// Log initial status
 	_, err = tx.Exec(ctx,
 		`INSERT INTO order_status_log (
 			order_id,
 			status,
 			changed_by,
-			notes
-		) VALUES ($1, $2, $3, $4)`,
+			notes,
+			priority
+		) VALUES ($1, $2, $3, $4, $5)`,
 		order.ID,
-		types.StatusOrderReceived, // 'received'
+		req.Status, // 'received', 'scheduled' or 'pending_payment'
 		changedBy,
 		notes,
+		order.Priority,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to log initial order status: %w", err)
@@ -117,7 +149,40 @@ func (r *orderRepository) Create(ctx context.Context, req *models.CreateOrder, c
 	return &order, nil
 }
 
-func (r *orderRepository) GetAndIncrementSequence(ctx context.Context, date string) (int, error) {
+func insertItems(ctx context.Context, tx pgx.Tx, orderID int, items []models.CreateOrderItem) error {
+	for _, item := range items {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO order_items (
+				order_id, 
+				name, 
+				quantity, 
+				price,
+				station,
+				category,
+				tax_rate
+			) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)`,
+			orderID,
+			item.Name,
+			item.Quantity,
+			item.Price,
+			item.Station,
+			item.Category,
+			item.TaxRate,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAndIncrementSequence returns next order sequence of the location for the date.
+func (r *orderRepository) GetAndIncrementSequence(ctx context.Context, locationID, date string) (int, error) {
+	return r.ReserveSequences(ctx, locationID, date, 1)
+}
+
+// ReserveSequences reserves a block of n order sequences of the location for the date and returns the first of them.
+func (r *orderRepository) ReserveSequences(ctx context.Context, locationID, date string, n int) (int, error) {
 	var seq int
 
 	// Configure transaction with serializable isolation level
@@ -136,13 +201,15 @@ func (r *orderRepository) GetAndIncrementSequence(ctx context.Context, date stri
 
 	// Use the new pgx syntax for querying
 	err = tx.QueryRow(ctx,
-		`INSERT INTO order_sequences (date, last_value) 
-		VALUES ($1, 1)
-		ON CONFLICT (date) DO UPDATE 
-		SET last_value = order_sequences.last_value + 1,
+		`INSERT INTO order_sequences (location_id, date, last_value) 
+		VALUES ($1, $2, $3)
+		ON CONFLICT (location_id, date) DO UPDATE 
+		SET last_value = order_sequences.last_value + $3,
 		    updated_at = NOW()
 		RETURNING last_value`,
+		locationID,
 		date,
+		n,
 	).Scan(&seq)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get/increment sequence: %w", err)
@@ -152,11 +219,27 @@ func (r *orderRepository) GetAndIncrementSequence(ctx context.Context, date stri
 		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	return seq, nil
+	return seq - n + 1, nil
 }
 
-// SetStatus updates order status and logs it in one transaction.
-func (r *orderRepository) SetStatus(ctx context.Context, orderNumber, workerName, status, notes string) (string, error) {
+// SetStatus updates order status of the location and logs it in one transaction.
+func (r *orderRepository) SetStatus(ctx context.Context, locationID, orderNumber, workerName, status, notes string) (string, error) {
+	return r.setStatus(ctx, locationID, orderNumber, workerName, status, notes, 0, 0, nil)
+}
+
+// StartCooking sets 'cooking' status if the order still has the given priority and version and is in the kitchen.
+// Otherwise the order was republished by aging or modification, or the message was published by a release or
+// payment transaction which did not commit (the order is still scheduled, maybe cancelled), and
+// models.ErrOrderSuperseded is returned. A 'cooking' order is taken again, like a message redelivered after
+// a worker failure.
+func (r *orderRepository) StartCooking(ctx context.Context, locationID, orderNumber, workerName string, priority, version int) (string, error) {
+	return r.setStatus(ctx, locationID, orderNumber, workerName, types.StatusOrderCooking, "", priority, version,
+		[]string{types.StatusOrderReceived, types.StatusOrderCooking})
+}
+
+// setStatus updates status and logs it. If priority or version is not 0, the order is updated only if it has them;
+// if from is not empty, only if it has one of these statuses.
+func (r *orderRepository) setStatus(ctx context.Context, locationID, orderNumber, workerName, status, notes string, priority, version int, from []string) (string, error) {
 	const op = "orderRepository.SetStatus"
 
 	tx, err := r.pool.Begin(ctx)
@@ -178,16 +261,24 @@ func (r *orderRepository) SetStatus(ctx context.Context, orderNumber, workerName
 	query += `
 	FROM orders AS old
 	WHERE o.id = old.id
-	  AND o.number = $3
-	RETURNING old.status AS old_status, o.id;`
+	  AND o.location_id = $3
+	  AND o.number = $4
+	  AND ($5 = 0 OR old.priority = $5)
+	  AND ($6 = 0 OR old.version = $6)
+	  AND (cardinality($7::text[]) = 0 OR old.status = ANY($7::text[]))
+	RETURNING old.status AS old_status, o.id, o.priority;`
 
 	var (
-		orderID   int
-		oldStatus string
+		orderID       int
+		oldStatus     string
+		orderPriority int
 	)
-	if err := tx.QueryRow(ctx, query, status, workerName, orderNumber).Scan(&oldStatus, &orderID); err != nil {
+	if err := tx.QueryRow(ctx, query, status, workerName, locationID, orderNumber, priority, version, from).Scan(&oldStatus, &orderID, &orderPriority); err != nil {
 		tx.Rollback(ctx)
 		if err == pgx.ErrNoRows {
+			if (priority != 0 || version != 0 || len(from) > 0) && r.exists(ctx, locationID, orderNumber) {
+				return "", models.ErrOrderSuperseded
+			}
 			return "", models.ErrOrderNotFound
 		}
 		return "", fmt.Errorf("%s: %v", op, err)
@@ -195,11 +286,11 @@ func (r *orderRepository) SetStatus(ctx context.Context, orderNumber, workerName
 
 	query = `
 		INSERT INTO
-			order_status_log (order_id, status, changed_by, notes)
+			order_status_log (order_id, status, changed_by, notes, priority)
 		VALUES
-			($1, $2, $3, $4);`
+			($1, $2, $3, $4, $5);`
 
-	if _, err := tx.Exec(ctx, query, orderID, status, workerName, notes); err != nil {
+	if _, err := tx.Exec(ctx, query, orderID, status, workerName, notes, orderPriority); err != nil {
 		tx.Rollback(ctx)
 		if err == pgx.ErrNoRows {
 			return "", models.ErrOrderNotFound
@@ -207,5 +298,227 @@ func (r *orderRepository) SetStatus(ctx context.Context, orderNumber, workerName
 		return "", fmt.Errorf("%s: %v", op, err)
 	}
 
+	// Items of orders cooked whole follow the order status
+	if status == types.StatusOrderCooking || status == types.StatusOrderReady {
+		if _, err := tx.Exec(ctx,
+			`UPDATE order_items SET status = $1, processed_by = $2, updated_at = now() WHERE order_id = $3 AND station IS NULL`,
+			status, workerName, orderID,
+		); err != nil {
+			tx.Rollback(ctx)
+			return "", fmt.Errorf("%s: %v", op, err)
+		}
+	}
+
+	// Reserved ingredients are used up when the order is cooked
+	if status == types.StatusOrderReady {
+		if err := consumeStock(ctx, tx, orderID); err != nil {
+			tx.Rollback(ctx)
+			return "", fmt.Errorf("%s: %v", op, err)
+		}
+	}
+
+	// Loyalty points are earned once, when the order becomes ready
+	if status == types.StatusOrderReady && oldStatus != types.StatusOrderReady {
+		if err := creditPoints(ctx, tx, orderID); err != nil {
+			tx.Rollback(ctx)
+			return "", fmt.Errorf("%s: %v", op, err)
+		}
+	}
+
 	return oldStatus, tx.Commit(ctx)
 }
+
+func (r *orderRepository) exists(ctx context.Context, locationID, orderNumber string) bool {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM orders WHERE location_id = $1 AND number = $2)`,
+		locationID, orderNumber,
+	).Scan(&exists)
+
+	return err == nil && exists
+}
+
+// orderColumns are selected by order queries, in the order of scanOrder.
+const orderColumns = `
+		id, created_at, updated_at, location_id, number, customer_name,
+		type, table_number, delivery_address, total_amount,
+		priority, status, processed_by, completed_at, scheduled_for, customer_id,
+		subtotal, points_earned, points_redeemed, tax_amount, service_charge, tip_amount,
+		delivery_zone, delivery_fee, version`
+
+func scanOrder(row pgx.Row, order *models.Order) error {
+	return row.Scan(
+		&order.ID,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+		&order.LocationID,
+		&order.Number,
+		&order.CustomerName,
+		&order.Type,
+		&order.TableNumber,
+		&order.DeliveryAddress,
+		&order.TotalAmount,
+		&order.Priority,
+		&order.Status,
+		&order.ProcessedBy,
+		&order.CompletedAt,
+		&order.ScheduledFor,
+		&order.CustomerID,
+		&order.Subtotal,
+		&order.PointsEarned,
+		&order.PointsRedeemed,
+		&order.TaxAmount,
+		&order.ServiceCharge,
+		&order.Tip,
+		&order.DeliveryZone,
+		&order.DeliveryFee,
+		&order.Version,
+	)
+}
+
+// GetByNumber returns order of the location with its items.
+func (r *orderRepository) GetByNumber(ctx context.Context, locationID, orderNumber string) (models.Order, error) {
+	const op = "orderRepository.GetByNumber"
+
+	query := `SELECT` + orderColumns + `
+	FROM 
+		orders
+	WHERE 
+		location_id = $1
+		AND number = $2;`
+
+	var order models.Order
+	if err := scanOrder(r.pool.QueryRow(ctx, query, locationID, orderNumber), &order); err != nil {
+		if err == pgx.ErrNoRows {
+			return models.Order{}, models.ErrOrderNotFound
+		}
+		return models.Order{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	query = `
+	SELECT 
+		id, created_at, order_id, name, quantity, price, station, category, tax_rate, status
+	FROM 
+		order_items
+	WHERE 
+		order_id = $1
+	ORDER BY 
+		id;`
+
+	rows, err := r.pool.Query(ctx, query, order.ID)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	order.Items, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.OrderItem, error) {
+		var item models.OrderItem
+		err := row.Scan(&item.ID, &item.CreatedAt, &item.OrderID, &item.Name, &item.Quantity, &item.Price, &item.Station, &item.Category, &item.TaxRate, &item.Status)
+		return item, err
+	})
+	if err != nil {
+		return models.Order{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	order.Discounts, err = orderDiscounts(ctx, r.pool, order.ID)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	order.Payment, err = orderPayment(ctx, r.pool, order.ID)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return order, nil
+}
+
+// List returns orders matching the filter, ordered by (created_at, id).
+// It returns at most filter.Limit orders, starting after filter.After.
+func (r *orderRepository) List(ctx context.Context, filter models.OrderFilter) ([]models.Order, error) {
+	const op = "orderRepository.List"
+
+	conds := []string{"true"}
+	var args []any
+
+	// where adds condition with the next placeholder number
+	where := func(cond string, arg ...any) {
+		placeholders := make([]any, len(arg))
+		for i := range arg {
+			placeholders[i] = len(args) + i + 1
+		}
+		conds = append(conds, fmt.Sprintf(cond, placeholders...))
+		args = append(args, arg...)
+	}
+
+	if filter.LocationID != "" {
+		where("location_id = $%d", filter.LocationID)
+	}
+	if filter.CustomerID != nil {
+		where("customer_id = $%d", *filter.CustomerID)
+	}
+	if filter.Status != "" {
+		where("status = $%d", filter.Status)
+	}
+	if filter.Type != "" {
+		where("type = $%d", filter.Type)
+	}
+	if filter.From != nil {
+		where("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		where("created_at < $%d", *filter.To)
+	}
+	if filter.CustomerPrefix != "" {
+		where("customer_name LIKE $%d", escapeLike(filter.CustomerPrefix)+"%")
+	}
+	if filter.Worker != "" {
+		where("processed_by = $%d", filter.Worker)
+	}
+	if filter.Priority != nil {
+		where("priority = $%d", *filter.Priority)
+	}
+
+	order := "DESC"
+	if filter.Asc {
+		order = "ASC"
+	}
+
+	if filter.After != nil {
+		if filter.Asc {
+			where("(created_at, id) > ($%d, $%d)", filter.After.CreatedAt, filter.After.ID)
+		} else {
+			where("(created_at, id) < ($%d, $%d)", filter.After.CreatedAt, filter.After.ID)
+		}
+	}
+
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf(`SELECT %s
+	FROM 
+		orders
+	WHERE 
+		%s
+	ORDER BY 
+		created_at %s, id %s
+	LIMIT $%d;`, orderColumns, strings.Join(conds, "\n\t\tAND "), order, order, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	orders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Order, error) {
+		var order models.Order
+		err := scanOrder(row, &order)
+		return order, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return orders, nil
+}
+
+// escapeLike escapes LIKE wildcards, so user input is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
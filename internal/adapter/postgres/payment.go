@@ -0,0 +1,351 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type paymentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPaymentRepo(pool *pgxpool.Pool) *paymentRepository {
+	return &paymentRepository{
+		pool: pool,
+	}
+}
+
+// paymentColumns are selected by payment queries joined with orders as o, in the order of scanPayment.
+const paymentColumns = `
+		p.id, p.order_id, o.location_id, o.number, p.status, p.amount, p.provider, p.provider_ref,
+		p.attempts, p.last_error, p.authorized_at, p.refunded_at, p.created_at, p.updated_at`
+
+func scanPayment(row pgx.Row, p *models.Payment) error {
+	return row.Scan(&p.ID, &p.OrderID, &p.LocationID, &p.OrderNumber, &p.Status, &p.Amount, &p.Provider, &p.ProviderRef,
+		&p.Attempts, &p.LastError, &p.AuthorizedAt, &p.RefundedAt, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// ClaimDue takes up to limit payments of the status whose next attempt is due and counts the attempt.
+// The payments are leased for lease: other payment-services skip them until it passes, so a payment
+// of a crashed payment-service is retried.
+func (r *paymentRepository) ClaimDue(ctx context.Context, status string, limit int, lease time.Duration) ([]models.Payment, error) {
+	const op = "paymentRepository.ClaimDue"
+
+	rows, err := r.pool.Query(ctx, `
+	WITH due AS (
+		SELECT
+			id
+		FROM
+			payments
+		WHERE
+			status = $1
+			AND next_attempt_at <= now()
+		ORDER BY
+			next_attempt_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE payments p
+	SET
+		attempts = p.attempts + 1,
+		next_attempt_at = now() + $3 * interval '1 millisecond',
+		updated_at = now()
+	FROM
+		due, orders o
+	WHERE
+		p.id = due.id
+		AND o.id = p.order_id
+	RETURNING`+paymentColumns, status, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	payments, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Payment, error) {
+		var p models.Payment
+		err := scanPayment(row, &p)
+		return p, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return payments, nil
+}
+
+// SaveAuthorization records the provider reference of the authorized payment, it still waits for Authorize
+// to move its order on. The payment of an order cancelled meanwhile is refunded.
+func (r *paymentRepository) SaveAuthorization(ctx context.Context, paymentID int, provider, providerRef string) error {
+	const op = "paymentRepository.SaveAuthorization"
+
+	if _, err := r.pool.Exec(ctx, `
+	UPDATE payments
+	SET
+		status = CASE status WHEN 'cancelled' THEN 'refund_pending' ELSE status END,
+		attempts = CASE status WHEN 'cancelled' THEN 0 ELSE attempts END,
+		next_attempt_at = CASE status WHEN 'cancelled' THEN now() ELSE next_attempt_at END,
+		provider = $1,
+		provider_ref = $2,
+		authorized_at = now(),
+		updated_at = now()
+	WHERE
+		id = $3
+		AND status IN ('pending', 'cancelled');`, provider, providerRef, paymentID); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	return nil
+}
+
+// Authorize records authorization of the payment and moves its order on: scheduled orders to 'scheduled',
+// others to 'received'. publish is called for received orders inside the transaction, so the order stays
+// pending if it could not be published. If the order was cancelled meanwhile, the payment is refunded.
+// Returns the new status of the order.
+func (r *paymentRepository) Authorize(ctx context.Context, paymentID int, provider, providerRef, changedBy string, publish func(order *models.CreateOrder) error) (string, error) {
+	const op = "paymentRepository.Authorize"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	ids, orders, err := lockOrders(ctx, tx, `
+	WHERE
+		id = (SELECT order_id FROM payments WHERE id = $1)`, paymentID)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+	if len(orders) == 0 {
+		return "", fmt.Errorf("%s: order of payment %d is locked or missing", op, paymentID)
+	}
+	orderID, order := ids[0], orders[0]
+
+	// Money is taken, but the order was cancelled while the provider answered. A payment already
+	// being refunded (or refunded) since its reference was saved is left alone.
+	if order.Status != types.StatusOrderPendingPayment {
+		if _, err := tx.Exec(ctx, `
+		UPDATE payments
+		SET
+			status = $1,
+			provider = $2,
+			provider_ref = $3,
+			authorized_at = COALESCE(authorized_at, now()),
+			attempts = 0,
+			next_attempt_at = now(),
+			updated_at = now()
+		WHERE
+			id = $4
+			AND status IN ('pending', 'cancelled');`, types.PaymentRefundPending, provider, providerRef, paymentID); err != nil {
+			return "", fmt.Errorf("%s: %v", op, err)
+		}
+		return order.Status, tx.Commit(ctx)
+	}
+
+	if _, err := tx.Exec(ctx, `
+	UPDATE payments
+	SET
+		status = $1,
+		provider = $2,
+		provider_ref = $3,
+		last_error = NULL,
+		authorized_at = COALESCE(authorized_at, now()),
+		updated_at = now()
+	WHERE
+		id = $4;`, types.PaymentAuthorized, provider, providerRef, paymentID); err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	order.Status = types.StatusOrderReceived
+	notes := "payment authorized, sent to the kitchen"
+	if order.ScheduledFor != nil {
+		order.Status = types.StatusOrderScheduled
+		notes = "payment authorized, waits for its scheduled time"
+	}
+
+	if _, err := tx.Exec(ctx, `
+	UPDATE orders
+	SET
+		status = $1,
+		queued_at = CASE WHEN $1 = 'received' THEN now() END,
+		updated_at = now()
+	WHERE
+		id = $2;`, order.Status, orderID); err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO order_status_log (order_id, status, changed_by, notes, priority) VALUES ($1, $2, $3, $4, $5)`,
+		orderID, order.Status, changedBy, notes, order.Priority,
+	); err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	if order.Status == types.StatusOrderReceived {
+		if err := publish(order); err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	return order.Status, nil
+}
+
+// Fail records a declined or failed payment and cancels its order, returning the reserved stock,
+// promo code use and redeemed loyalty points. A refund that is failed or not needed only changes the payment,
+// its order is already cancelled.
+func (r *paymentRepository) Fail(ctx context.Context, paymentID int, status, reason, changedBy string) error {
+	const op = "paymentRepository.Fail"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var orderID int
+	err = tx.QueryRow(ctx, `
+	UPDATE payments
+	SET
+		status = $1,
+		last_error = $2,
+		updated_at = now()
+	WHERE
+		id = $3
+		AND status IN ('pending', 'refund_pending')
+	RETURNING order_id;`, status, reason, paymentID).Scan(&orderID)
+	if err != nil {
+		// The order was cancelled meanwhile, the payment is already cancelled.
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	var priority int
+	err = tx.QueryRow(ctx, `
+	UPDATE orders
+	SET
+		status = $1,
+		updated_at = now()
+	WHERE
+		id = $2
+		AND status = $3
+	RETURNING priority;`, types.StatusOrderCancelled, orderID, types.StatusOrderPendingPayment).Scan(&priority)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tx.Commit(ctx)
+		}
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO order_status_log (order_id, status, changed_by, notes, priority) VALUES ($1, $2, $3, $4, $5)`,
+		orderID, types.StatusOrderCancelled, changedBy, "payment "+status+": "+reason, priority,
+	); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := releaseStock(ctx, tx, orderID); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := refundDiscounts(ctx, tx, orderID); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	return nil
+}
+
+// Retry records a failed attempt of the payment, it is tried again after delay.
+func (r *paymentRepository) Retry(ctx context.Context, paymentID int, reason string, delay time.Duration) error {
+	const op = "paymentRepository.Retry"
+
+	if _, err := r.pool.Exec(ctx, `
+	UPDATE payments
+	SET
+		last_error = $1,
+		next_attempt_at = now() + $2 * interval '1 millisecond',
+		updated_at = now()
+	WHERE
+		id = $3;`, reason, delay.Milliseconds(), paymentID); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	return nil
+}
+
+// Refunded records refund of the payment.
+func (r *paymentRepository) Refunded(ctx context.Context, paymentID int) error {
+	const op = "paymentRepository.Refunded"
+
+	if _, err := r.pool.Exec(ctx, `
+	UPDATE payments
+	SET
+		status = $1,
+		last_error = NULL,
+		refunded_at = now(),
+		updated_at = now()
+	WHERE
+		id = $2
+		AND status = $3;`, types.PaymentRefunded, paymentID, types.PaymentRefundPending); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	return nil
+}
+
+// createPayment adds pending payment of a new order.
+func createPayment(ctx context.Context, tx pgx.Tx, orderID int, amount float64) error {
+	_, err := tx.Exec(ctx, `INSERT INTO payments (order_id, amount) VALUES ($1, $2)`, orderID, amount)
+	return err
+}
+
+// cancelPayment cancels payment of a cancelled order. A payment the provider has authorized is refunded
+// by payment-service, even if its order was not moved on yet.
+func cancelPayment(ctx context.Context, tx pgx.Tx, orderID int) error {
+	_, err := tx.Exec(ctx, `
+	UPDATE payments
+	SET
+		status = CASE WHEN provider_ref IS NOT NULL THEN 'refund_pending' ELSE 'cancelled' END,
+		attempts = 0,
+		next_attempt_at = now(),
+		updated_at = now()
+	WHERE
+		order_id = $1
+		AND status IN ('pending', 'authorized');`, orderID)
+	return err
+}
+
+// orderPayment returns payment of the order, nil if it has none.
+func orderPayment(ctx context.Context, pool *pgxpool.Pool, orderID int) (*models.Payment, error) {
+	var p models.Payment
+	err := scanPayment(pool.QueryRow(ctx, `
+	SELECT`+paymentColumns+`
+	FROM
+		payments p
+	JOIN orders o ON o.id = p.order_id
+	WHERE
+		p.order_id = $1;`, orderID), &p)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
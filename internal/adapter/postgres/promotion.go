@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type promotionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPromotionRepo(pool *pgxpool.Pool) *promotionRepository {
+	return &promotionRepository{
+		pool: pool,
+	}
+}
+
+const promotionColumns = `
+		id, code, kind, value, item_name, buy_quantity, get_quantity,
+		max_uses, uses, starts_at, expires_at, created_at`
+
+func scanPromotion(row pgx.Row, p *models.Promotion) error {
+	return row.Scan(&p.ID, &p.Code, &p.Kind, &p.Value, &p.ItemName, &p.BuyQuantity, &p.GetQuantity,
+		&p.MaxUses, &p.Uses, &p.StartsAt, &p.ExpiresAt, &p.CreatedAt)
+}
+
+// Create stores a new promotion. Returns models.ErrPromotionExists if the code is taken.
+func (r *promotionRepository) Create(ctx context.Context, p models.Promotion) (models.Promotion, error) {
+	const op = "promotionRepository.Create"
+
+	var promotion models.Promotion
+	err := scanPromotion(r.pool.QueryRow(ctx, `
+	INSERT INTO promotions (code, kind, value, item_name, buy_quantity, get_quantity, max_uses, starts_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING`+promotionColumns,
+		p.Code, p.Kind, p.Value, p.ItemName, p.BuyQuantity, p.GetQuantity, p.MaxUses, p.StartsAt, p.ExpiresAt,
+	), &promotion)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.Promotion{}, models.ErrPromotionExists
+		}
+		return models.Promotion{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return promotion, nil
+}
+
+// GetByCode returns promotion by upper case code.
+func (r *promotionRepository) GetByCode(ctx context.Context, code string) (models.Promotion, error) {
+	const op = "promotionRepository.GetByCode"
+
+	var promotion models.Promotion
+	err := scanPromotion(r.pool.QueryRow(ctx, `
+	SELECT`+promotionColumns+`
+	FROM
+		promotions
+	WHERE
+		code = $1;`, code), &promotion)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Promotion{}, models.ErrPromotionNotFound
+		}
+		return models.Promotion{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return promotion, nil
+}
+
+// List returns all promotions, newest first.
+func (r *promotionRepository) List(ctx context.Context) ([]models.Promotion, error) {
+	const op = "promotionRepository.List"
+
+	rows, err := r.pool.Query(ctx, `
+	SELECT`+promotionColumns+`
+	FROM
+		promotions
+	ORDER BY
+		created_at DESC, id DESC;`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	promotions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Promotion, error) {
+		var p models.Promotion
+		err := scanPromotion(row, &p)
+		return p, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return promotions, nil
+}
+
+// applyDiscounts stores discounts of a new order, counts use of its promo code and takes redeemed
+// loyalty points from the customer. Returns models.ErrPromotionExhausted or models.ErrNotEnoughPoints.
+func applyDiscounts(ctx context.Context, tx pgx.Tx, orderID int, customerID *int, points int, discounts []models.Discount) error {
+	for _, d := range discounts {
+		if d.PromotionID != nil {
+			tag, err := tx.Exec(ctx, `
+			UPDATE promotions
+			SET uses = uses + 1
+			WHERE
+				id = $1
+				AND (max_uses IS NULL OR uses < max_uses)
+				AND (expires_at IS NULL OR expires_at > now());`, *d.PromotionID)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return models.ErrPromotionExhausted
+			}
+		}
+	}
+
+	if err := insertDiscounts(ctx, tx, orderID, discounts); err != nil {
+		return err
+	}
+
+	if points > 0 {
+		if customerID == nil {
+			return models.ErrNotEnoughPoints
+		}
+
+		tag, err := tx.Exec(ctx, `
+		UPDATE customers
+		SET
+			loyalty_points = loyalty_points - $1,
+			updated_at = now()
+		WHERE
+			id = $2
+			AND loyalty_points >= $1;`, points, *customerID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return models.ErrNotEnoughPoints
+		}
+	}
+
+	return nil
+}
+
+func insertDiscounts(ctx context.Context, tx pgx.Tx, orderID int, discounts []models.Discount) error {
+	for _, d := range discounts {
+		if _, err := tx.Exec(ctx, `
+		INSERT INTO order_discounts (order_id, kind, promotion_id, code, item_name, description, amount)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7);`,
+			orderID, d.Kind, d.PromotionID, d.Code, d.ItemName, d.Description, d.Amount,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refundDiscounts returns promo code use and redeemed loyalty points of a cancelled order.
+func refundDiscounts(ctx context.Context, tx pgx.Tx, orderID int) error {
+	if _, err := tx.Exec(ctx, `
+	UPDATE promotions p
+	SET uses = p.uses - 1
+	FROM order_discounts d
+	WHERE
+		d.order_id = $1
+		AND d.promotion_id = p.id
+		AND p.uses > 0;`, orderID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+	UPDATE customers c
+	SET
+		loyalty_points = c.loyalty_points + o.points_redeemed,
+		updated_at = now()
+	FROM orders o
+	WHERE
+		o.id = $1
+		AND c.id = o.customer_id
+		AND o.points_redeemed > 0;`, orderID)
+	return err
+}
+
+// creditPoints credits loyalty points earned by the order to its customer.
+func creditPoints(ctx context.Context, tx pgx.Tx, orderID int) error {
+	_, err := tx.Exec(ctx, `
+	UPDATE customers c
+	SET
+		loyalty_points = c.loyalty_points + o.points_earned,
+		updated_at = now()
+	FROM orders o
+	WHERE
+		o.id = $1
+		AND c.id = o.customer_id
+		AND o.points_earned > 0;`, orderID)
+	return err
+}
+
+// orderDiscounts returns discount breakdown of the order.
+func orderDiscounts(ctx context.Context, pool *pgxpool.Pool, orderID int) ([]models.Discount, error) {
+	rows, err := pool.Query(ctx, `
+	SELECT
+		kind, promotion_id, COALESCE(code, ''), item_name, description, amount
+	FROM
+		order_discounts
+	WHERE
+		order_id = $1
+	ORDER BY
+		id;`, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Discount, error) {
+		var d models.Discount
+		err := row.Scan(&d.Kind, &d.PromotionID, &d.Code, &d.ItemName, &d.Description, &d.Amount)
+		return d, err
+	})
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// ReleaseDue moves up to limit scheduled orders whose release time has come to 'received' and logs it.
+//
+// publish is called for every released order inside the transaction, so the order stays scheduled if it
+// could not be published. If the commit fails after publishing, kitchen workers skip the message while the
+// order is not 'received' (see StartCooking). Orders locked by another order-service are skipped.
+func (r *orderRepository) ReleaseDue(ctx context.Context, changedBy string, limit int, publish func(order *models.CreateOrder) error) (int, error) {
+	const op = "orderRepository.ReleaseDue"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	ids, orders, err := lockOrders(ctx, tx, `
+	WHERE
+		status = 'scheduled'
+		AND release_at <= now()
+	ORDER BY
+		release_at
+	LIMIT $1`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+
+	for i, o := range orders {
+		o.Status = types.StatusOrderReceived
+
+		if _, err := tx.Exec(ctx,
+			`UPDATE orders SET status = $1, queued_at = now(), updated_at = now() WHERE id = $2`,
+			types.StatusOrderReceived, ids[i],
+		); err != nil {
+			return 0, fmt.Errorf("%s: %v", op, err)
+		}
+
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO order_status_log (order_id, status, changed_by, notes, priority) VALUES ($1, $2, $3, $4, $5)`,
+			ids[i], types.StatusOrderReceived, changedBy, "released to the kitchen", o.Priority,
+		); err != nil {
+			return 0, fmt.Errorf("%s: %v", op, err)
+		}
+
+		if err := publish(o); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return len(orders), nil
+}
+
+// CancelScheduled cancels the scheduled or waiting for payment order of the location. Orders already released
+// to the kitchen can't be cancelled, models.ErrOrderNotCancellable is returned for them.
+// An authorized payment of the order is left for payment-service to refund.
+func (r *orderRepository) CancelScheduled(ctx context.Context, locationID, orderNumber, changedBy, notes string) error {
+	const op = "orderRepository.CancelScheduled"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	orderID, status, priority, _, err := lockOrder(ctx, tx, locationID, orderNumber)
+	if err != nil {
+		return err
+	}
+
+	if status != types.StatusOrderScheduled && status != types.StatusOrderPendingPayment {
+		return models.ErrOrderNotCancellable
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE orders SET status = $1, updated_at = now() WHERE id = $2`,
+		types.StatusOrderCancelled, orderID,
+	); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO order_status_log (order_id, status, changed_by, notes, priority) VALUES ($1, $2, $3, $4, $5)`,
+		orderID, types.StatusOrderCancelled, changedBy, notes, priority,
+	); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := releaseStock(ctx, tx, orderID); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := refundDiscounts(ctx, tx, orderID); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := cancelPayment(ctx, tx, orderID); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type shiftRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewShiftRepo(pool *pgxpool.Pool) *shiftRepository {
+	return &shiftRepository{
+		pool: pool,
+	}
+}
+
+const shiftColumns = `
+		worker_name, weekday, to_char(starts_at, 'HH24:MI'), to_char(ends_at, 'HH24:MI'), order_types`
+
+func scanShift(row pgx.CollectableRow) (models.Shift, error) {
+	var (
+		s          models.Shift
+		orderTypes string
+	)
+	if err := row.Scan(&s.WorkerName, &s.Weekday, &s.Start, &s.End, &orderTypes); err != nil {
+		return models.Shift{}, err
+	}
+	if orderTypes != "" {
+		s.OrderTypes = strings.Split(orderTypes, ",")
+	}
+	return s, nil
+}
+
+// List returns shifts of all workers of the location.
+func (r *shiftRepository) List(ctx context.Context, locationID string) ([]models.Shift, error) {
+	const op = "shiftRepository.List"
+
+	rows, err := r.pool.Query(ctx, `
+	SELECT`+shiftColumns+`
+	FROM
+		worker_shifts
+	WHERE
+		location_id = $1
+	ORDER BY
+		worker_name, weekday, starts_at;`, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	shifts, err := pgx.CollectRows(rows, scanShift)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return shifts, nil
+}
+
+// ListByWorker returns shifts of the worker of the location.
+func (r *shiftRepository) ListByWorker(ctx context.Context, locationID, workerName string) ([]models.Shift, error) {
+	const op = "shiftRepository.ListByWorker"
+
+	rows, err := r.pool.Query(ctx, `
+	SELECT`+shiftColumns+`
+	FROM
+		worker_shifts
+	WHERE
+		location_id = $1
+		AND worker_name = $2
+	ORDER BY
+		weekday, starts_at;`, locationID, workerName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	shifts, err := pgx.CollectRows(rows, scanShift)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return shifts, nil
+}
+
+// Replace replaces all shifts of the worker of the location. Empty shifts remove the schedule.
+func (r *shiftRepository) Replace(ctx context.Context, locationID, workerName string, shifts []models.Shift) error {
+	const op = "shiftRepository.Replace"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM worker_shifts WHERE location_id = $1 AND worker_name = $2;`, locationID, workerName); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	for _, s := range shifts {
+		if _, err := tx.Exec(ctx, `
+		INSERT INTO worker_shifts (location_id, worker_name, weekday, starts_at, ends_at, order_types)
+		VALUES ($1, $2, $3, $4::time, $5::time, $6);`,
+			locationID, workerName, int(s.Weekday), s.Start, s.End, strings.Join(s.OrderTypes, ","),
+		); err != nil {
+			return fmt.Errorf("%s: %v", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	return nil
+}
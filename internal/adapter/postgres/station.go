@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/jackc/pgx/v5"
+)
+
+// StartStation sets 'cooking' status of the station items of the order. The first started station moves
+// the order to 'cooking' too. Old status of the order is returned. Items still cooking are taken again,
+// so a message redelivered after a worker failure is cooked by the next worker.
+func (r *orderRepository) StartStation(ctx context.Context, locationID, orderNumber, station, workerName string, priority, version int) (string, error) {
+	const op = "orderRepository.StartStation"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	orderID, status, orderPriority, orderVersion, err := lockOrder(ctx, tx, locationID, orderNumber)
+	if err != nil {
+		return "", err
+	}
+
+	// Old copy of an order republished by aging or modification
+	if orderPriority != priority || (version != 0 && orderVersion != version) {
+		return "", models.ErrOrderSuperseded
+	}
+	// Message of a release or payment which did not commit, the order is not in the kitchen
+	if status != types.StatusOrderReceived && status != types.StatusOrderCooking {
+		return "", models.ErrOrderSuperseded
+	}
+
+	res, err := tx.Exec(ctx, `
+	UPDATE order_items
+	SET
+		status = $1,
+		processed_by = $2,
+		updated_at = now()
+	WHERE
+		order_id = $3
+		AND station = $4
+		AND status <> $5;`,
+		types.StatusOrderCooking, workerName, orderID, station, types.StatusOrderReady)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+	if res.RowsAffected() == 0 {
+		return "", models.ErrStationDone
+	}
+
+	if err := logStation(ctx, tx, orderID, station, types.StatusOrderCooking, workerName); err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	if status == types.StatusOrderReceived {
+		if _, err := tx.Exec(ctx,
+			`UPDATE orders SET status = $1, processed_by = $2, updated_at = now() WHERE id = $3`,
+			types.StatusOrderCooking, workerName, orderID,
+		); err != nil {
+			return "", fmt.Errorf("%s: %v", op, err)
+		}
+
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO order_status_log (order_id, status, changed_by, notes, priority) VALUES ($1, $2, $3, $4, $5)`,
+			orderID, types.StatusOrderCooking, workerName, "started at "+station, orderPriority,
+		); err != nil {
+			return "", fmt.Errorf("%s: %v", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	return status, nil
+}
+
+// FinishStation sets 'ready' status of the station items of the order. It aggregates stations of the order:
+// when every item is ready, the order becomes 'ready', its reserved stock is consumed and its old status is returned.
+// While other stations are cooking, the empty status is returned.
+func (r *orderRepository) FinishStation(ctx context.Context, locationID, orderNumber, station, workerName string) (string, error) {
+	const op = "orderRepository.FinishStation"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Order row lock serializes stations finishing at the same time, so exactly one of them completes the order.
+	orderID, status, priority, _, err := lockOrder(ctx, tx, locationID, orderNumber)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(ctx, `
+	UPDATE order_items
+	SET
+		status = $1,
+		processed_by = $2,
+		updated_at = now()
+	WHERE
+		order_id = $3
+		AND station = $4;`,
+		types.StatusOrderReady, workerName, orderID, station); err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := logStation(ctx, tx, orderID, station, types.StatusOrderReady, workerName); err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	var pending int
+	if err := tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM order_items WHERE order_id = $1 AND status <> $2`,
+		orderID, types.StatusOrderReady,
+	).Scan(&pending); err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	oldStatus := ""
+	if pending == 0 && status != types.StatusOrderReady {
+		if _, err := tx.Exec(ctx,
+			`UPDATE orders SET status = $1, completed_at = now(), updated_at = now() WHERE id = $2`,
+			types.StatusOrderReady, orderID,
+		); err != nil {
+			return "", fmt.Errorf("%s: %v", op, err)
+		}
+
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO order_status_log (order_id, status, changed_by, notes, priority) VALUES ($1, $2, $3, $4, $5)`,
+			orderID, types.StatusOrderReady, workerName, "all stations ready", priority,
+		); err != nil {
+			return "", fmt.Errorf("%s: %v", op, err)
+		}
+
+		if err := consumeStock(ctx, tx, orderID); err != nil {
+			return "", fmt.Errorf("%s: %v", op, err)
+		}
+
+		if err := creditPoints(ctx, tx, orderID); err != nil {
+			return "", fmt.Errorf("%s: %v", op, err)
+		}
+		oldStatus = status
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	return oldStatus, nil
+}
+
+// lockOrder locks the order of the location until the end of the transaction and returns its id, status,
+// priority and version.
+func lockOrder(ctx context.Context, tx pgx.Tx, locationID, orderNumber string) (int, string, int, int, error) {
+	var (
+		id       int
+		status   string
+		priority int
+		version  int
+	)
+	err := tx.QueryRow(ctx, `
+	SELECT
+		id, status, priority, version
+	FROM
+		orders
+	WHERE
+		location_id = $1
+		AND number = $2
+	FOR UPDATE;`, locationID, orderNumber).Scan(&id, &status, &priority, &version)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, "", 0, 0, models.ErrOrderNotFound
+		}
+		return 0, "", 0, 0, fmt.Errorf("lockOrder: %v", err)
+	}
+
+	return id, status, priority, version, nil
+}
+
+func logStation(ctx context.Context, tx pgx.Tx, orderID int, station, status, changedBy string) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO station_status_log (order_id, station, status, changed_by) VALUES ($1, $2, $3, $4)`,
+		orderID, station, status, changedBy,
+	)
+	return err
+}
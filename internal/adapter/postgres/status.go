@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
 	"github.com/jackc/pgx/v5"
@@ -19,58 +20,101 @@ func NewStatusRepo(pool *pgxpool.Pool) *statusRepository {
 	}
 }
 
-func (repo *statusRepository) GetCurrent(ctx context.Context, orderNumber string) (models.OrderStatus, error) {
+func (repo *statusRepository) GetCurrent(ctx context.Context, locationID, orderNumber string) (models.OrderStatus, error) {
 	const op = "statusRepository.GetCurrent"
 	query := `
 	SELECT 
+		o.location_id,
 		o.number,
 		COALESCE(s.status,''),
 		s.changed_at,
 		o.completed_at,
-		o.processed_by  
+		o.processed_by,
+		o.type,
+		COALESCE(o.queued_at, o.created_at),
+		o.scheduled_for,
+		(SELECT p.status FROM payments p WHERE p.order_id = o.id),
+		o.travel_seconds
 	FROM 
 		order_status_log s
 	INNER JOIN orders o on s.order_id = o.id
 	WHERE 
-		o.number = $1
+		o.location_id = $1
+		AND o.number = $2
 	ORDER BY 
 		s.created_at DESC;`
 
-	var statusInfo models.OrderStatus
+	var (
+		statusInfo    models.OrderStatus
+		travelSeconds int
+	)
 
-	if err := repo.pool.QueryRow(ctx, query, orderNumber).
-		Scan(&statusInfo.OrderNumber, &statusInfo.Status, &statusInfo.UpdatedAt, &statusInfo.Completion, &statusInfo.ProcessedBy); err != nil {
+	if err := repo.pool.QueryRow(ctx, query, locationID, orderNumber).
+		Scan(&statusInfo.LocationID, &statusInfo.OrderNumber, &statusInfo.Status, &statusInfo.UpdatedAt, &statusInfo.Completion, &statusInfo.ProcessedBy,
+			&statusInfo.OrderType, &statusInfo.QueuedAt, &statusInfo.ScheduledFor, &statusInfo.PaymentStatus, &travelSeconds); err != nil {
 		if err == pgx.ErrNoRows {
 			return models.OrderStatus{}, models.ErrOrderNotFound
 		}
 		return models.OrderStatus{}, fmt.Errorf("%s: %v", op, err)
 	}
+	statusInfo.TravelTime = time.Duration(travelSeconds) * time.Second
 
 	return statusInfo, nil
 }
 
-func (repo *statusRepository) ListOrderHistory(ctx context.Context, orderNumber string) ([]models.OrderHistory, error) {
+func (repo *statusRepository) ListOrderHistory(ctx context.Context, locationID, orderNumber string) ([]models.OrderHistory, error) {
 	const op = "statusRepository.ListStatusHistory"
 
+	// Order statuses with progress of its kitchen stations
 	query := `
-	SELECT 
-		COALESCE(s.status,''),
-		s.changed_at,
-		COALESCE(s.changed_by,'') 
-	FROM 
-		order_status_log s
-	INNER JOIN orders o ON s.order_id = o.id
-	WHERE 
-		o.number = $1;`
+	SELECT
+		status, changed_at, changed_by, priority, notes, station
+	FROM (
+		SELECT 
+			s.id,
+			0 AS kind,
+			COALESCE(s.status,'') AS status,
+			s.changed_at,
+			COALESCE(s.changed_by,'') AS changed_by,
+			COALESCE(s.priority, o.priority) AS priority,
+			COALESCE(s.notes,'') AS notes,
+			'' AS station
+		FROM 
+			order_status_log s
+		INNER JOIN orders o ON s.order_id = o.id
+		WHERE 
+			o.location_id = $1
+			AND o.number = $2
+
+		UNION ALL
+
+		SELECT
+			l.id,
+			1 AS kind,
+			l.status,
+			l.changed_at,
+			COALESCE(l.changed_by,''),
+			o.priority,
+			'',
+			l.station
+		FROM
+			station_status_log l
+		INNER JOIN orders o ON l.order_id = o.id
+		WHERE
+			o.location_id = $1
+			AND o.number = $2
+	) h
+	ORDER BY
+		changed_at, kind, id;`
 
-	rows, err := repo.pool.Query(ctx, query, orderNumber)
+	rows, err := repo.pool.Query(ctx, query, locationID, orderNumber)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %v", op, err)
 	}
 
 	historyList, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.OrderHistory, error) {
 		var history models.OrderHistory
-		if err := row.Scan(&history.Status, &history.Timestamp, &history.ChangedBy); err != nil {
+		if err := row.Scan(&history.Status, &history.Timestamp, &history.ChangedBy, &history.Priority, &history.Notes, &history.Station); err != nil {
 			return models.OrderHistory{}, err
 		}
 		return history, nil
@@ -0,0 +1,361 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type tableRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTableRepo(pool *pgxpool.Pool) *tableRepository {
+	return &tableRepository{
+		pool: pool,
+	}
+}
+
+// tableColumns are selected by table queries with the open session, in the order of scanTable.
+const tableColumns = `
+		t.id, t.location_id, t.number, t.capacity, t.status, t.updated_at,
+		s.id, s.guests, s.opened_at`
+
+func scanTable(row pgx.Row, t *models.Table) error {
+	var (
+		sessionID, guests *int
+		openedAt          *time.Time
+	)
+	if err := row.Scan(&t.ID, &t.LocationID, &t.Number, &t.Capacity, &t.Status, &t.UpdatedAt, &sessionID, &guests, &openedAt); err != nil {
+		return err
+	}
+
+	t.Session = nil
+	if sessionID != nil {
+		t.Session = &models.TableSession{ID: *sessionID, Guests: *guests, OpenedAt: *openedAt}
+	}
+	return nil
+}
+
+// Create stores a new table. Returns models.ErrTableExists if the location already has a table of the number.
+func (r *tableRepository) Create(ctx context.Context, t models.Table) (models.Table, error) {
+	const op = "tableRepository.Create"
+
+	var table models.Table
+	err := r.pool.QueryRow(ctx, `
+	INSERT INTO tables (location_id, number, capacity)
+	VALUES ($1, $2, $3)
+	RETURNING
+		id, location_id, number, capacity, status, updated_at`,
+		t.LocationID, t.Number, t.Capacity,
+	).Scan(&table.ID, &table.LocationID, &table.Number, &table.Capacity, &table.Status, &table.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.Table{}, models.ErrTableExists
+		}
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return table, nil
+}
+
+// List returns tables of the location by number, with open sessions. Orders of the sessions are loaded if withOrders is set.
+func (r *tableRepository) List(ctx context.Context, locationID string, withOrders bool) ([]models.Table, error) {
+	const op = "tableRepository.List"
+
+	rows, err := r.pool.Query(ctx, `
+	SELECT`+tableColumns+`
+	FROM
+		tables t
+	LEFT JOIN table_sessions s ON s.table_id = t.id AND s.closed_at IS NULL
+	WHERE
+		t.location_id = $1
+	ORDER BY
+		t.number;`, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	tables, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Table, error) {
+		var t models.Table
+		err := scanTable(row, &t)
+		return t, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if withOrders {
+		for i := range tables {
+			if tables[i].Session == nil {
+				continue
+			}
+			if tables[i].Session.Orders, err = sessionOrders(ctx, r.pool, tables[i].Session.ID); err != nil {
+				return nil, fmt.Errorf("%s: %v", op, err)
+			}
+		}
+	}
+
+	return tables, nil
+}
+
+// Open seats guests at a free table and opens its session.
+func (r *tableRepository) Open(ctx context.Context, locationID string, number, guests int) (models.Table, error) {
+	const op = "tableRepository.Open"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	table, err := lockTable(ctx, tx, locationID, number)
+	if err != nil {
+		return models.Table{}, err
+	}
+
+	if table.Status != types.TableFree {
+		return models.Table{}, models.ErrTableNotFree
+	}
+	if guests > table.Capacity {
+		return models.Table{}, models.ErrTableCapacity
+	}
+
+	session := models.TableSession{Guests: guests}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO table_sessions (table_id, guests) VALUES ($1, $2) RETURNING id, opened_at`,
+		table.ID, guests,
+	).Scan(&session.ID, &session.OpenedAt); err != nil {
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := setTableStatus(ctx, tx, &table, types.TableOccupied); err != nil {
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+	table.Session = &session
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return table, nil
+}
+
+// Close closes the open session of the table and returns its combined bill. The table needs cleaning afterwards.
+// Dine-in orders can't be placed on the table until it is opened again.
+func (r *tableRepository) Close(ctx context.Context, locationID string, number int) (models.TableBill, error) {
+	const op = "tableRepository.Close"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return models.TableBill{}, fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	table, err := lockTable(ctx, tx, locationID, number)
+	if err != nil {
+		return models.TableBill{}, err
+	}
+
+	if table.Session == nil {
+		return models.TableBill{}, models.ErrTableClosed
+	}
+	session := *table.Session
+
+	if err := tx.QueryRow(ctx,
+		`UPDATE table_sessions SET closed_at = now() WHERE id = $1 RETURNING closed_at`,
+		session.ID,
+	).Scan(&session.ClosedAt); err != nil {
+		return models.TableBill{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := setTableStatus(ctx, tx, &table, types.TableNeedsCleaning); err != nil {
+		return models.TableBill{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if session.Orders, err = sessionOrders(ctx, tx, session.ID); err != nil {
+		return models.TableBill{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.TableBill{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return models.NewTableBill(table, session), nil
+}
+
+// Clean marks the table of a closed session free.
+func (r *tableRepository) Clean(ctx context.Context, locationID string, number int) (models.Table, error) {
+	const op = "tableRepository.Clean"
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	table, err := lockTable(ctx, tx, locationID, number)
+	if err != nil {
+		return models.Table{}, err
+	}
+
+	if table.Status != types.TableNeedsCleaning {
+		return models.Table{}, models.ErrTableNotCleaning
+	}
+
+	if err := setTableStatus(ctx, tx, &table, types.TableFree); err != nil {
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return table, nil
+}
+
+// Bill returns the bill of the open session of the table, or of its last session if the table is not occupied.
+func (r *tableRepository) Bill(ctx context.Context, locationID string, number int) (models.TableBill, error) {
+	const op = "tableRepository.Bill"
+
+	var (
+		table             models.Table
+		sessionID, guests *int
+		openedAt          *time.Time
+		closedAt          *time.Time
+	)
+	err := r.pool.QueryRow(ctx, `
+	SELECT
+		t.id, t.location_id, t.number, s.id, s.guests, s.opened_at, s.closed_at
+	FROM
+		tables t
+	LEFT JOIN LATERAL (
+		SELECT * FROM table_sessions WHERE table_id = t.id ORDER BY opened_at DESC LIMIT 1
+	) s ON true
+	WHERE
+		t.location_id = $1
+		AND t.number = $2;`, locationID, number,
+	).Scan(&table.ID, &table.LocationID, &table.Number, &sessionID, &guests, &openedAt, &closedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TableBill{}, models.ErrTableNotFound
+		}
+		return models.TableBill{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if sessionID == nil {
+		return models.TableBill{}, models.ErrTableNoSessions
+	}
+	session := models.TableSession{ID: *sessionID, Guests: *guests, OpenedAt: *openedAt, ClosedAt: closedAt}
+
+	if session.Orders, err = sessionOrders(ctx, r.pool, session.ID); err != nil {
+		return models.TableBill{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return models.NewTableBill(table, session), nil
+}
+
+// lockTable locks the table of the location for update and returns it with its open session.
+func lockTable(ctx context.Context, tx pgx.Tx, locationID string, number int) (models.Table, error) {
+	var table models.Table
+	err := scanTable(tx.QueryRow(ctx, `
+	SELECT`+tableColumns+`
+	FROM
+		tables t
+	LEFT JOIN table_sessions s ON s.table_id = t.id AND s.closed_at IS NULL
+	WHERE
+		t.location_id = $1
+		AND t.number = $2
+	FOR UPDATE OF t;`, locationID, number), &table)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Table{}, models.ErrTableNotFound
+		}
+		return models.Table{}, fmt.Errorf("lockTable: %v", err)
+	}
+
+	return table, nil
+}
+
+func setTableStatus(ctx context.Context, tx pgx.Tx, table *models.Table, status string) error {
+	table.Status = status
+	return tx.QueryRow(ctx,
+		`UPDATE tables SET status = $1, updated_at = now() WHERE id = $2 RETURNING updated_at`,
+		status, table.ID,
+	).Scan(&table.UpdatedAt)
+}
+
+// querier is a pool or a transaction.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// sessionOrders returns orders of the table session, oldest first.
+func sessionOrders(ctx context.Context, q querier, sessionID int) ([]models.TableOrder, error) {
+	rows, err := q.Query(ctx, `
+	SELECT
+		o.number, o.status, o.subtotal,
+		COALESCE((SELECT sum(d.amount) FROM order_discounts d WHERE d.order_id = o.id), 0),
+		o.tax_amount, o.service_charge, o.tip_amount, o.total_amount, o.created_at
+	FROM
+		orders o
+	WHERE
+		o.table_session_id = $1
+	ORDER BY
+		o.created_at;`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.TableOrder, error) {
+		var o models.TableOrder
+		err := row.Scan(&o.Number, &o.Status, &o.Subtotal, &o.Discounts, &o.TaxAmount, &o.ServiceCharge, &o.Tip, &o.TotalAmount, &o.CreatedAt)
+		return o, err
+	})
+}
+
+// attachTable returns the open session of the table a dine-in order is placed on, locked until the order is stored
+// so the session is not closed meanwhile. Locations without tables accept any table number, nil is returned for them.
+func attachTable(ctx context.Context, tx pgx.Tx, locationID string, number int) (*int, error) {
+	var (
+		tableID   int
+		sessionID *int
+	)
+	err := tx.QueryRow(ctx, `
+	SELECT
+		t.id, s.id
+	FROM
+		tables t
+	LEFT JOIN table_sessions s ON s.table_id = t.id AND s.closed_at IS NULL
+	WHERE
+		t.location_id = $1
+		AND t.number = $2
+	FOR SHARE OF t;`, locationID, number).Scan(&tableID, &sessionID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+
+		var tracked bool
+		if err := tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM tables WHERE location_id = $1)`, locationID,
+		).Scan(&tracked); err != nil {
+			return nil, err
+		}
+		if tracked {
+			return nil, models.ErrTableNotFound
+		}
+		return nil, nil
+	}
+
+	if sessionID == nil {
+		return nil, models.ErrTableClosed
+	}
+	return sessionID, nil
+}
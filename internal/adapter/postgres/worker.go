@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -20,30 +21,27 @@ func NewWorkerRepo(pool *pgxpool.Pool) *workerRepository {
 	}
 }
 
-func (repo *workerRepository) List(ctx context.Context) ([]models.Worker, error) {
+const workerColumns = `
+		name, location_id, status, type, stations, command, orders_processed, active_orders,
+		current_order, version, host, pid, heartbeat_interval, last_seen`
+
+// List returns workers of the location.
+func (repo *workerRepository) List(ctx context.Context, locationID string) ([]models.Worker, error) {
 	const op = "workerRepository.List"
 
 	query := `
-	SELECT 
-		name,
-		status,
-		orders_processed,
-		last_seen
+	SELECT` + workerColumns + `
 	FROM 
-		workers;`
+		workers
+	WHERE
+		location_id = $1;`
 
-	rows, err := repo.pool.Query(ctx, query)
+	rows, err := repo.pool.Query(ctx, query, locationID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %v", op, err)
 	}
 
-	workers, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Worker, error) {
-		var worker models.Worker
-		if err := row.Scan(&worker.Name, &worker.Status, &worker.ProcessedOrders, &worker.LastSeen); err != nil {
-			return models.Worker{}, err
-		}
-		return worker, nil
-	})
+	workers, err := pgx.CollectRows(rows, scanWorker)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %v", op, err)
 	}
@@ -54,76 +52,358 @@ func (repo *workerRepository) List(ctx context.Context) ([]models.Worker, error)
 	return workers, nil
 }
 
-// MarkOnline marks a worker as online by inserting or updating its record.
+// Get returns the worker of the location.
+func (repo *workerRepository) Get(ctx context.Context, locationID, name string) (models.Worker, error) {
+	const op = "workerRepository.Get"
+
+	query := `
+	SELECT` + workerColumns + `
+	FROM 
+		workers
+	WHERE
+		location_id = $1
+		AND name = $2;`
+
+	rows, err := repo.pool.Query(ctx, query, locationID, name)
+	if err != nil {
+		return models.Worker{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	worker, err := pgx.CollectExactlyOneRow(rows, scanWorker)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Worker{}, models.ErrWorkerNotFound
+		}
+		return models.Worker{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return worker, nil
+}
+
+// Sessions returns latest online periods of the worker, newest first.
+func (repo *workerRepository) Sessions(ctx context.Context, locationID, name string, limit int) ([]models.WorkerSession, error) {
+	const op = "workerRepository.Sessions"
+
+	query := `
+	SELECT
+		id, version, host, pid, order_types, stations, started_at, ended_at, end_reason, orders_processed
+	FROM 
+		worker_sessions
+	WHERE
+		location_id = $1
+		AND worker_name = $2
+	ORDER BY
+		started_at DESC
+	LIMIT $3;`
+
+	rows, err := repo.pool.Query(ctx, query, locationID, name, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	sessions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WorkerSession, error) {
+		var s models.WorkerSession
+		err := row.Scan(&s.ID, &s.Version, &s.Host, &s.PID, &s.OrderTypes, &s.Stations, &s.StartedAt, &s.EndedAt, &s.EndReason, &s.ProcessedOrders)
+		return s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return sessions, nil
+}
+
+// EndTimedOutSessions ends open sessions of workers of the location not seen for two of their heartbeat
+// intervals, or for timeout if they did not report one, as timed out when the worker was last seen.
+func (repo *workerRepository) EndTimedOutSessions(ctx context.Context, locationID string, timeout time.Duration) error {
+	const op = "workerRepository.EndTimedOutSessions"
+
+	query := `
+		UPDATE 
+			worker_sessions s
+		SET 
+			ended_at = w.last_seen,
+			end_reason = 'heartbeat_timeout'
+		FROM 
+			workers w
+		WHERE 
+			w.location_id = s.location_id
+			AND w.name = s.worker_name
+			AND s.location_id = $1
+			AND s.ended_at IS NULL
+			AND w.last_seen < now() - CASE
+				WHEN w.heartbeat_interval > 0 THEN make_interval(secs => 2 * w.heartbeat_interval)
+				ELSE make_interval(secs => $2)
+			END;`
+
+	if _, err := repo.pool.Exec(ctx, query, locationID, timeout.Seconds()); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	return nil
+}
+
+// MarkOnline marks a worker as online by inserting or updating its record and opens a new session.
 // If the worker already exists and is online but last_seen is recent (within heartbeat), registration fails.
-// if worker marker 'online' worker still will be successfully marked if last_seen < Now() - heartbeat * 2
-func (repo *workerRepository) MarkOnline(ctx context.Context, name, orderTypes string, heartbeat time.Duration) error {
+// if worker marker 'online' worker still will be successfully marked if last_seen < Now() - heartbeat * 2,
+// then its previous session is closed as timed out. Returns id of the new session.
+func (repo *workerRepository) MarkOnline(ctx context.Context, locationID, name string, hb models.Heartbeat) (int, error) {
 	const op = "workerRepository.MarkOnline"
 
+	tx, err := repo.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Session left open by a worker which stopped heartbeating, it ended when the worker was last seen.
+	// Rolled back if the worker is still online.
+	if _, err := tx.Exec(ctx, `
+		UPDATE 
+			worker_sessions s
+		SET 
+			ended_at = w.last_seen,
+			end_reason = 'heartbeat_timeout'
+		FROM 
+			workers w
+		WHERE 
+			w.location_id = s.location_id
+			AND w.name = s.worker_name
+			AND s.location_id = $1
+			AND s.worker_name = $2
+			AND s.ended_at IS NULL;`, locationID, name); err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+
 	query := `
-		INSERT INTO workers (name, type, status, last_seen)
-		VALUES ($1, $2, 'online', now())
-		ON CONFLICT (name)
+		INSERT INTO workers (location_id, name, type, stations, status, version, host, pid, heartbeat_interval, last_seen)
+		VALUES ($1, $2, $3, $5, 'online', $6, $7, $8, $9, now())
+		ON CONFLICT (location_id, name)
 		DO UPDATE
 		SET 
 			status = 'online',
-			type = $2,
+			type = $3,
+			stations = $5,
+			command = NULL,
+			command_at = NULL,
+			version = $6,
+			host = $7,
+			pid = $8,
+			heartbeat_interval = $9,
+			active_orders = 0,
+			current_order = NULL,
 			last_seen = now()
 		WHERE 
-			workers.name = $1
+			workers.location_id = $1
+			AND workers.name = $2
 			AND (
 				workers.status = 'offline' 
-				OR workers.last_seen < now() - make_interval(secs => $3)
+				OR workers.last_seen < now() - make_interval(secs => $4)
 			);
 		`
 
-	res, err := repo.pool.Exec(ctx, query, name, orderTypes, int64(heartbeat.Seconds())*2)
+	res, err := tx.Exec(ctx, query, locationID, name, hb.OrderTypes, int64(hb.Interval.Seconds())*2, hb.Stations,
+		hb.Version, hb.Host, hb.PID, int64(hb.Interval.Seconds()))
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
 	if res.RowsAffected() == 0 {
-		return fmt.Errorf("%s: %v", op, models.ErrWorkerAlreadyOnline)
+		return 0, fmt.Errorf("%s: %v", op, models.ErrWorkerAlreadyOnline)
+	}
+
+	var sessionID int
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO worker_sessions (location_id, worker_name, version, host, pid, order_types, stations)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id;`,
+		locationID, name, hb.Version, hb.Host, hb.PID, hb.OrderTypes, hb.Stations).Scan(&sessionID); err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return sessionID, nil
+}
+
+// Heartbeat updates last seen timestamp with the state reported by the worker instance of the session and returns
+// admin command waiting for the worker, if any. Returns models.ErrWorkerReplaced if a newer instance registered
+// under the name: its session is the latest one of the worker.
+func (repo *workerRepository) Heartbeat(ctx context.Context, locationID, name string, sessionID int, hb models.Heartbeat) (string, error) {
+	const op = "workerRepository.Heartbeat"
+
+	query := `
+		UPDATE 
+			workers
+		SET 
+			last_seen = now(),
+			type = $3,
+			stations = $4,
+			version = $5,
+			host = $6,
+			pid = $7,
+			heartbeat_interval = $8,
+			active_orders = $9,
+			current_order = NULLIF($10, '')
+		WHERE 
+			location_id = $1
+			AND name = $2
+			AND $11 = (SELECT max(id) FROM worker_sessions WHERE location_id = $1 AND worker_name = $2)
+		RETURNING
+			coalesce(command, '');`
+
+	var command string
+	if err := repo.pool.QueryRow(ctx, query, locationID, name, hb.OrderTypes, hb.Stations, hb.Version, hb.Host, hb.PID,
+		int64(hb.Interval.Seconds()), hb.ActiveOrders, hb.CurrentOrder, sessionID).Scan(&command); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if repo.exists(ctx, locationID, name) {
+				return "", models.ErrWorkerReplaced
+			}
+			return "", models.ErrWorkerNotFound
+		}
+		return "", fmt.Errorf("%s: %v", op, err)
+	}
+
+	return command, nil
+}
+
+// AckCommand clears the command picked up by the worker and reports the resulting worker status.
+// A newer command given meanwhile is kept.
+func (repo *workerRepository) AckCommand(ctx context.Context, locationID, name, command, status string) error {
+	const op = "workerRepository.AckCommand"
+
+	query := `
+		UPDATE 
+			workers
+		SET 
+			status = $4,
+			command = NULL,
+			command_at = NULL
+		WHERE 
+			location_id = $1
+			AND name = $2
+			AND command = $3;`
+
+	if _, err := repo.pool.Exec(ctx, query, locationID, name, command, status); err != nil {
+		return fmt.Errorf("%s: %v", op, err)
 	}
 
 	return nil
 }
 
-func (repo *workerRepository) UpdateLastSeen(ctx context.Context, name string) error {
-	const op = "workerRepository.UpdateLastSeen"
+// SetStatus reports status of the working worker, e.g. off duty outside its shifts.
+func (repo *workerRepository) SetStatus(ctx context.Context, locationID, name, status string) error {
+	const op = "workerRepository.SetStatus"
 
 	query := `
 		UPDATE 
 			workers
 		SET 
-			last_seen = now()
+			status = $3
 		WHERE 
-			name = $1;`
+			location_id = $1
+			AND name = $2;`
 
-	res, err := repo.pool.Exec(ctx, query, name)
+	res, err := repo.pool.Exec(ctx, query, locationID, name, status)
 	if err != nil {
 		return fmt.Errorf("%s: %v", op, err)
 	}
 
 	if res.RowsAffected() == 0 {
-		return models.ErrOrderNotFound
+		return models.ErrWorkerNotFound
 	}
 
 	return nil
 }
 
-func (repo *workerRepository) IncrOrdersProcessed(ctx context.Context, name string) error {
+// SetCommand gives an admin command to the worker. Evict marks the worker offline at once, so a new
+// instance can register under its name. Other commands are accepted only for workers seen after onlineSince.
+func (repo *workerRepository) SetCommand(ctx context.Context, locationID, name, command string, onlineSince time.Time) (models.Worker, error) {
+	const op = "workerRepository.SetCommand"
+
+	// Evicted worker session ends at once.
+	query := `
+		WITH updated AS (
+			UPDATE 
+				workers
+			SET 
+				status = CASE WHEN $3 = 'evict' THEN 'offline' ELSE status END,
+				command = $3,
+				command_at = now()
+			WHERE 
+				location_id = $1
+				AND name = $2
+				AND (
+					$3 = 'evict'
+					OR (status <> 'offline' AND last_seen >= $4)
+				)
+			RETURNING` + workerColumns + `
+		), evicted AS (
+			UPDATE 
+				worker_sessions
+			SET 
+				ended_at = now(),
+				end_reason = 'evicted'
+			WHERE 
+				location_id = $1
+				AND worker_name = $2
+				AND ended_at IS NULL
+				AND $3 = 'evict'
+				AND EXISTS (SELECT 1 FROM updated)
+		)
+		SELECT` + workerColumns + ` FROM updated;`
+
+	rows, err := repo.pool.Query(ctx, query, locationID, name, command, onlineSince)
+	if err != nil {
+		return models.Worker{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	worker, err := pgx.CollectExactlyOneRow(rows, scanWorker)
+	if err == nil {
+		return worker, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return models.Worker{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	// Not updated, the worker is either unknown or offline.
+	var exists bool
+	if err := repo.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM workers WHERE location_id = $1 AND name = $2);`, locationID, name).Scan(&exists); err != nil {
+		return models.Worker{}, fmt.Errorf("%s: %v", op, err)
+	}
+	if !exists {
+		return models.Worker{}, models.ErrWorkerNotFound
+	}
+
+	return models.Worker{}, models.ErrWorkerOffline
+}
+
+// IncrOrdersProcessed increments number of orders processed by the worker and by its current session.
+func (repo *workerRepository) IncrOrdersProcessed(ctx context.Context, locationID, name string) error {
 	const op = "workerRepository.IncrOrdersProcessed"
 
 	query := `
+		WITH session AS (
+			UPDATE 
+				worker_sessions
+			SET 
+				orders_processed = orders_processed + 1
+			WHERE 
+				location_id = $1
+				AND worker_name = $2
+				AND ended_at IS NULL
+		)
 		UPDATE 
 			workers
 		SET 
 			orders_processed = orders_processed + 1
 		WHERE 
-			name = $1;`
+			location_id = $1
+			AND name = $2;`
 
-	res, err := repo.pool.Exec(ctx, query, name)
+	res, err := repo.pool.Exec(ctx, query, locationID, name)
 	if err != nil {
 		return fmt.Errorf("%s: %v", op, err)
 	}
@@ -135,26 +415,63 @@ func (repo *workerRepository) IncrOrdersProcessed(ctx context.Context, name stri
 	return nil
 }
 
-func (repo *workerRepository) MarkOffline(ctx context.Context, name string) error {
+// MarkOffline marks the worker offline and ends the session of its instance gracefully. A worker registered
+// by a newer instance meanwhile, e.g. after an evict, is left alone.
+func (repo *workerRepository) MarkOffline(ctx context.Context, locationID, name string, sessionID int) error {
 	const op = "workerRepository.MarkOffline"
 
 	query := `
+		WITH session AS (
+			UPDATE 
+				worker_sessions
+			SET 
+				ended_at = now(),
+				end_reason = 'graceful'
+			WHERE 
+				id = $3
+				AND ended_at IS NULL
+		)
 		UPDATE 
 			workers
 		SET 
 			status = 'offline',
+			command = NULL,
+			command_at = NULL,
+			active_orders = 0,
+			current_order = NULL,
 			last_seen = now()
 		WHERE 
-			name = $1;`
+			location_id = $1
+			AND name = $2
+			AND $3 = (SELECT max(id) FROM worker_sessions WHERE location_id = $1 AND worker_name = $2);`
 
-	res, err := repo.pool.Exec(ctx, query, name)
+	res, err := repo.pool.Exec(ctx, query, locationID, name, sessionID)
 	if err != nil {
 		return fmt.Errorf("%s: %v", op, err)
 	}
 
-	if res.RowsAffected() == 0 {
-		return models.ErrOrderNotFound
+	if res.RowsAffected() == 0 && !repo.exists(ctx, locationID, name) {
+		return models.ErrWorkerNotFound
 	}
 
 	return nil
 }
+
+func (repo *workerRepository) exists(ctx context.Context, locationID, name string) bool {
+	var exists bool
+	err := repo.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM workers WHERE location_id = $1 AND name = $2)`,
+		locationID, name,
+	).Scan(&exists)
+	return err == nil && exists
+}
+
+func scanWorker(row pgx.CollectableRow) (models.Worker, error) {
+	var worker models.Worker
+	if err := row.Scan(&worker.Name, &worker.LocationID, &worker.Status, &worker.OrderTypes, &worker.Stations, &worker.Command,
+		&worker.ProcessedOrders, &worker.ActiveOrders, &worker.CurrentOrder, &worker.Version, &worker.Host, &worker.PID,
+		&worker.HeartbeatInterval, &worker.LastSeen); err != nil {
+		return models.Worker{}, err
+	}
+	return worker, nil
+}
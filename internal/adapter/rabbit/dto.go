@@ -9,6 +9,7 @@ import (
 
 // Order represents the structure of an order to be published
 type Order struct {
+	LocationID      string      `json:"location_id"`
 	OrderNumber     string      `json:"order_number"`
 	CustomerName    string      `json:"customer_name"`
 	OrderType       string      `json:"order_type"`
@@ -17,6 +18,8 @@ type Order struct {
 	Items           []OrderItem `json:"items"`
 	TotalAmount     float64     `json:"total_amount"`
 	Priority        int         `json:"priority"`
+	Version         int         `json:"version,omitempty"` // messages of older versions are skipped by the kitchen
+	Station         string      `json:"station,omitempty"` // items are of this kitchen station only
 	RequestID       string      `json:"request_id,omitempty"`
 }
 
@@ -48,6 +51,7 @@ func FromInternalToPublishOrder(ctx context.Context, m *models.CreateOrder) *Ord
 	}
 
 	return &Order{
+		LocationID:      m.LocationID,
 		OrderNumber:     m.Number,
 		CustomerName:    m.CustomerName,
 		OrderType:       m.Type,
@@ -56,6 +60,8 @@ func FromInternalToPublishOrder(ctx context.Context, m *models.CreateOrder) *Ord
 		Items:           publishItems,
 		TotalAmount:     m.TotalAmount,
 		Priority:        m.Priority,
+		Version:         m.Version,
+		Station:         m.Station,
 		RequestID:       requestID,
 	}
 }
@@ -76,6 +82,7 @@ func FromPublishToInternalOrder(m *Order) *models.CreateOrder {
 	}
 
 	return &models.CreateOrder{
+		LocationID:      m.LocationID,
 		Number:          m.OrderNumber,
 		CustomerName:    m.CustomerName,
 		Type:            m.OrderType,
@@ -84,6 +91,8 @@ func FromPublishToInternalOrder(m *Order) *models.CreateOrder {
 		DeliveryAddress: m.DeliveryAddress,
 		TotalAmount:     m.TotalAmount,
 		Priority:        m.Priority,
+		Version:         m.Version,
+		Station:         m.Station,
 		Status:          "", // no status in published message
 	}
 }
@@ -32,7 +32,7 @@ func NewNotificationSubscriber(client *rabbit.RabbitMQ, cfg config.RabbitMQ, log
 	}
 }
 
-func (s *NotificationSubscriber) StartListening(ctx context.Context) (chan models.StatusUpdate, error) {
+func (s *NotificationSubscriber) StartListening(ctx context.Context) (chan models.Notification, error) {
 	s.log.Info(ctx, "subscriber_start", "Starting to listen for notifications")
 
 	if err := s.declareAndBindQueue(); err != nil {
@@ -42,7 +42,7 @@ func (s *NotificationSubscriber) StartListening(ctx context.Context) (chan model
 
 	s.log.Info(ctx, "rabbit_queue_ready", fmt.Sprintf("Queue %s bound to exchange %s", s.queueName, s.exchangeName))
 
-	updateCh := make(chan models.StatusUpdate, 1)
+	updateCh := make(chan models.Notification, 1)
 
 	go s.startConsuming(ctx, updateCh)
 
@@ -76,7 +76,7 @@ func (s *NotificationSubscriber) declareAndBindQueue() error {
 	return nil
 }
 
-func (s *NotificationSubscriber) startConsuming(ctx context.Context, outCh chan models.StatusUpdate) {
+func (s *NotificationSubscriber) startConsuming(ctx context.Context, outCh chan models.Notification) {
 	defer close(outCh)
 
 	for {
@@ -97,13 +97,13 @@ func (s *NotificationSubscriber) startConsuming(ctx context.Context, outCh chan
 		for {
 			select {
 			case msg := <-msgs:
-				update, err := decodeStatusUpdate(msg.Body)
-				if len(update.RequestID) != 0 {
-					ctx = logger.WithRequestID(ctx, update.RequestID) // request_id logging
+				notification, err := decodeNotification(msg.Type, msg.Body)
+				if notification.StatusUpdate != nil && len(notification.StatusUpdate.RequestID) != 0 {
+					ctx = logger.WithRequestID(ctx, notification.StatusUpdate.RequestID) // request_id logging
 				}
 
 				if err != nil {
-					s.log.Error(ctx, "notification_decode", "Failed to decode status update", err)
+					s.log.Error(ctx, "notification_decode", "Failed to decode notification", err)
 					if err := msg.Nack(false, false); err != nil {
 						s.log.Error(ctx, "rabbit_ack", "Failed to ack message", err)
 					}
@@ -114,7 +114,7 @@ func (s *NotificationSubscriber) startConsuming(ctx context.Context, outCh chan
 					s.log.Error(ctx, "rabbit_ack", "Failed to ack message", err)
 				}
 
-				outCh <- update
+				outCh <- notification
 			case <-connClose:
 				s.log.Warn(ctx, "rabbit_channel_closed", "Channel closed by broker, attempting to reconnect", "error", err)
 
@@ -195,10 +195,19 @@ func (s *NotificationSubscriber) Close() error {
 	return s.reader.Close(ctx)
 }
 
-func decodeStatusUpdate(body []byte) (models.StatusUpdate, error) {
-	var update models.StatusUpdate
-	if err := json.Unmarshal(body, &update); err != nil {
-		return models.StatusUpdate{}, err
+func decodeNotification(messageType string, body []byte) (models.Notification, error) {
+	switch messageType {
+	case messageLowStock:
+		var alert models.LowStockAlert
+		if err := json.Unmarshal(body, &alert); err != nil {
+			return models.Notification{}, err
+		}
+		return models.Notification{LowStock: &alert}, nil
+	default:
+		var update models.StatusUpdate
+		if err := json.Unmarshal(body, &update); err != nil {
+			return models.Notification{}, err
+		}
+		return models.Notification{StatusUpdate: &update}, nil
 	}
-	return update, nil
 }
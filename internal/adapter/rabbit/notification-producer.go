@@ -17,6 +17,12 @@ import (
 
 var ErrEmptyExchangeName = errors.New("empty exchange name")
 
+// Types of messages of the notifications exchange. Messages without type are status updates.
+const (
+	messageStatusUpdate = "status_update"
+	messageLowStock     = "low_stock"
+)
+
 // NotificationProducer
 type NotificationProducer struct {
 	client *rabbit.RabbitMQ
@@ -59,6 +65,15 @@ func NewProducerNotify(ctx context.Context, cfg config.RabbitMQ, log logger.Logg
 
 // StatusUpdate publishes event about status change.
 func (p *NotificationProducer) StatusUpdate(ctx context.Context, req *models.StatusUpdate) error {
+	return p.publish(ctx, messageStatusUpdate, req)
+}
+
+// LowStock publishes event about low stock of an ingredient.
+func (p *NotificationProducer) LowStock(ctx context.Context, alert *models.LowStockAlert) error {
+	return p.publish(ctx, messageLowStock, alert)
+}
+
+func (p *NotificationProducer) publish(ctx context.Context, messageType string, event any) error {
 	// Cheking if connected
 	if p.client.IsConnectionClosed() {
 		p.log.Debug(ctx, types.ActionRabbitReconnect, "trying to recconect to RabbitMQ")
@@ -68,14 +83,15 @@ func (p *NotificationProducer) StatusUpdate(ctx context.Context, req *models.Sta
 	}
 
 	// Marshal the struct to JSON
-	body, err := json.Marshal(req)
+	body, err := json.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal StatusUpdate: %w", err)
+		return fmt.Errorf("failed to marshal %s: %w", messageType, err)
 	}
 
 	// Prepare the message
 	msg := amqp.Publishing{
 		ContentType:  "application/json",
+		Type:         messageType,
 		Body:         body,
 		DeliveryMode: amqp.Persistent, // Persistent message (2). Means rabbitmq will store message in the disc.
 		Timestamp:    time.Now(),
@@ -90,7 +106,7 @@ func (p *NotificationProducer) StatusUpdate(ctx context.Context, req *models.Sta
 		false, // immediate
 		msg,
 	); err != nil {
-		return fmt.Errorf("failed to publish StatusUpdate: %w", err)
+		return fmt.Errorf("failed to publish %s: %w", messageType, err)
 	}
 
 	return nil
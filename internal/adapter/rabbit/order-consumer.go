@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/Temutjin2k/wheres-my-pizza/config"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
@@ -11,22 +12,26 @@ import (
 	"github.com/Temutjin2k/wheres-my-pizza/internal/service/kitchen"
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/rabbit"
+	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 type OrderConsumer struct {
 	client *rabbit.RabbitMQ
 
+	mu            sync.Mutex
 	prefetchCount int
 	exchangeOrder string
+	location      string
 	orderTypes    []string
+	stations      []string
 
 	cfg config.RabbitMQ
 	log logger.Logger
 }
 
-func NewOrderConsumer(ctx context.Context, cfg config.RabbitMQ, prefetchCount int, orderTypes []string, log logger.Logger) (*OrderConsumer, error) {
-	if len(orderTypes) == 0 {
-		return nil, errors.New("orderTypes not provided, slice len 0")
+func NewOrderConsumer(ctx context.Context, cfg config.RabbitMQ, location string, prefetchCount int, orderTypes, stations []string, log logger.Logger) (*OrderConsumer, error) {
+	if len(orderTypes) == 0 && len(stations) == 0 {
+		return nil, errors.New("neither orderTypes nor stations provided")
 	}
 
 	// RabbitMQ connection
@@ -49,7 +54,11 @@ func NewOrderConsumer(ctx context.Context, cfg config.RabbitMQ, prefetchCount in
 		return nil, fmt.Errorf("failed to declare exchange: %w", err)
 	}
 
-	if err := InitQueuesForOrderTypes(client, cfg.OrderExchange, orderTypes); err != nil {
+	if err := InitQueuesForOrderTypes(client, cfg.OrderExchange, location, orderTypes); err != nil {
+		return nil, err
+	}
+
+	if err := InitQueuesForStations(client, cfg.OrderExchange, location, stations); err != nil {
 		return nil, err
 	}
 
@@ -57,7 +66,9 @@ func NewOrderConsumer(ctx context.Context, cfg config.RabbitMQ, prefetchCount in
 		client:        client,
 		prefetchCount: prefetchCount,
 		exchangeOrder: cfg.OrderExchange,
+		location:      location,
 		orderTypes:    orderTypes,
+		stations:      stations,
 
 		cfg: cfg,
 		log: log,
@@ -69,6 +80,23 @@ func (c *OrderConsumer) Consume(
 	ctx context.Context,
 	orderType string,
 	handler func(ctx context.Context, req *models.CreateOrder) error,
+) error {
+	return c.consume(ctx, getQueueByOrderType(c.location, orderType), handler)
+}
+
+// ConsumeStation consumes station parts of created orders.
+func (c *OrderConsumer) ConsumeStation(
+	ctx context.Context,
+	station string,
+	handler func(ctx context.Context, req *models.CreateOrder) error,
+) error {
+	return c.consume(ctx, getQueueByStation(c.location, station), handler)
+}
+
+func (c *OrderConsumer) consume(
+	ctx context.Context,
+	queueName string,
+	handler func(ctx context.Context, req *models.CreateOrder) error,
 ) error {
 	// Cheking if connected
 	if c.client.IsConnectionClosed() {
@@ -81,16 +109,19 @@ func (c *OrderConsumer) Consume(
 	// In RabbitMQ, basic.qos is a method used to configure the quality of service for consumers,
 	// specifically by controlling how many messages a consumer can receive without acknowledging them.
 	// Using basic.qos is critical to prevent worker overload and distribute the load evenly between workers.
-	if err := c.client.Channel.Qos(c.prefetchCount, 0, false); err != nil {
-		c.log.Error(ctx, types.ActionMessageProcessingFailed, "failed to set QoS", err, "prefetchCount", c.prefetchCount)
+	c.mu.Lock()
+	prefetchCount := c.prefetchCount
+	c.mu.Unlock()
+
+	if err := c.client.Channel.Qos(prefetchCount, 0, false); err != nil {
+		c.log.Error(ctx, types.ActionMessageProcessingFailed, "failed to set QoS", err, "prefetchCount", prefetchCount)
 		return fmt.Errorf("failed to set QoS: %w", err)
 	}
 
-	queueName := getQueueByOrderType(orderType)
-
+	// Queue name is the consumer tag, a worker consumes each queue once.
 	msgs, err := c.client.Channel.Consume(
 		queueName,
-		"",
+		queueName,
 		false,
 		false,
 		false,
@@ -105,6 +136,7 @@ func (c *OrderConsumer) Consume(
 	for {
 		select {
 		case <-ctx.Done():
+			c.cancel(ctx, queueName, msgs)
 			return nil
 		case msg, ok := <-msgs:
 			if !ok {
@@ -132,6 +164,12 @@ func (c *OrderConsumer) Consume(
 			}
 
 			if err := handler(ctx, order); err != nil {
+				// Stale copy of a republished order, the current one is still in the queue.
+				if errors.Is(err, kitchen.ErrStaleOrder) {
+					msg.Ack(false)
+					continue
+				}
+
 				if isRecoverableError(err) {
 					msg.Nack(false, true) // Requeue
 				} else {
@@ -146,6 +184,36 @@ func (c *OrderConsumer) Consume(
 	}
 }
 
+// cancel stops the consumer, e.g. when the worker is paused, and requeues messages
+// delivered to it but not handled yet, so other workers can take them.
+func (c *OrderConsumer) cancel(ctx context.Context, consumerTag string, msgs <-chan amqp.Delivery) {
+	if c.client.IsConnectionClosed() {
+		return
+	}
+
+	if err := c.client.Channel.Cancel(consumerTag, false); err != nil {
+		c.log.Error(ctx, types.ActionMessageProcessingFailed, "failed to cancel consumer", err, "queue", consumerTag)
+		return
+	}
+
+	for msg := range msgs {
+		msg.Nack(false, true)
+	}
+}
+
+// SetPrefetch changes QoS of the channel. It applies to deliveries made after the call.
+func (c *OrderConsumer) SetPrefetch(prefetch int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.client.Channel.Qos(prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+	c.prefetchCount = prefetch
+
+	return nil
+}
+
 func (r *OrderConsumer) reconnect(ctx context.Context) error {
 	fn := func() error {
 		conn, err := rabbit.New(ctx, r.cfg.Conn, r.log)
@@ -177,7 +245,8 @@ func (r *OrderConsumer) Close(ctx context.Context) error {
 // - If there are data validation errors in the message that will never be corrected, the message should be
 // sent to a Dead-Letter Queue (`DLQ`) to allow for manual analysis and to prevent the queue from being blocked.
 
-// isRecoverableError returns true if the provided error must be requeued
+// isRecoverableError returns true if the provided error must be requeued.
+// Orders rejected by a stopping worker are cooked by other workers.
 func isRecoverableError(err error) bool {
-	return errors.Is(err, kitchen.ErrNilOrder)
+	return errors.Is(err, kitchen.ErrNilOrder) || errors.Is(err, kitchen.ErrWorkerStopping)
 }
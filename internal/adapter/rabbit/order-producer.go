@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/Temutjin2k/wheres-my-pizza/config"
@@ -20,11 +21,15 @@ type OrderProducer struct {
 
 	exchangeOrder string
 
+	mu        sync.Mutex
+	locations map[string]struct{} // locations whose queues are already declared
+	stations  map[string]struct{} // <location>/<station> station queues already declared
+
 	cfg config.RabbitMQ
 	log logger.Logger
 }
 
-func NewOrderProducer(ctx context.Context, cfg config.RabbitMQ, log logger.Logger) (*OrderProducer, error) {
+func NewOrderProducer(ctx context.Context, cfg config.RabbitMQ, location string, log logger.Logger) (*OrderProducer, error) {
 	// RabbitMQ connection
 	client, err := rabbit.New(ctx, cfg.Conn, log)
 	if err != nil {
@@ -45,20 +50,61 @@ func NewOrderProducer(ctx context.Context, cfg config.RabbitMQ, log logger.Logge
 		return nil, fmt.Errorf("failed to declare exchange: %w", err)
 	}
 
-	// creating all possible queues and binding them to order exchange.
-	if err := InitQueuesForOrderTypes(client, cfg.OrderExchange, types.AllOrderTypes); err != nil {
-		return nil, fmt.Errorf("failed to init order queues: %w", err)
-	}
-
-	return &OrderProducer{
+	p := &OrderProducer{
 		client:        client,
 		exchangeOrder: cfg.OrderExchange,
+		locations:     make(map[string]struct{}),
+		stations:      make(map[string]struct{}),
 		cfg:           cfg,
 		log:           log,
-	}, nil
+	}
+
+	// creating all possible queues of the location and binding them to order exchange.
+	if err := p.initLocation(location); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// initLocation declares kitchen queues of the location once, so orders published
+// before any worker of the location started are not lost.
+func (r *OrderProducer) initLocation(location string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.locations[location]; ok {
+		return nil
+	}
+
+	if err := InitQueuesForOrderTypes(r.client, r.exchangeOrder, location, types.AllOrderTypes); err != nil {
+		return fmt.Errorf("failed to init order queues: %w", err)
+	}
+	r.locations[location] = struct{}{}
+
+	return nil
+}
+
+// initStation declares station queue of the location once.
+func (r *OrderProducer) initStation(location, station string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := location + "/" + station
+	if _, ok := r.stations[key]; ok {
+		return nil
+	}
+
+	if err := InitQueuesForStations(r.client, r.exchangeOrder, location, []string{station}); err != nil {
+		return fmt.Errorf("failed to init station queue: %w", err)
+	}
+	r.stations[key] = struct{}{}
+
+	return nil
 }
 
-// PublishCreateOrder publishes an order message to the orders_topic exchange
+// PublishCreateOrder publishes an order message to the orders_topic exchange. Orders with kitchen stations
+// are published as one message per station with items of the station only.
 func (r *OrderProducer) PublishCreateOrder(ctx context.Context, order *models.CreateOrder) error {
 	if order == nil {
 		return errors.New("nil order")
@@ -71,6 +117,35 @@ func (r *OrderProducer) PublishCreateOrder(ctx context.Context, order *models.Cr
 		}
 	}
 
+	if err := r.initLocation(order.LocationID); err != nil {
+		r.log.Error(ctx, types.ActionRabbitMQPublishFailed, "failed to init location queues", err, "location", order.LocationID)
+		return err
+	}
+
+	stations, items := order.Stations()
+	if len(stations) == 0 {
+		return r.publish(ctx, order, createOrderPublishedKey(order))
+	}
+
+	for _, station := range stations {
+		if err := r.initStation(order.LocationID, station); err != nil {
+			r.log.Error(ctx, types.ActionRabbitMQPublishFailed, "failed to init station queue", err, "location", order.LocationID, "station", station)
+			return err
+		}
+
+		part := *order
+		part.Station = station
+		part.Items = items[station]
+
+		if err := r.publish(ctx, &part, createStationPublishedKey(&part)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *OrderProducer) publish(ctx context.Context, order *models.CreateOrder, routingKey string) error {
 	// Marshal order to JSON
 	body, err := json.Marshal(FromInternalToPublishOrder(ctx, order))
 	if err != nil {
@@ -78,8 +153,6 @@ func (r *OrderProducer) PublishCreateOrder(ctx context.Context, order *models.Cr
 		return fmt.Errorf("failed to marshal order: %w", err)
 	}
 
-	routingKey := createOrderPublishedKey(order)
-
 	// Create the message with persistent delivery mode
 	msg := amqp091.Publishing{
 		ContentType:  "application/json",
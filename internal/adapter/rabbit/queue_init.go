@@ -4,18 +4,49 @@ import (
 	"fmt"
 
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/rabbit"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-// Creates queues for each type of order and binds them to the given exchange.
+const dlxExchange = "dlx_exchange"
+
+// Creates queues of the location for each type of order and binds them to the given exchange.
 // Also creates Dead Letter Exchange(DLX) and alongside with DLQ for each orderType and bind each the queue to that DLQ.
-func InitQueuesForOrderTypes(client *rabbit.RabbitMQ, exchange string, orderTypes []string) error {
-	// > Dead Letter Queue (DLQ) is a specialized queue that stores messages that cannot be delivered or processed by
-	// their intended queue. It acts as a safety net, preventing failed messages from being lost and allowing for
-	// inspection, troubleshooting, and potential reprocessing.
-	// Creating Dead Letter exchange
-	dlxExchange := "dlx_exchange"
+func InitQueuesForOrderTypes(client *rabbit.RabbitMQ, exchange, location string, orderTypes []string) error {
+	if err := declareDLX(client); err != nil {
+		return err
+	}
+
+	for _, ot := range orderTypes {
+		if err := declareKitchenQueue(client, exchange, getQueueByOrderType(location, ot), getRoutingkeyByOrderType(location, ot)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InitQueuesForStations creates queues of the location for each kitchen station, the same way as for order types.
+func InitQueuesForStations(client *rabbit.RabbitMQ, exchange, location string, stations []string) error {
+	if err := declareDLX(client); err != nil {
+		return err
+	}
+
+	for _, station := range stations {
+		if err := declareKitchenQueue(client, exchange, getQueueByStation(location, station), getRoutingKeyByStation(location, station)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// > Dead Letter Queue (DLQ) is a specialized queue that stores messages that cannot be delivered or processed by
+// their intended queue. It acts as a safety net, preventing failed messages from being lost and allowing for
+// inspection, troubleshooting, and potential reprocessing.
+// Creating Dead Letter exchange
+func declareDLX(client *rabbit.RabbitMQ) error {
 	err := client.Channel.ExchangeDeclare(
 		dlxExchange,
 		"topic",
@@ -29,83 +60,96 @@ func InitQueuesForOrderTypes(client *rabbit.RabbitMQ, exchange string, orderType
 		return fmt.Errorf("failed to declare DLX exchange: %w", err)
 	}
 
-	for _, ot := range orderTypes {
-		// main order type queue name
-		queueName := getQueueByOrderType(ot)
+	return nil
+}
 
-		// DLQ name
-		deadLQueue := getDLQKeyForQueue(queueName)
+// declareKitchenQueue creates priority queue bound to the exchange and its DLQ.
+func declareKitchenQueue(client *rabbit.RabbitMQ, exchange, queueName, bindingKey string) error {
+	// DLQ name
+	deadLQueue := getDLQKeyForQueue(queueName)
 
-		// arguments for the queue
-		args := amqp.Table{
-			"x-dead-letter-exchange":    dlxExchange,
-			"x-dead-letter-routing-key": deadLQueue,
-		}
+	// arguments for the queue
+	args := amqp.Table{
+		"x-dead-letter-exchange":    dlxExchange,
+		"x-dead-letter-routing-key": deadLQueue,
+		"x-max-priority":            types.MaxOrderPriority, // orders are delivered by priority
+	}
 
-		// Creating new queue
-		_, err := client.Channel.QueueDeclare(
-			queueName,
-			true,
-			false,
-			false,
-			false,
-			args,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to declare queue %s: %w", queueName, err)
-		}
+	// Creating new queue
+	_, err := client.Channel.QueueDeclare(
+		queueName,
+		true,
+		false,
+		false,
+		false,
+		args,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", queueName, err)
+	}
 
-		// Binding queue to the exchange
-		bindingKey := getRoutingkeyByOrderType(ot)
-		if err := client.Channel.QueueBind(
-			queueName,
-			bindingKey,
-			exchange,
-			false,
-			nil,
-		); err != nil {
-			return fmt.Errorf("failed to bind queue %s: %w", queueName, err)
-		}
+	// Binding queue to the exchange
+	if err := client.Channel.QueueBind(
+		queueName,
+		bindingKey,
+		exchange,
+		false,
+		nil,
+	); err != nil {
+		return fmt.Errorf("failed to bind queue %s: %w", queueName, err)
+	}
 
-		// DLQ queue
-		_, err = client.Channel.QueueDeclare(
-			deadLQueue,
-			true,  // durable
-			false, // autoDelete
-			false, // exclusive
-			false, // noWait
-			nil,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to declare DLQ: %w", err)
-		}
+	// DLQ queue
+	_, err = client.Channel.QueueDeclare(
+		deadLQueue,
+		true,  // durable
+		false, // autoDelete
+		false, // exclusive
+		false, // noWait
+		nil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare DLQ: %w", err)
+	}
 
-		// binding DLQ to the DLX
-		if err := client.Channel.QueueBind(
-			deadLQueue,
-			getDLQRoutingKeyQueueName(queueName),
-			dlxExchange,
-			false,
-			nil,
-		); err != nil {
-			return fmt.Errorf("failed to bind DLQ: %w", err)
-		}
+	// binding DLQ to the DLX
+	if err := client.Channel.QueueBind(
+		deadLQueue,
+		getDLQRoutingKeyQueueName(queueName),
+		dlxExchange,
+		false,
+		nil,
+	); err != nil {
+		return fmt.Errorf("failed to bind DLQ: %w", err)
 	}
 
 	return nil
 }
 
-func getQueueByOrderType(ot string) string {
-	return fmt.Sprintf("kitchen_%s_queue", ot)
+func getQueueByOrderType(location, ot string) string {
+	return fmt.Sprintf("kitchen_%s_%s_queue", location, ot)
+}
+
+func getRoutingkeyByOrderType(location, ot string) string {
+	return fmt.Sprintf("kitchen.%s.%s.*", location, ot)
+}
+
+func getQueueByStation(location, station string) string {
+	return fmt.Sprintf("kitchen_%s_station_%s_queue", location, station)
+}
+
+func getRoutingKeyByStation(location, station string) string {
+	return fmt.Sprintf("kitchen.%s.station.%s.*", location, station)
 }
 
-func getRoutingkeyByOrderType(ot string) string {
-	return fmt.Sprintf("kitchen.%s.*", ot)
+// Create the routing key of a station part of the order: kitchen.<location>.station.<station>.<priority>
+func createStationPublishedKey(order *models.CreateOrder) string {
+	return fmt.Sprintf("kitchen.%s.station.%s.%d", order.LocationID, order.Station, order.Priority)
 }
 
-// Create the routing key
+// Create the routing key: kitchen.<location>.<type>.<priority>
 func createOrderPublishedKey(order *models.CreateOrder) string {
-	return fmt.Sprintf("kitchen.%s.%d", order.Type, order.Priority)
+	return fmt.Sprintf("kitchen.%s.%s.%d", order.LocationID, order.Type, order.Priority)
 }
 
 func getDLQKeyForQueue(queueName string) string {
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Temutjin2k/wheres-my-pizza/config"
 	svc "github.com/Temutjin2k/wheres-my-pizza/internal/app/services"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
 )
@@ -17,9 +19,14 @@ type Service interface {
 	Start(ctx context.Context) error
 }
 
+// App runs services of the mode: one service, or every service of --mode=all.
 type App struct {
-	mode    types.ServiceMode
-	service Service
+	mode       types.ServiceMode
+	components []*component
+
+	// Failed services are restarted only in --mode=all, a single service exits instead.
+	restartAttempts int
+	restartDelay    time.Duration
 
 	cfg config.Config
 	log logger.Logger
@@ -33,50 +40,103 @@ func NewApplication(ctx context.Context, cfg config.Config, log logger.Logger) (
 		log:  log,
 	}
 
-	if err := app.initService(ctx, app.mode); err != nil {
+	if err := app.initServices(ctx); err != nil {
 		return nil, err
 	}
 
 	return app, nil
 }
 
+// Run runs services until SIGINT or SIGTERM, or until every service stopped.
 func (app *App) Run(ctx context.Context) error {
-	if app.service == nil {
-		if err := app.initService(ctx, app.mode); err != nil {
+	ctx, stop := app.notifyShutdown(ctx)
+	defer stop()
+
+	return app.supervise(ctx)
+}
+
+// BackfillCustomers is a one-off command linking anonymous orders to customer accounts by name.
+func BackfillCustomers(ctx context.Context, cfg config.Config, log logger.Logger) (int, error) {
+	return svc.BackfillCustomers(ctx, cfg, log)
+}
+
+// ImportOrders is a one-off command importing orders of a JSONL file into a running order-service.
+func ImportOrders(ctx context.Context, path, url, location string, batchSize, concurrency int, log logger.Logger) (models.BatchSummary, error) {
+	return svc.ImportOrders(ctx, path, url, location, batchSize, concurrency, log)
+}
+
+func (app *App) initServices(ctx context.Context) error {
+	if app.mode != types.ModeAll {
+		return app.addComponent(ctx, string(app.mode), app.cfg, app.log)
+	}
+
+	all := app.cfg.Services.All
+	app.restartAttempts = all.RestartAttempts
+	app.restartDelay = all.RestartDelay
+
+	workers, err := models.ParseKitchenWorkers(all.KitchenWorkers)
+	if err != nil {
+		return fmt.Errorf("failed to init service: %w", err)
+	}
+
+	modes := []types.ServiceMode{types.ModeOrder, types.ModeTracking, types.ModeNotificationSubscriber}
+	if app.cfg.Services.Order.RequirePayment {
+		modes = append(modes, types.ModePayment)
+	}
+
+	for _, mode := range modes {
+		cfg := app.cfg.Component(mode, models.KitchenWorkerSpec{})
+		if err := app.addComponent(ctx, string(mode), cfg, logger.InitLogger(string(mode), cfg.LogLevel)); err != nil {
+			return err
+		}
+	}
+
+	for _, worker := range workers {
+		name := fmt.Sprintf("%s/%s", types.ModeKitchenWorker, worker.Name)
+		cfg := app.cfg.Component(types.ModeKitchenWorker, worker)
+		if err := app.addComponent(ctx, name, cfg, logger.InitLogger(name, cfg.LogLevel)); err != nil {
 			return err
 		}
 	}
 
-	if err := app.service.Start(ctx); err != nil {
-		return err
+	return nil
+}
+
+func (app *App) addComponent(ctx context.Context, name string, cfg config.Config, log logger.Logger) error {
+	c := &component{
+		name: name,
+		cfg:  cfg,
+		log:  log,
 	}
 
+	service, err := newService(ctx, cfg, log)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMode) {
+			return err
+		}
+		return fmt.Errorf("failed to init service %s: %w", name, err)
+	}
+	c.service = service
+
+	app.components = append(app.components, c)
 	return nil
 }
 
-func (app *App) initService(ctx context.Context, mode types.ServiceMode) error {
-	var (
-		service Service
-		err     error
-	)
-	switch mode {
+func newService(ctx context.Context, cfg config.Config, log logger.Logger) (Service, error) {
+	switch cfg.Mode {
 	case types.ModeOrder:
-		service, err = svc.NewOrder(ctx, app.cfg, app.log)
+		return svc.NewOrder(ctx, cfg, log)
 	case types.ModeKitchenWorker:
-		service, err = svc.NewKitchen(ctx, app.cfg, app.log)
+		return svc.NewKitchen(ctx, cfg, log)
 	case types.ModeTracking:
-		service, err = svc.NewTracking(ctx, app.cfg, app.log)
+		return svc.NewTracking(ctx, cfg, log)
 	case types.ModeNotificationSubscriber:
-		service, err = svc.NewNotificationSubscriber(ctx, app.cfg, app.log)
+		return svc.NewNotificationSubscriber(ctx, cfg, log)
+	case types.ModePayment:
+		return svc.NewPayment(ctx, cfg, log)
+	case types.ModeLoadGen:
+		return svc.NewLoadGen(ctx, cfg, log)
 	default:
-		return ErrInvalidMode
+		return nil, ErrInvalidMode
 	}
-
-	if err != nil {
-		return fmt.Errorf("failed to init service: %w", err)
-	}
-
-	app.service = service
-
-	return nil
 }
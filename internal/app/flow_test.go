@@ -0,0 +1,353 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/config"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/memory"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/kitchen"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/notification"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/order"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/tracking"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/semaphore"
+)
+
+const (
+	location       = "test"
+	orderExchange  = "orders_topic"
+	notifyExchange = "notifications_fanout"
+)
+
+var (
+	_ order.OrderRepository             = (*memory.OrderRepo)(nil)
+	_ order.ShiftRepository             = (*memory.ShiftRepo)(nil)
+	_ order.MessageBroker               = (*memory.OrderProducer)(nil)
+	_ order.StockNotifier               = (*memory.NotificationProducer)(nil)
+	_ order.PromotionRepository         = (*memory.PromotionRepo)(nil)
+	_ order.ETAEstimator                = (*memory.Estimator)(nil)
+	_ tracking.ETAEstimator             = (*memory.Estimator)(nil)
+	_ tracking.StatusRepo               = (*memory.StatusRepo)(nil)
+	_ tracking.OrderRepo                = (*memory.OrderRepo)(nil)
+	_ tracking.WorkerRepo               = (*memory.WorkerRepo)(nil)
+	_ tracking.ShiftRepo                = (*memory.ShiftRepo)(nil)
+	_ notification.NotificationConsumer = (*memory.NotificationSubscriber)(nil)
+)
+
+type customers struct{}
+
+func (customers) Get(context.Context, int) (models.Customer, error) {
+	return models.Customer{}, models.ErrCustomerNotFound
+}
+
+// recorder is a notifier remembering status updates it got.
+type recorder struct {
+	mu      sync.Mutex
+	updates []models.StatusUpdate
+}
+
+func (r *recorder) StatusUpdate(_ context.Context, req models.StatusUpdate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, req)
+}
+
+func (r *recorder) LowStock(context.Context, models.LowStockAlert) {}
+
+// transitions returns status changes of the order in the order they were notified.
+func (r *recorder) transitions(number string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var transitions []string
+	for _, u := range r.updates {
+		if u.OrderNumber == number {
+			transitions = append(transitions, u.OldStatus+"->"+u.NewStatus)
+		}
+	}
+	return transitions
+}
+
+// listener signals when the subscriber has started listening, fanout messages published before are lost.
+type listener struct {
+	*memory.NotificationSubscriber
+	started chan struct{}
+}
+
+func (l *listener) StartListening(ctx context.Context) (chan models.Notification, error) {
+	defer close(l.started)
+	return l.NotificationSubscriber.StartListening(ctx)
+}
+
+// restaurant is order, kitchen, notification and tracking services of one location sharing
+// an in-memory broker and database.
+type restaurant struct {
+	broker   *memory.Broker
+	store    *memory.Store
+	orders   *order.Service
+	tracking *tracking.Service
+	notified *recorder
+	log      logger.Logger
+}
+
+func newRestaurant(t *testing.T) *restaurant {
+	t.Helper()
+
+	broker := memory.NewBroker()
+	store := memory.NewStore()
+	log := logger.InitLogger("flow-test", logger.LevelError)
+
+	producer, err := memory.NewOrderProducer(broker, orderExchange, location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stock, err := memory.NewNotificationProducer(broker, notifyExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg config.Config
+	cfg.Location = location
+	cfg.RabbitMQ.ReconnectAttempt = 1
+
+	// Every order is estimated ready in a minute.
+	estimator := memory.NewEstimator(time.Minute)
+	orders := order.NewService(cfg, memory.NewOrderRepo(store), producer, stock, semaphore.NewSemaphore(10), estimator,
+		order.DefaultPriorityPolicy(), nil, order.NewTaxTable(0), memory.NewShiftRepo(store), customers{}, memory.NewPromotionRepo(store),
+		nil, nil, time.Second, log)
+	tracker := tracking.NewService(memory.NewStatusRepo(store), memory.NewWorkerRepo(store), memory.NewOrderRepo(store),
+		memory.NewShiftRepo(store), estimator, 30, log)
+
+	// Notifications are listened from the start, like the notification service running before orders come.
+	notified := &recorder{}
+	subscriber := &listener{NotificationSubscriber: memory.NewNotificationSubscriber(broker, notifyExchange, log), started: make(chan struct{})}
+	notifier := notification.NewService(subscriber, notified, log)
+	errCh := make(chan error, 1)
+	go notifier.Notify(context.Background(), errCh)
+	<-subscriber.started
+	t.Cleanup(func() {
+		notifier.Close()
+		if err := <-errCh; !errors.Is(err, notification.ErrNotificationStopped) {
+			t.Errorf("notification service stopped with %v", err)
+		}
+	})
+
+	return &restaurant{
+		broker:   broker,
+		store:    store,
+		orders:   orders,
+		tracking: tracker,
+		notified: notified,
+		log:      log,
+	}
+}
+
+// startWorker runs a kitchen worker of the order types until the test ends.
+func (r *restaurant) startWorker(t *testing.T, name string, orderTypes ...string) {
+	t.Helper()
+
+	consumer, err := memory.NewOrderConsumer(r.broker, orderExchange, location, 1, orderTypes, nil, r.log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	producer, err := memory.NewNotificationProducer(r.broker, notifyExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := kitchen.NewWorker(memory.NewWorkerRepo(r.store), memory.NewOrderRepo(r.store), memory.NewShiftRepo(r.store),
+		consumer, producer, name, location, orderTypes, nil, time.Hour, r.log)
+	w.SetCookingTime(func(string) time.Duration { return 10 * time.Millisecond })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 2)
+	go w.Work(ctx, errCh)
+	t.Cleanup(func() {
+		cancel()
+		if err := <-errCh; !errors.Is(err, kitchen.ErrWorkerStopped) {
+			t.Errorf("worker %s stopped with %v", name, err)
+		}
+	})
+}
+
+// waitNotified waits until status changes of the order are notified.
+func (r *restaurant) waitNotified(t *testing.T, number string, want ...string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := r.notified.transitions(number)
+		if slices.Equal(got, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("notified %v, want %v", got, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (r *restaurant) historyStatuses(t *testing.T, number string) []string {
+	t.Helper()
+
+	history, err := r.tracking.GetTrackingHistory(context.Background(), location, number)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var statuses []string
+	for _, h := range history {
+		statuses = append(statuses, h.Status)
+	}
+	return statuses
+}
+
+func TestOrderFlow(t *testing.T) {
+	r := newRestaurant(t)
+	ctx := context.Background()
+
+	info, err := r.orders.CreateOrder(ctx, &models.CreateOrder{
+		CustomerName: "Alice",
+		Type:         types.OrderTypeTakeOut,
+		Items: []models.CreateOrderItem{
+			{Name: "Margherita", Quantity: 2, Price: 10},
+			{Name: "Cola", Quantity: 1, Price: 2},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != types.StatusOrderReceived || info.TotalAmount != 22 || info.ETA == nil {
+		t.Fatalf("created order %+v", info)
+	}
+
+	// The order waits in the queue until a worker comes.
+	status, err := r.tracking.GetOrderStatus(ctx, location, info.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != types.StatusOrderReceived || status.ETA == nil {
+		t.Fatalf("waiting order status %+v", status)
+	}
+
+	r.startWorker(t, "chef_1", types.OrderTypeTakeOut)
+	r.waitNotified(t, info.Number,
+		types.StatusOrderReceived+"->"+types.StatusOrderCooking,
+		types.StatusOrderCooking+"->"+types.StatusOrderReady)
+
+	status, err = r.tracking.GetOrderStatus(ctx, location, info.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != types.StatusOrderReady || status.ProcessedBy == nil || *status.ProcessedBy != "chef_1" || status.ETA != nil {
+		t.Fatalf("ready order status %+v", status)
+	}
+
+	want := []string{types.StatusOrderReceived, types.StatusOrderCooking, types.StatusOrderReady}
+	if got := r.historyStatuses(t, info.Number); !slices.Equal(got, want) {
+		t.Fatalf("history %v, want %v", got, want)
+	}
+
+	worker, err := r.tracking.GetWorker(ctx, location, "chef_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worker.ProcessedOrders != 1 || worker.Status != types.WorkerOnline {
+		t.Fatalf("worker %+v", worker.Worker)
+	}
+}
+
+func TestModifiedOrderIsCookedOnce(t *testing.T) {
+	r := newRestaurant(t)
+	ctx := context.Background()
+
+	info, err := r.orders.CreateOrder(ctx, &models.CreateOrder{
+		CustomerName: "Bob",
+		Type:         types.OrderTypeTakeOut,
+		Items:        []models.CreateOrderItem{{Name: "Margherita", Quantity: 1, Price: 10}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modified, err := r.orders.ModifyOrder(ctx, "", info.Number, models.OrderModification{
+		Add: []models.CreateOrderItem{{Name: "Cola", Quantity: 2, Price: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modified.Version != 2 || modified.TotalAmount != 14 {
+		t.Fatalf("modified order version %d total %v, want version 2 total 14", modified.Version, modified.TotalAmount)
+	}
+
+	// Both copies are in the queue, only the current one is cooked.
+	queue := memory.QueueByOrderType(location, types.OrderTypeTakeOut)
+	if n := len(r.broker.Ready(queue)); n != 2 {
+		t.Fatalf("queue has %d messages, want 2", n)
+	}
+
+	r.startWorker(t, "chef_1", types.OrderTypeTakeOut)
+	r.waitNotified(t, info.Number,
+		types.StatusOrderReceived+"->"+types.StatusOrderCooking,
+		types.StatusOrderCooking+"->"+types.StatusOrderReady)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(r.broker.Ready(queue)) > 0 || r.broker.Unacked(queue) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("stale copy is not consumed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := len(r.broker.Ready(memory.DLQByQueue(queue))); n != 0 {
+		t.Fatalf("dlq has %d messages", n)
+	}
+
+	order, err := r.tracking.GetOrder(ctx, location, info.Number)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(order.Items) != 2 || order.Status != types.StatusOrderReady {
+		t.Fatalf("cooked order is %s with %d items, want ready with 2", order.Status, len(order.Items))
+	}
+
+	want := []string{types.StatusOrderReceived, types.StatusOrderReceived, types.StatusOrderCooking, types.StatusOrderReady}
+	if got := r.historyStatuses(t, info.Number); !slices.Equal(got, want) {
+		t.Fatalf("history %v, want %v", got, want)
+	}
+}
+
+func TestDeadWorkerSessionTimesOut(t *testing.T) {
+	r := newRestaurant(t)
+	ctx := context.Background()
+
+	// Registered and never heard of again.
+	workers := memory.NewWorkerRepo(r.store)
+	if _, err := workers.MarkOnline(ctx, location, "chef_1", models.Heartbeat{OrderTypes: types.OrderTypeTakeOut}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// Workers not seen within the interval are taken as offline.
+	r.tracking.SetHeartbeatInterval(0)
+	worker, err := r.tracking.GetWorker(ctx, location, "chef_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worker.Status != types.WorkerOffline || len(worker.Sessions) != 1 {
+		t.Fatalf("worker is %s with %d sessions, want offline with 1", worker.Status, len(worker.Sessions))
+	}
+	session := worker.Sessions[0]
+	if session.EndedAt == nil || session.EndReason == nil || *session.EndReason != "heartbeat_timeout" {
+		t.Fatalf("session of dead worker is not timed out: %+v", session)
+	}
+	if !session.EndedAt.Equal(worker.LastSeen) {
+		t.Fatalf("session ended at %v, want when last seen %v", session.EndedAt, worker.LastSeen)
+	}
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/config"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/postgres"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/customer"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	postgresclient "github.com/Temutjin2k/wheres-my-pizza/pkg/postgres"
+)
+
+// BackfillCustomers links existing anonymous orders to customer accounts by name and returns number of linked orders.
+func BackfillCustomers(ctx context.Context, cfg config.Config, log logger.Logger) (int, error) {
+	db, err := postgresclient.New(ctx, cfg.Postgres)
+	if err != nil {
+		log.Error(ctx, types.ActionDBConnectionFailed, "failed to connect postgres", err)
+		return 0, fmt.Errorf("failed to connect postgres: %v", err)
+	}
+	defer db.Pool.Close()
+
+	return customer.NewService(postgres.NewCustomerRepo(db.Pool), log).BackfillOrders(ctx)
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/client"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/importer"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// importTimeout limits one batch request, the order-service creates its orders one by one.
+const importTimeout = 2 * time.Minute
+
+// ImportOrders imports orders of the JSONL file into the order-service running at url.
+func ImportOrders(ctx context.Context, path, url, location string, batchSize, concurrency int, log logger.Logger) (models.BatchSummary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return models.BatchSummary{}, fmt.Errorf("failed to open orders file: %w", err)
+	}
+	defer file.Close()
+
+	service := importer.NewService(client.NewOrderClient(url, importTimeout), location, batchSize, concurrency, log)
+	return service.Import(ctx, file)
+}
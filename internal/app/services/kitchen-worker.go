@@ -4,10 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
-	"os/signal"
+	"slices"
 	"strings"
-	"syscall"
 	"time"
 	"unicode/utf8"
 
@@ -24,6 +22,7 @@ var (
 	ErrEmptyOrderTypes    = fmt.Errorf("order types cannot be empty")
 	ErrDuplicateOrderType = fmt.Errorf("duplicate order type found")
 	ErrInvalidOrderType   = errors.New("invalid order type. must be Comma-separated list of order types the worker can handle (e.g., dine_in,takeout)")
+	ErrInvalidStation     = errors.New("invalid station. must be 1-32 lower case letters, digits or '_'")
 
 	ErrInvalidHeartbeatInterval = errors.New("heartbeat interval must be at least 5 seconds")
 )
@@ -31,6 +30,8 @@ var (
 type KitchenWorker interface {
 	Work(ctx context.Context, errCh chan<- error)
 	Stop(ctx context.Context)
+	SetHeartbeat(interval time.Duration)
+	SetCapacity(prefetch int) error
 }
 
 // Feature: Order Service
@@ -54,11 +55,21 @@ func NewKitchen(ctx context.Context, cfg config.Config, log logger.Logger) (*Kit
 		log.Error(ctx, types.ActionValidationFailed, "failed to vailidate worker name", err)
 		return nil, fmt.Errorf("failed to validate worker name: %w", err)
 	}
-	// Validating order-types to handle by worker.
-	validOrderTypes, err := ValidateOrderTypes(cfg.Services.Kitchen.OrderTypes)
+	// Validating stations to cook items for.
+	stations, err := ValidateStations(cfg.Services.Kitchen.Stations)
 	if err != nil {
-		log.Error(ctx, types.ActionValidationFailed, "failed to vailidate provided order types", err)
-		return nil, fmt.Errorf("failed to vailidate provided order types: %w", err)
+		log.Error(ctx, types.ActionValidationFailed, "failed to vailidate provided stations", err)
+		return nil, fmt.Errorf("failed to vailidate provided stations: %w", err)
+	}
+
+	// Validating order-types to handle by worker. Station workers handle whole orders only if order types are given.
+	var validOrderTypes []string
+	if len(stations) == 0 || cfg.Services.Kitchen.OrderTypes != "" {
+		validOrderTypes, err = ValidateOrderTypes(cfg.Services.Kitchen.OrderTypes)
+		if err != nil {
+			log.Error(ctx, types.ActionValidationFailed, "failed to vailidate provided order types", err)
+			return nil, fmt.Errorf("failed to vailidate provided order types: %w", err)
+		}
 	}
 
 	// validate heartbeat interval
@@ -77,7 +88,7 @@ func NewKitchen(ctx context.Context, cfg config.Config, log logger.Logger) (*Kit
 
 	// RabbitMQ connection
 	// Initialize order consumer
-	consumer, err := rabbit.NewOrderConsumer(ctx, cfg.RabbitMQ, cfg.Services.Kitchen.Prefetch, validOrderTypes, log)
+	consumer, err := rabbit.NewOrderConsumer(ctx, cfg.RabbitMQ, cfg.Location, cfg.Services.Kitchen.Prefetch, validOrderTypes, stations, log)
 	if err != nil {
 		log.Error(ctx, types.ActionRabbitConnectionFailed, "failed to create order consumer", err)
 		return nil, fmt.Errorf("failed to create order consumer: %w", err)
@@ -92,9 +103,10 @@ func NewKitchen(ctx context.Context, cfg config.Config, log logger.Logger) (*Kit
 	// Initialize repositories
 	workerRepo := postgres.NewWorkerRepo(db.Pool)
 	orderRepo := postgres.NewOrderRepo(db.Pool)
+	shiftRepo := postgres.NewShiftRepo(db.Pool)
 
 	// Initialize kitchen-worker service
-	kitchenWorker := kitchen.NewWorker(workerRepo, orderRepo, consumer, producer, cfg.Services.Kitchen.WorkerName, validOrderTypes, heartbeatDuration, log)
+	kitchenWorker := kitchen.NewWorker(workerRepo, orderRepo, shiftRepo, consumer, producer, cfg.Services.Kitchen.WorkerName, cfg.Location, validOrderTypes, stations, heartbeatDuration, log)
 
 	return &KitchenService{
 		postgresDB:    db,
@@ -118,35 +130,53 @@ func (s *KitchenService) Start(ctx context.Context) error {
 	// kitchen worker starts to work in goroutine
 	go s.kitchenWorker.Work(ctx, errCh)
 
-	// Waiting signal
-	shutdownCh := make(chan os.Signal, 1)
-	signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
+	reloader := newConfigReloader(&s.cfg, s.applyConfig, s.log)
+	reloadCh := reloader.Notify(ctx)
 
 	s.log.Info(ctx, types.ActionServiceStarted, "service started")
 
 	for {
 		select {
-		case <-ctx.Done():
-			s.log.Info(ctx, types.ActionGracefulShutdown, "context cancelled")
-			return ctx.Err()
+		case <-reloadCh:
+			reloader.Reload(ctx)
 		case errRun := <-errCh:
+			// Drained or evicted by admin, the worker is not recreated.
+			if errors.Is(errRun, kitchen.ErrWorkerDrained) || errors.Is(errRun, kitchen.ErrWorkerEvicted) {
+				s.log.Info(ctx, types.ActionGracefulShutdown, "worker stopped by admin command", "reason", errRun.Error())
+				return nil
+			}
 			if errors.Is(errRun, kitchen.ErrWorkerStopped) {
-				if err := s.reconnect(ctx, shutdownCh, s.cfg.Services.Kitchen.ReconnectAttempt, s.cfg.Services.Kitchen.ReconnectDelay); err != nil {
+				if err := s.reconnect(ctx, errCh, s.cfg.Services.Kitchen.ReconnectAttempt, s.cfg.Services.Kitchen.ReconnectDelay); err != nil {
 					return err
 				}
 				continue
 			}
 			return errRun
-		case sig := <-shutdownCh:
-			s.log.Info(ctx, types.ActionGracefulShutdown, "shutting down application", "signal", sig.String())
+		case <-ctx.Done():
+			s.log.Info(ctx, types.ActionGracefulShutdown, "shutting down service")
 			return nil
 		}
 	}
 }
 
+// applyConfig applies reloaded settings to the running worker.
+func (s *KitchenService) applyConfig(ctx context.Context, cfg config.Config) {
+	heartbeat := time.Duration(cfg.Services.Kitchen.HeartbeatInterval) * time.Second
+	if heartbeat <= time.Second*5 {
+		s.log.Warn(ctx, types.ActionConfigReloadRejected, ErrInvalidHeartbeatInterval.Error(), "heartbeat-interval", cfg.Services.Kitchen.HeartbeatInterval)
+	} else {
+		s.kitchenWorker.SetHeartbeat(heartbeat)
+	}
+
+	if err := s.kitchenWorker.SetCapacity(cfg.Services.Kitchen.Prefetch); err != nil {
+		s.log.Error(ctx, types.ActionConfigReloadFailed, "failed to change worker capacity", err, "prefetch", cfg.Services.Kitchen.Prefetch)
+	}
+}
+
 // close stops worker and closes connections.
 func (s *KitchenService) close(ctx context.Context) {
-	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	// Service is closed after its context is cancelled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second*10)
 	defer cancel()
 
 	s.kitchenWorker.Stop(ctx)
@@ -162,7 +192,7 @@ func (s *KitchenService) close(ctx context.Context) {
 	s.postgresDB.Pool.Close()
 }
 
-func (s *KitchenService) reconnect(ctx context.Context, shutdownCh chan os.Signal, attempts int, delay time.Duration) error {
+func (s *KitchenService) reconnect(ctx context.Context, errCh chan error, attempts int, delay time.Duration) error {
 	var lastErr error
 
 	const action = "kitchen-create-attempt"
@@ -179,7 +209,7 @@ func (s *KitchenService) reconnect(ctx context.Context, shutdownCh chan os.Signa
 			*s = *newSvc
 
 			// Starting a new worker
-			go s.kitchenWorker.Work(ctx, make(chan error, 1))
+			go s.kitchenWorker.Work(ctx, errCh)
 			return nil
 		}
 
@@ -187,11 +217,10 @@ func (s *KitchenService) reconnect(ctx context.Context, shutdownCh chan os.Signa
 		s.log.Error(ctx, failedAction, "failed to recreate kitchen service", err, "attempt", i)
 
 		select {
-		case <-shutdownCh:
+		case <-ctx.Done():
 			s.log.Info(ctx, action, "reconnect was stopped externally")
-			return fmt.Errorf("reconnect stopped externally")
-		default:
-			time.Sleep(delay)
+			return nil
+		case <-time.After(delay):
 		}
 	}
 
@@ -240,6 +269,30 @@ func ValidateOrderTypes(input string) ([]string, error) {
 	return orderTypes, nil
 }
 
+// ValidateStations parses comma-separated stations. Empty input means no stations.
+func ValidateStations(input string) ([]string, error) {
+	var stations []string
+
+	for station := range strings.SplitSeq(input, ",") {
+		station = strings.TrimSpace(station)
+		if station == "" {
+			continue
+		}
+
+		if !types.IsValidStationName(station) {
+			return nil, fmt.Errorf("%q: %w", station, ErrInvalidStation)
+		}
+
+		if slices.Contains(stations, station) {
+			return nil, fmt.Errorf("%q: duplicate station", station)
+		}
+
+		stations = append(stations, station)
+	}
+
+	return stations, nil
+}
+
 func validateWorkerName(name string) error {
 	// Check length (1-100 characters)
 	if utf8.RuneCountInString(name) < 1 || utf8.RuneCountInString(name) > 100 {
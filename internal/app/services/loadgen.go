@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/config"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/client"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/rabbit"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/loadgen"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	pkg "github.com/Temutjin2k/wheres-my-pizza/pkg/rabbit"
+)
+
+// loadGenTimeout limits one request of the load generator.
+const loadGenTimeout = 30 * time.Second
+
+// Feature: Load Generator
+// The Load Generator sends orders generated from templates to a running order-service with a traffic profile.
+// It measures response latency and, through the notifications exchange, time until the orders are ready,
+// and writes a summary report.
+type LoadGen struct {
+	consumer *rabbit.NotificationSubscriber
+	service  *loadgen.Service
+
+	cfg config.Config
+	log logger.Logger
+}
+
+func NewLoadGen(ctx context.Context, cfg config.Config, log logger.Logger) (*LoadGen, error) {
+	lg := cfg.Services.LoadGen
+
+	templates, err := loadgen.LoadTemplates(lg.Templates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load templates: %w", err)
+	}
+
+	rabbitClient, err := pkg.New(ctx, cfg.RabbitMQ.Conn, log)
+	if err != nil {
+		log.Error(ctx, types.ActionRabbitConnectionFailed, "failed to connect rabbitmq", err)
+		return nil, fmt.Errorf("failed to connect rabbitmq: %v", err)
+	}
+	consumer := rabbit.NewNotificationSubscriber(rabbitClient, cfg.RabbitMQ, log)
+
+	profile := loadgen.Profile{
+		Name:        lg.Profile,
+		Rate:        lg.Rate,
+		Duration:    lg.Duration,
+		BurstEvery:  lg.BurstEvery,
+		BurstLength: lg.BurstLength,
+	}
+
+	service := loadgen.NewService(
+		client.NewOrderClient(lg.URL, loadGenTimeout),
+		consumer,
+		templates,
+		profile,
+		cfg.Location,
+		lg.URL,
+		lg.MaxInFlight,
+		lg.Drain,
+		lg.Report,
+		log,
+	)
+
+	return &LoadGen{
+		consumer: consumer,
+		service:  service,
+		cfg:      cfg,
+		log:      log,
+	}, nil
+}
+
+// Start runs the load until its duration is over. Cancelling ctx stops sending early, the report is still written.
+func (s *LoadGen) Start(ctx context.Context) error {
+	defer func() {
+		if err := s.consumer.Close(); err != nil {
+			s.log.Error(ctx, types.ActionGracefulShutdown, "failed to close notification consumer", err)
+		}
+	}()
+
+	report, err := s.service.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("sent %d orders (%.1f/s), %d created, %d ready, %d errors, latency p50 %.0fms p99 %.0fms, time to ready p50 %.0fms p99 %.0fms, report: %s\n",
+		report.Sent, report.SentRate, report.Created, report.Ready, report.Errors,
+		report.Latency.P50, report.Latency.P99, report.TimeToReady.P50, report.TimeToReady.P99, s.cfg.Services.LoadGen.Report)
+
+	return nil
+}
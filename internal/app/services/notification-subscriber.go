@@ -3,9 +3,6 @@ package services
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/Temutjin2k/wheres-my-pizza/config"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/rabbit"
@@ -60,18 +57,22 @@ func (s *NotificationSubsriber) Start(ctx context.Context) error {
 	errCh := make(chan error, 1)
 	go s.service.Notify(ctx, errCh)
 
-	// Waiting signal
-	shutdownCh := make(chan os.Signal, 1)
-	signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
+	// Only log level can be changed for notification subscriber, it is applied by reloader.
+	reloader := newConfigReloader(&s.cfg, func(context.Context, config.Config) {}, s.log)
+	reloadCh := reloader.Notify(ctx)
 
 	s.log.Info(ctx, types.ActionServiceStarted, "service started")
 
-	select {
-	case errRun := <-errCh:
-		return errRun
-	case sig := <-shutdownCh:
-		s.log.Info(ctx, types.ActionGracefulShutdown, "shuting down application", "signal", sig.String())
-		return nil
+	for {
+		select {
+		case errRun := <-errCh:
+			return errRun
+		case <-reloadCh:
+			reloader.Reload(ctx)
+		case <-ctx.Done():
+			s.log.Info(ctx, types.ActionGracefulShutdown, "shutting down service")
+			return nil
+		}
 	}
 }
 
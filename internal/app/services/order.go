@@ -3,17 +3,21 @@ package services
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/Temutjin2k/wheres-my-pizza/config"
+	geo "github.com/Temutjin2k/wheres-my-pizza/internal/adapter/geocoder"
 	httpserver "github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/server"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/postgres"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/rabbit"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/customer"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/eta"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/inventory"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/service/order"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/promotion"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/table"
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
 	postgresclient "github.com/Temutjin2k/wheres-my-pizza/pkg/postgres"
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/semaphore"
@@ -28,6 +32,11 @@ type Order struct {
 	postgresDB *postgresclient.PostgreDB
 	httpServer *httpserver.API
 	producer   *rabbit.OrderProducer
+	notifier   *rabbit.NotificationProducer
+	service    *order.Service
+	estimator  *eta.Estimator
+	ager       *order.Ager
+	scheduler  *order.Scheduler
 
 	cfg config.Config
 	log logger.Logger
@@ -44,8 +53,17 @@ func NewOrder(ctx context.Context, cfg config.Config, log logger.Logger) (*Order
 
 	// RabbitMQ connection
 	orderRepo := postgres.NewOrderRepo(db.Pool)
+	customerRepo := postgres.NewCustomerRepo(db.Pool)
+	promotionRepo := postgres.NewPromotionRepo(db.Pool)
 
-	producer, err := rabbit.NewOrderProducer(ctx, cfg.RabbitMQ, log)
+	producer, err := rabbit.NewOrderProducer(ctx, cfg.RabbitMQ, cfg.Location, log)
+	if err != nil {
+		log.Error(ctx, types.ActionRabbitConnectionFailed, "failed to connect rabbitmq", err)
+		return nil, fmt.Errorf("failed to connect rabbitmq: %v", err)
+	}
+
+	// Low stock alerts go to the notifications exchange.
+	notifier, err := rabbit.NewProducerNotify(ctx, cfg.RabbitMQ, log)
 	if err != nil {
 		log.Error(ctx, types.ActionRabbitConnectionFailed, "failed to connect rabbitmq", err)
 		return nil, fmt.Errorf("failed to connect rabbitmq: %v", err)
@@ -54,13 +72,78 @@ func NewOrder(ctx context.Context, cfg config.Config, log logger.Logger) (*Order
 	// Semaphore to control maximum number of concurrent orders to process.
 	sem := semaphore.NewSemaphore(cfg.Services.Order.MaxConcurrent)
 
-	orderService := order.NewService(cfg, orderRepo, producer, sem, time.Second, log)
+	// Estimates ready time of new orders from the kitchen state, the same way tracking-service does.
+	estimator := eta.NewEstimator(postgres.NewETARepo(db.Pool), cfg.Services.Tracking.HeartbeatInterval)
+
+	// Priority policy of new orders, by total amount unless rules file is given.
+	var policy order.PriorityPolicy = order.DefaultPriorityPolicy()
+	if cfg.Services.Order.PriorityRules != "" {
+		policy, err = order.LoadPriorityPolicy(cfg.Services.Order.PriorityRules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load priority rules: %v", err)
+		}
+	}
+
+	// Kitchen stations items are routed to, orders are cooked whole without them.
+	var stations *order.StationRouter
+	if cfg.Services.Order.StationsFile != "" {
+		stations, err = order.LoadStations(cfg.Services.Order.StationsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load stations: %v", err)
+		}
+	}
+
+	// Tax categories of menu items, every item is taxed with the default rate without them.
+	taxes := order.NewTaxTable(cfg.Services.Order.TaxRate)
+	if cfg.Services.Order.TaxFile != "" {
+		taxes, err = order.LoadTaxRates(cfg.Services.Order.TaxFile, cfg.Services.Order.TaxRate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tax rates: %v", err)
+		}
+	}
+
+	// Delivery zones with the offline geocoder, delivery is accepted from any address without them.
+	var (
+		zones    *order.DeliveryZones
+		geocoder order.Geocoder
+	)
+	if cfg.Services.Order.DeliveryZones != "" {
+		zones, err = order.LoadDeliveryZones(cfg.Services.Order.DeliveryZones)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load delivery zones: %v", err)
+		}
+
+		geocoder, err = geo.LoadTableGeocoder(cfg.Services.Order.GeocoderFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load geocoder table: %v", err)
+		}
+	}
+
+	orderService := order.NewService(cfg, orderRepo, producer, notifier, sem, estimator, policy, stations, taxes, postgres.NewShiftRepo(db.Pool), customerRepo, promotionRepo, zones, geocoder, cfg.Services.Order.SemWait, log)
+
+	// Priority aging of orders waiting in the kitchen queues. Rules are validated by config.
+	agingRules, _ := models.ParseAgingRules(cfg.Services.Order.AgingRules)
+	ager := order.NewAger(orderRepo, producer, agingRules, log)
 
-	api := httpserver.New(cfg, orderService, nil, log)
+	// Release of scheduled orders to the kitchen.
+	scheduler := order.NewScheduler(orderRepo, producer, log)
+
+	inventoryService := inventory.NewService(postgres.NewInventoryRepo(db.Pool), log)
+	customerService := customer.NewService(customerRepo, log)
+	promotionService := promotion.NewService(promotionRepo, log)
+
+	tableService := table.NewService(postgres.NewTableRepo(db.Pool), log)
+
+	api := httpserver.New(cfg, orderService, nil, nil, inventoryService, customerService, promotionService, tableService, log)
 	return &Order{
 		postgresDB: db,
 		httpServer: api,
 		producer:   producer,
+		notifier:   notifier,
+		service:    orderService,
+		estimator:  estimator,
+		ager:       ager,
+		scheduler:  scheduler,
 
 		cfg: cfg,
 		log: log,
@@ -77,23 +160,51 @@ func (s *Order) Start(ctx context.Context) error {
 		s.log.Info(ctx, types.ActionGracefulShutdown, "order service closed")
 	}()
 
-	// Waiting signal
-	shutdownCh := make(chan os.Signal, 1)
-	signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
+	if s.cfg.Services.Order.AgingInterval > 0 {
+		agingCtx, stopAging := context.WithCancel(ctx)
+		defer stopAging()
+
+		go s.ager.Run(agingCtx, s.cfg.Services.Order.AgingInterval)
+	}
+
+	if s.cfg.Services.Order.SchedulerInterval > 0 {
+		schedulerCtx, stopScheduler := context.WithCancel(ctx)
+		defer stopScheduler()
+
+		go s.scheduler.Run(schedulerCtx, s.cfg.Services.Order.SchedulerInterval)
+	}
+
+	reloader := newConfigReloader(&s.cfg, s.applyConfig, s.log)
+	reloadCh := reloader.Notify(ctx)
 
 	s.log.Info(ctx, types.ActionServiceStarted, "service started")
 
-	select {
-	case errRun := <-errCh:
-		return errRun
-	case sig := <-shutdownCh:
-		s.log.Info(ctx, types.ActionGracefulShutdown, "shuting down application", "signal", sig.String())
-		return nil
+	for {
+		select {
+		case errRun := <-errCh:
+			return errRun
+		case <-reloadCh:
+			reloader.Reload(ctx)
+		case <-ctx.Done():
+			s.log.Info(ctx, types.ActionGracefulShutdown, "shutting down service")
+			return nil
+		}
 	}
 }
 
+// applyConfig applies reloaded settings.
+func (s *Order) applyConfig(ctx context.Context, cfg config.Config) {
+	s.service.SetLimits(cfg.Services.Order.MaxConcurrent, cfg.Services.Order.SemWait)
+	s.service.SetScheduleLead(cfg.Services.Order.ScheduleLead)
+	s.estimator.SetHeartbeatInterval(cfg.Services.Tracking.HeartbeatInterval)
+
+	agingRules, _ := models.ParseAgingRules(cfg.Services.Order.AgingRules)
+	s.ager.SetRules(agingRules)
+}
+
 func (s *Order) close(ctx context.Context) {
-	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	// Service is closed after its context is cancelled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second*10)
 	defer cancel()
 
 	if err := s.httpServer.Stop(ctx); err != nil {
@@ -104,5 +215,9 @@ func (s *Order) close(ctx context.Context) {
 		s.log.Error(ctx, types.ActionGracefulShutdown, "failed to close rabbitMQ order client connection", err)
 	}
 
+	if err := s.notifier.Close(ctx); err != nil {
+		s.log.Error(ctx, types.ActionGracefulShutdown, "failed to close rabbitMQ notification client connection", err)
+	}
+
 	s.postgresDB.Pool.Close()
 }
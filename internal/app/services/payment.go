@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/config"
+	paymentprovider "github.com/Temutjin2k/wheres-my-pizza/internal/adapter/payment"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/postgres"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/rabbit"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/payment"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	postgresclient "github.com/Temutjin2k/wheres-my-pizza/pkg/postgres"
+)
+
+// Feature: Payment Service
+// The Payment Service authorizes payments of orders waiting in 'pending_payment' through a payment provider.
+// Orders with authorized payment are sent to the kitchen, orders with declined or failed payment are cancelled.
+// Payments of orders cancelled after authorization are refunded.
+type Payment struct {
+	postgresDB *postgresclient.PostgreDB
+	producer   *rabbit.OrderProducer
+	provider   *paymentprovider.FakeProvider
+	service    *payment.Service
+
+	cfg config.Config
+	log logger.Logger
+}
+
+func NewPayment(ctx context.Context, cfg config.Config, log logger.Logger) (*Payment, error) {
+	// Postgres database
+	db, err := postgresclient.New(ctx, cfg.Postgres)
+	if err != nil {
+		log.Error(ctx, "db_connect", "failed to connect postgres", err)
+		return nil, fmt.Errorf("failed to connect postgres: %v", err)
+	}
+	log.Info(ctx, types.ActionDBConnected, "connected to the database")
+
+	// Authorized orders are published to the kitchen the same way order-service does.
+	producer, err := rabbit.NewOrderProducer(ctx, cfg.RabbitMQ, cfg.Location, log)
+	if err != nil {
+		log.Error(ctx, types.ActionRabbitConnectionFailed, "failed to connect rabbitmq", err)
+		return nil, fmt.Errorf("failed to connect rabbitmq: %v", err)
+	}
+
+	// Script is validated by config.
+	script, _ := models.ParsePaymentScript(cfg.Services.Payment.FakeScript)
+	provider := paymentprovider.NewFakeProvider(script, cfg.Services.Payment.FakeDelay)
+
+	service := payment.NewService(
+		postgres.NewPaymentRepo(db.Pool),
+		provider,
+		producer,
+		cfg.Services.Payment.Timeout,
+		cfg.Services.Payment.MaxAttempts,
+		cfg.Services.Payment.RetryDelay,
+		log,
+	)
+
+	return &Payment{
+		postgresDB: db,
+		producer:   producer,
+		provider:   provider,
+		service:    service,
+
+		cfg: cfg,
+		log: log,
+	}, nil
+}
+
+func (s *Payment) Start(ctx context.Context) error {
+	defer func() {
+		s.close(ctx)
+		s.log.Info(ctx, types.ActionGracefulShutdown, "payment service closed")
+	}()
+
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.service.Run(runCtx, s.cfg.Services.Payment.Interval)
+	}()
+
+	// Payments being authorized are finished or left for retry before connections are closed.
+	defer func() {
+		stop()
+		<-done
+	}()
+
+	reloader := newConfigReloader(&s.cfg, s.applyConfig, s.log)
+	reloadCh := reloader.Notify(ctx)
+
+	s.log.Info(ctx, types.ActionServiceStarted, "service started", "provider", s.cfg.Services.Payment.Provider)
+
+	for {
+		select {
+		case <-reloadCh:
+			reloader.Reload(ctx)
+		case <-ctx.Done():
+			s.log.Info(ctx, types.ActionGracefulShutdown, "shutting down service")
+			return nil
+		}
+	}
+}
+
+// applyConfig applies reloaded settings.
+func (s *Payment) applyConfig(ctx context.Context, cfg config.Config) {
+	script, _ := models.ParsePaymentScript(cfg.Services.Payment.FakeScript)
+	s.provider.SetScript(script, cfg.Services.Payment.FakeDelay)
+}
+
+func (s *Payment) close(ctx context.Context) {
+	// Service is closed after its context is cancelled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second*10)
+	defer cancel()
+
+	if err := s.producer.Close(ctx); err != nil {
+		s.log.Error(ctx, types.ActionGracefulShutdown, "failed to close rabbitMQ order client connection", err)
+	}
+
+	s.postgresDB.Pool.Close()
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/config"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// configReloader re-reads config on SIGHUP (and on file change if --config-watch is set)
+// and applies settings that can be changed without restart.
+type configReloader struct {
+	cfg   *config.Config
+	apply func(ctx context.Context, cfg config.Config) // applies reloaded settings to the running service
+
+	log logger.Logger
+}
+
+func newConfigReloader(cfg *config.Config, apply func(ctx context.Context, cfg config.Config), log logger.Logger) *configReloader {
+	return &configReloader{
+		cfg:   cfg,
+		apply: apply,
+		log:   log,
+	}
+}
+
+// Notify returns channel which receives a value each time config must be reloaded.
+func (r *configReloader) Notify(ctx context.Context) <-chan struct{} {
+	reloadCh := make(chan struct{}, 1)
+
+	sighupCh := make(chan os.Signal, 1)
+	signal.Notify(sighupCh, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sighupCh)
+
+		var watchCh <-chan time.Time
+		if r.cfg.ConfigWatch > 0 {
+			ticker := time.NewTicker(r.cfg.ConfigWatch)
+			defer ticker.Stop()
+			watchCh = ticker.C
+		}
+		modTime := r.modTime()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sighupCh:
+			case <-watchCh:
+				mt := r.modTime()
+				if mt.Equal(modTime) {
+					continue
+				}
+				modTime = mt
+			}
+
+			select {
+			case reloadCh <- struct{}{}:
+			default: // reload is already pending
+			}
+		}
+	}()
+
+	return reloadCh
+}
+
+// Reload reads config again and applies changes. Changes that require restart are logged and ignored.
+func (r *configReloader) Reload(ctx context.Context) {
+	next, err := config.Reload(r.cfg)
+	if err != nil {
+		r.log.Error(ctx, types.ActionConfigReloadFailed, "failed to reload config, keeping current one", err)
+		return
+	}
+
+	applied, rejected, err := config.ApplyReloadable(r.cfg, next)
+	if err != nil {
+		r.log.Error(ctx, types.ActionConfigReloadFailed, "failed to apply reloaded config", err)
+		return
+	}
+
+	for _, c := range rejected {
+		r.log.Warn(ctx, types.ActionConfigReloadRejected, "setting can not be changed without restart", "path", c.Path, "old", c.Old, "new", c.New)
+	}
+
+	if len(applied) == 0 {
+		r.log.Info(ctx, types.ActionConfigReloaded, "config reloaded, nothing to apply", "rejected", len(rejected))
+		return
+	}
+
+	r.log.SetLevel(r.cfg.LogLevel)
+	r.apply(ctx, *r.cfg)
+
+	r.log.Info(ctx, types.ActionConfigReloaded, "config reloaded", "changes", applied, "rejected", len(rejected))
+}
+
+func (r *configReloader) modTime() time.Time {
+	info, err := os.Stat(r.cfg.Path())
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
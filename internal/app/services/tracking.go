@@ -3,15 +3,15 @@ package services
 import (
 	"context"
 	"fmt"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/Temutjin2k/wheres-my-pizza/config"
 	httpserver "github.com/Temutjin2k/wheres-my-pizza/internal/adapter/http/server"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/postgres"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/analytics"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/eta"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/table"
 	"github.com/Temutjin2k/wheres-my-pizza/internal/service/tracking"
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
 	postgresclient "github.com/Temutjin2k/wheres-my-pizza/pkg/postgres"
@@ -26,6 +26,9 @@ import (
 type Tracking struct {
 	postgresDB *postgresclient.PostgreDB
 	httpServer *httpserver.API
+	service    *tracking.Service
+	analytics  *analytics.Service
+	estimator  *eta.Estimator
 
 	cfg config.Config
 	log logger.Logger
@@ -41,15 +44,27 @@ func NewTracking(ctx context.Context, cfg config.Config, log logger.Logger) (*Tr
 	log.Info(ctx, types.ActionDBConnected, "connected to the database")
 
 	workerRepo := postgres.NewWorkerRepo(db.Pool)
+	shiftRepo := postgres.NewShiftRepo(db.Pool)
 	statusRepo := postgres.NewStatusRepo(db.Pool)
+	orderRepo := postgres.NewOrderRepo(db.Pool)
 
-	trackingService := tracking.NewService(statusRepo, workerRepo, cfg.Services.Tracking.HeartbeatInterval, log)
+	estimator := eta.NewEstimator(postgres.NewETARepo(db.Pool), cfg.Services.Tracking.HeartbeatInterval)
 
-	api := httpserver.New(cfg, nil, trackingService, log)
+	trackingService := tracking.NewService(statusRepo, workerRepo, orderRepo, shiftRepo, estimator, cfg.Services.Tracking.HeartbeatInterval, log)
+
+	analyticsService := analytics.NewService(postgres.NewAnalyticsRepo(db.Pool), cfg.Services.Tracking.SLA, log)
+
+	// Floor view of dining tables
+	tableService := table.NewService(postgres.NewTableRepo(db.Pool), log)
+
+	api := httpserver.New(cfg, nil, trackingService, analyticsService, nil, nil, nil, tableService, log)
 
 	return &Tracking{
 		postgresDB: db,
 		httpServer: api,
+		service:    trackingService,
+		analytics:  analyticsService,
+		estimator:  estimator,
 		cfg:        cfg,
 
 		log: log,
@@ -66,23 +81,34 @@ func (s *Tracking) Start(ctx context.Context) error {
 		s.log.Info(ctx, types.ActionGracefulShutdown, "tracking service closed!")
 	}()
 
-	// Waiting signal
-	shutdownCh := make(chan os.Signal, 1)
-	signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
+	reloader := newConfigReloader(&s.cfg, s.applyConfig, s.log)
+	reloadCh := reloader.Notify(ctx)
 
 	s.log.Info(ctx, types.ActionServiceStarted, "service started")
 
-	select {
-	case errRun := <-errCh:
-		return errRun
-	case sig := <-shutdownCh:
-		s.log.Info(ctx, types.ActionGracefulShutdown, "shuting down application", "signal", sig.String())
-		return nil
+	for {
+		select {
+		case errRun := <-errCh:
+			return errRun
+		case <-reloadCh:
+			reloader.Reload(ctx)
+		case <-ctx.Done():
+			s.log.Info(ctx, types.ActionGracefulShutdown, "shutting down service")
+			return nil
+		}
 	}
 }
 
+// applyConfig applies reloaded settings.
+func (s *Tracking) applyConfig(ctx context.Context, cfg config.Config) {
+	s.service.SetHeartbeatInterval(cfg.Services.Tracking.HeartbeatInterval)
+	s.estimator.SetHeartbeatInterval(cfg.Services.Tracking.HeartbeatInterval)
+	s.analytics.SetSLA(cfg.Services.Tracking.SLA)
+}
+
 func (s *Tracking) close(ctx context.Context) {
-	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	// Service is closed after its context is cancelled.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second*10)
 	defer cancel()
 
 	if err := s.httpServer.Stop(ctx); err != nil {
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/config"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// stableRun is how long a service must run before its failures are counted from zero again.
+const stableRun = time.Minute
+
+// component is a service run by the supervisor. A stopped service is created again on restart.
+type component struct {
+	name    string
+	service Service // nil once stopped
+
+	cfg config.Config
+	log logger.Logger
+}
+
+// notifyShutdown returns ctx which is cancelled on SIGINT or SIGTERM. It is the only shutdown path of services.
+// A second signal kills the process.
+func (app *App) notifyShutdown(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		defer signal.Stop(sigCh)
+
+		select {
+		case sig := <-sigCh:
+			app.log.Info(ctx, types.ActionGracefulShutdown, "shutting down application", "signal", sig.String())
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// supervise runs every component until ctx is cancelled or every component stopped by itself.
+// A component which can't be restarted anymore stops the others.
+func (app *App) supervise(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, c := range app.components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := app.run(ctx, c); err != nil {
+				// Error of a single service is returned as is.
+				if len(app.components) > 1 {
+					err = fmt.Errorf("%s: %w", c.name, err)
+				}
+
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+
+				cancel()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// run runs the service of c and restarts it after a failure, at most restartAttempts times in a row.
+// nil is returned if the service stopped by itself or ctx was cancelled.
+func (app *App) run(ctx context.Context, c *component) error {
+	failures := 0
+
+	for {
+		started := time.Now()
+
+		err := c.start(ctx)
+		if err == nil || ctx.Err() != nil {
+			return nil
+		}
+
+		if time.Since(started) >= stableRun {
+			failures = 0
+		}
+		failures++
+
+		if failures > app.restartAttempts {
+			return err
+		}
+
+		c.log.Error(ctx, types.ActionServiceFailed, "service failed", err, "attempt", failures, "restart-in", app.restartDelay.String())
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(app.restartDelay):
+		}
+
+		c.log.Info(ctx, types.ActionServiceRestarted, "restarting service", "attempt", failures)
+	}
+}
+
+// start creates the service if it was stopped before and runs it.
+func (c *component) start(ctx context.Context) error {
+	if c.service == nil {
+		service, err := newService(ctx, c.cfg, c.log)
+		if err != nil {
+			return err
+		}
+		c.service = service
+	}
+	defer func() { c.service = nil }()
+
+	return c.service.Start(ctx)
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// AgingRule promotes an order which has been waiting in the queue for After to at least Priority.
+type AgingRule struct {
+	After    time.Duration
+	Priority int
+}
+
+// ParseAgingRules parses comma-separated rules in form `<wait>:<priority>`, e.g. "3m:5,6m:10".
+// Rules are returned sorted by wait time. Empty string means no aging.
+func ParseAgingRules(s string) ([]AgingRule, error) {
+	var rules []AgingRule
+
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		after, priority, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid aging rule %q: expected <wait>:<priority>", part)
+		}
+
+		d, err := time.ParseDuration(after)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid aging rule %q: wait must be a positive duration", part)
+		}
+
+		p, err := strconv.Atoi(priority)
+		if err != nil || p < 1 || p > types.MaxOrderPriority {
+			return nil, fmt.Errorf("invalid aging rule %q: priority must be between 1 and %d", part, types.MaxOrderPriority)
+		}
+
+		rules = append(rules, AgingRule{After: d, Priority: p})
+	}
+
+	slices.SortFunc(rules, func(a, b AgingRule) int {
+		return int(a.After - b.After)
+	})
+
+	return rules, nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// AnalyticsWindow is a time range of kitchen analytics of a location.
+type AnalyticsWindow struct {
+	LocationID string
+	From       time.Time // inclusive
+	To         time.Time // exclusive
+}
+
+// ThroughputPoint is the number of orders of one type that became ready within a bucket.
+type ThroughputPoint struct {
+	BucketStart time.Time `json:"bucket_start"`
+	OrderType   string    `json:"order_type"`
+	Orders      int       `json:"orders"`
+}
+
+// DurationStats are percentiles of a duration in seconds.
+type DurationStats struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50_seconds"`
+	P90   float64 `json:"p90_seconds"`
+	P99   float64 `json:"p99_seconds"`
+}
+
+type KitchenDurations struct {
+	QueueWait DurationStats `json:"queue_wait"` // received -> cooking
+	Cooking   DurationStats `json:"cooking"`    // cooking -> ready
+}
+
+type WorkerPerformance struct {
+	Worker  string        `json:"worker_name"`
+	Orders  int           `json:"orders_processed"`
+	Cooking DurationStats `json:"cooking"`
+}
+
+// SLAReport is the share of orders which were not ready within SLA after they were received.
+// Orders that are still not ready count as breached once SLA has passed.
+type SLAReport struct {
+	SLASeconds    float64 `json:"sla_seconds"`
+	Orders        int     `json:"orders"`
+	Breached      int     `json:"breached"`
+	BreachedShare float64 `json:"breached_share"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// BatchResult is the result of one order of a batch import. Err is set if the order was not created.
+type BatchResult struct {
+	Info *OrderCreatedInfo
+	Err  error
+}
+
+// BatchSummary counts results of a batch import.
+type BatchSummary struct {
+	Total   int `json:"total"`
+	Created int `json:"created"`
+	Invalid int `json:"invalid"` // rejected by validation, before or during creation
+	Failed  int `json:"failed"`  // not created because of a server error
+}
+
+// Add adds counts of another summary, e.g. of the next chunk of an import.
+func (s *BatchSummary) Add(other BatchSummary) {
+	s.Total += other.Total
+	s.Created += other.Created
+	s.Invalid += other.Invalid
+	s.Failed += other.Failed
+}
+
+// BatchRejection is an order of a batch import that was not created.
+type BatchRejection struct {
+	Index  int // index of the order in the batch
+	Reason string
+}
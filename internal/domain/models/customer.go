@@ -0,0 +1,24 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrCustomerNotFound  = errors.New("customer is not found")
+	ErrCustomerExists    = errors.New("customer with this phone already exists")
+	ErrCustomerNoAddress = errors.New("customer has no default address, delivery_address is required")
+)
+
+// Customer is an account orders can refer to.
+type Customer struct {
+	ID             int       `json:"id"`
+	Name           string    `json:"name"`
+	Phone          *string   `json:"phone,omitempty"` // digits with optional leading '+'
+	Email          *string   `json:"email,omitempty"`
+	DefaultAddress *string   `json:"default_address,omitempty"`
+	LoyaltyPoints  int       `json:"loyalty_points"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -5,5 +5,20 @@ import "errors"
 var (
 	ErrWorkerNotFound      = errors.New("worker is not found")
 	ErrOrderNotFound       = errors.New("order is not found")
+	ErrOrderNotCancellable = errors.New("only scheduled orders and orders waiting for payment can be cancelled")
 	ErrWorkerAlreadyOnline = errors.New("worker already exists and is online")
+	ErrWorkerOffline       = errors.New("worker is offline")
+	ErrWorkerReplaced      = errors.New("worker is replaced by a new instance")
+
+	// ErrOrderSuperseded is returned when an order message is older than the order, i.e. the order was
+	// republished with a higher priority by aging or with a new version after modification.
+	ErrOrderSuperseded = errors.New("order message is superseded")
+
+	ErrOrderNotModifiable  = errors.New("only received orders can be modified")
+	ErrOrderPaid           = errors.New("orders with a payment can't be modified")
+	ErrInvalidModification = errors.New("invalid order modification")
+
+	// ErrStationDone is returned when items of a kitchen station are already ready,
+	// i.e. the station message is a duplicate.
+	ErrStationDone = errors.New("station items already ready")
 )
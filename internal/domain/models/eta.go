@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ETAInputs is the kitchen state an order's ready time is predicted from.
+type ETAInputs struct {
+	QueueAhead    int           // received orders of the type queued before the order, the order included
+	OnlineWorkers int           // online workers of the location able to cook the type
+	CookTime      DurationStats // recent cooking -> ready durations of the type
+}
+
+// ETA is a predicted ready time of an order which is still waiting in the queue.
+type ETA struct {
+	ReadyAt       time.Time `json:"estimated_ready_at"`
+	Confidence    float64   `json:"confidence"` // 0..1
+	QueuePosition int       `json:"queue_position"`
+	OnlineWorkers int       `json:"online_workers"`
+
+	DeliveryAt *time.Time `json:"estimated_delivery_at,omitempty"` // only for delivery orders of a delivery zone
+}
+
+// AddTravel sets estimated delivery time, travel time after the ready time.
+func (e *ETA) AddTravel(travel time.Duration) {
+	if travel <= 0 {
+		return
+	}
+	deliveryAt := e.ReadyAt.Add(travel)
+	e.DeliveryAt = &deliveryAt
+}
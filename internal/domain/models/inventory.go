@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrOutOfStock = errors.New("out of stock")
+
+// StockItem is stock of an ingredient at a location.
+type StockItem struct {
+	LocationID        string    `json:"location_id"`
+	Ingredient        string    `json:"ingredient"`
+	Unit              string    `json:"unit"`
+	Quantity          float64   `json:"quantity"`
+	Reserved          float64   `json:"reserved"`  // by orders which are not ready yet
+	Available         float64   `json:"available"` // quantity - reserved
+	LowStockThreshold float64   `json:"low_stock_threshold"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Restock adds quantity to stock of the ingredient. Unit and threshold are changed only if set.
+type Restock struct {
+	LocationID        string
+	Ingredient        string
+	Quantity          float64
+	Unit              *string
+	LowStockThreshold *float64
+}
+
+type RecipeIngredient struct {
+	Ingredient string  `json:"ingredient"`
+	Quantity   float64 `json:"quantity"` // per one unit of the item
+}
+
+// Recipe maps a menu item to its ingredients.
+type Recipe struct {
+	ItemName    string             `json:"item_name"`
+	Ingredients []RecipeIngredient `json:"ingredients"`
+	Available   bool               `json:"available"` // false if the item is 86'd at the location
+}
+
+// LowStockAlert is published when available stock of an ingredient drops below its threshold.
+type LowStockAlert struct {
+	LocationID string    `json:"location_id"`
+	Ingredient string    `json:"ingredient"`
+	Unit       string    `json:"unit"`
+	Available  float64   `json:"available"`
+	Threshold  float64   `json:"low_stock_threshold"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// OutOfStockError lists items of an order which can't be made from the stock of the location.
+type OutOfStockError struct {
+	Items []string
+}
+
+func (e *OutOfStockError) Error() string {
+	return "out of stock: " + strings.Join(e.Items, ", ")
+}
+
+func (e *OutOfStockError) Unwrap() error {
+	return ErrOutOfStock
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// LoadReport is the summary of a load generator run.
+type LoadReport struct {
+	Profile     string         `json:"profile"`
+	TargetRate  float64        `json:"target_rate"` // requests per second
+	Duration    string         `json:"duration"`
+	StartedAt   time.Time      `json:"started_at"`
+	FinishedAt  time.Time      `json:"finished_at"`
+	LocationID  string         `json:"location_id"`
+	TargetURL   string         `json:"target_url"`
+	Sent        int            `json:"sent"`
+	Skipped     int            `json:"skipped"` // not sent because too many requests were waiting for a response
+	SentRate    float64        `json:"sent_rate"`
+	Created     int            `json:"created"`
+	Ready       int            `json:"ready"` // created orders which became ready before the drain timeout
+	NotReady    int            `json:"not_ready"`
+	Errors      int            `json:"errors"` // requests without a response, e.g. connection refused or timeout
+	StatusCodes map[string]int `json:"status_codes"`
+
+	Latency     Percentiles `json:"latency_ms"`       // from sending a request to its response
+	TimeToReady Percentiles `json:"time_to_ready_ms"` // from sending a request to the ready notification of the order
+}
+
+// Percentiles of durations in milliseconds.
+type Percentiles struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// NewPercentiles returns nearest-rank percentiles of the durations.
+func NewPercentiles(durations []time.Duration) Percentiles {
+	if len(durations) == 0 {
+		return Percentiles{}
+	}
+
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+		return millis(sorted[max(i, 0)])
+	}
+
+	return Percentiles{
+		Count: len(sorted),
+		P50:   rank(50),
+		P90:   rank(90),
+		P95:   rank(95),
+		P99:   rank(99),
+		Max:   millis(sorted[len(sorted)-1]),
+	}
+}
+
+func millis(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits of request validation the modified order must stay within.
+const (
+	MaxOrderItems   = 20
+	MaxItemQuantity = 10
+)
+
+// OrderModification changes items of an order still waiting in the queue.
+// Items are matched by name, case-insensitively.
+type OrderModification struct {
+	Add    []CreateOrderItem // added items, quantity of an item already in the order is increased
+	Remove []string          // names of removed items
+	Change []ItemChange      // new quantities of items in the order
+	Note   string            // optional, stored in the status log
+}
+
+type ItemChange struct {
+	Name     string
+	Quantity int
+}
+
+// Modify applies the modification to items of the order and returns notes describing it.
+// It returns ErrInvalidModification if an item is not in the order or no items are left.
+func (m *CreateOrder) Modify(mod OrderModification) (string, error) {
+	var notes []string
+
+	for _, name := range mod.Remove {
+		i := m.itemIndex(name)
+		if i < 0 {
+			return "", fmt.Errorf("%w: %s is not in the order", ErrInvalidModification, name)
+		}
+		m.Items = append(m.Items[:i], m.Items[i+1:]...)
+		notes = append(notes, "removed "+name)
+	}
+
+	for _, change := range mod.Change {
+		i := m.itemIndex(change.Name)
+		if i < 0 {
+			return "", fmt.Errorf("%w: %s is not in the order", ErrInvalidModification, change.Name)
+		}
+		notes = append(notes, fmt.Sprintf("changed %s from %d to %d", m.Items[i].Name, m.Items[i].Quantity, change.Quantity))
+		m.Items[i].Quantity = change.Quantity
+	}
+
+	for _, item := range mod.Add {
+		if i := m.itemIndex(item.Name); i >= 0 {
+			m.Items[i].Quantity += item.Quantity
+			if m.Items[i].Quantity > MaxItemQuantity {
+				return "", fmt.Errorf("%w: at most %d of %s", ErrInvalidModification, MaxItemQuantity, item.Name)
+			}
+		} else {
+			m.Items = append(m.Items, item)
+		}
+		notes = append(notes, fmt.Sprintf("added %d x %s", item.Quantity, item.Name))
+	}
+
+	if len(m.Items) == 0 {
+		return "", fmt.Errorf("%w: order must keep at least one item", ErrInvalidModification)
+	}
+	if len(m.Items) > MaxOrderItems {
+		return "", fmt.Errorf("%w: at most %d items", ErrInvalidModification, MaxOrderItems)
+	}
+
+	if mod.Note != "" {
+		notes = append(notes, mod.Note)
+	}
+
+	return strings.Join(notes, "; "), nil
+}
+
+func (m *CreateOrder) itemIndex(name string) int {
+	for i, item := range m.Items {
+		if strings.EqualFold(item.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
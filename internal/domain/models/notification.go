@@ -2,7 +2,14 @@ package models
 
 import "time"
 
+// Notification is a message of the notifications exchange. Exactly one of the fields is set.
+type Notification struct {
+	StatusUpdate *StatusUpdate
+	LowStock     *LowStockAlert
+}
+
 type StatusUpdate struct {
+	LocationID  string    `json:"location_id"`
 	OrderNumber string    `json:"order_number"`
 	OldStatus   string    `json:"old_status"`
 	NewStatus   string    `json:"new_status"`
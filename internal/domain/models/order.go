@@ -3,22 +3,41 @@ package models
 import (
 	"fmt"
 	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
 )
 
 type Order struct {
 	ID              int
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
+	LocationID      string
 	Number          string
 	CustomerName    string
+	CustomerID      *int    // nullable, anonymous orders have no customer
 	Type            string  // 'dine_in', 'takeout', or 'delivery'
 	TableNumber     *int    // nullable
 	DeliveryAddress *string // nullable
-	TotalAmount     float64 // decimal(10,2)
+	DeliveryZone    *string // nullable, set if delivery zones are configured
+	DeliveryFee     float64 // decimal(10,2)
+	Subtotal        float64 // decimal(10,2), gross amount of the items
+	TaxAmount       float64 // decimal(10,2)
+	ServiceCharge   float64 // decimal(10,2), dine_in only
+	Tip             float64 // decimal(10,2)
+	TotalAmount     float64 // decimal(10,2), subtotal - discounts + tax + service charge + delivery fee + tip
+	PointsEarned    int     // loyalty points credited to the customer when the order is ready
+	PointsRedeemed  int
 	Priority        int
 	Status          string
+	Version         int        // incremented by every modification, kitchen skips messages of older versions
 	ProcessedBy     *string    // nullable
 	CompletedAt     *time.Time // nullable
+	ScheduledFor    *time.Time // nullable, pickup or delivery time of a scheduled order
+	Items           []OrderItem
+	Discounts       []Discount
+	Payment         *Payment // nil if the order is not paid through payment-service
+
+	LowStock []StockItem // set by Create, ingredients whose stock dropped below threshold by the order
 }
 
 type OrderItem struct {
@@ -28,26 +47,52 @@ type OrderItem struct {
 	Name      string
 	Quantity  int
 	Price     float64 // decimal(8,2)
+	Station   *string // nullable, station the item is cooked at
+	Category  string  // tax category
+	TaxRate   float64 // numeric(5,2), percent
+	Status    string
 }
 
 type CreateOrder struct {
+	LocationID      string
 	Number          string
 	CustomerName    string
+	CustomerID      *int // optional customer account
 	Type            string
 	Items           []CreateOrderItem
 	TableNumber     *int    // Only for dine_in
 	DeliveryAddress *string // Only for delivery
-	TotalAmount     float64
+	DeliveryZone    string  // Only for delivery, set if delivery zones are configured
+	DeliveryFee     float64
+	TravelTime      time.Duration // travel time of the delivery zone, added to the ETA
+	DistanceKm      *float64      // distance from the restaurant to the delivery address, nil if unknown
+	PromoCode       string        // optional, upper case
+	RedeemPoints    int           // loyalty points to pay with, requires customer
+	Subtotal        float64       // gross amount of the items, priority is decided on it
+	Discounts       []Discount
+	TaxAmount       float64
+	ServiceCharge   float64 // Only for dine_in
+	Tip             float64 // optional
+	TotalAmount     float64 // net of discounts, then with tax, service charge, delivery fee and tip added by ApplyCharges
+	PointsEarned    int
 	Priority        int
 	Status          string
+	Version         int        // version of the order the message was published for
+	ScheduledFor    *time.Time // Only for scheduled orders
+	ReleaseAt       *time.Time // when scheduled order is published to the kitchen
+	Station         string     // set in kitchen station messages, items are of this station only
 }
 type CreateOrderItem struct {
 	Name     string
 	Quantity int
 	Price    float64
+	Station  string // empty if the order is cooked whole
+	Category string // tax category
+	TaxRate  float64
 }
 
-// CalucalteTotalAmount sets total amount. Sum the price * quantity for all items in the order.
+// CalucalteTotalAmount sets subtotal and total amount. Sum the price * quantity for all items in the order.
+// Discounts are applied to the total amount afterwards with AddDiscount.
 func (m *CreateOrder) CalucalteTotalAmount() {
 	var total float64
 
@@ -55,22 +100,55 @@ func (m *CreateOrder) CalucalteTotalAmount() {
 		total += item.Price * float64(item.Quantity)
 	}
 
-	m.TotalAmount = total
+	m.Subtotal = RoundMoney(total)
+	m.TotalAmount = m.Subtotal
+	m.Discounts = nil
+}
+
+// AddDiscount subtracts discount from the total amount. The discount is capped at the remaining total.
+func (m *CreateOrder) AddDiscount(d Discount) {
+	d.Amount = RoundMoney(min(d.Amount, m.TotalAmount))
+	m.TotalAmount = RoundMoney(m.TotalAmount - d.Amount)
+	m.Discounts = append(m.Discounts, d)
 }
 
-// CalculatePriority sets priority
-// '10' if Order total amount is greater than $100.
-// '5'	if Order total amount is between $50 and $100.
-// '1'	if All other standard orders.
-func (m *CreateOrder) CalculatePriority() {
-	switch {
-	case m.TotalAmount > 100:
-		m.Priority = 10
-	case m.TotalAmount > 50:
-		m.Priority = 5
-	default:
-		m.Priority = 1
+// ApplyCharges adds tax of the items, service charge of dine_in orders (percent of the net amount), delivery fee
+// and tip to the total amount. It is called after discounts.
+func (m *CreateOrder) ApplyCharges(serviceChargeRate float64) {
+	net := m.TotalAmount
+
+	lines := make([]TaxableLine, len(m.Items))
+	for i, item := range m.Items {
+		lines[i] = TaxableLine{Category: item.Category, Rate: item.TaxRate, Amount: item.Price * float64(item.Quantity)}
+	}
+	m.TaxAmount = TotalTax(CalculateTax(lines, m.Subtotal, net))
+
+	m.ServiceCharge = 0
+	if m.Type == types.OrderTypeDineIn {
+		m.ServiceCharge = RoundMoney(net * serviceChargeRate / 100)
 	}
+
+	m.DeliveryFee = RoundMoney(m.DeliveryFee)
+	m.Tip = RoundMoney(m.Tip)
+	m.TotalAmount = RoundMoney(net + m.TaxAmount + m.ServiceCharge + m.DeliveryFee + m.Tip)
+}
+
+// Stations groups items by kitchen station in order of first appearance.
+// It returns nil if the order is cooked whole.
+func (m *CreateOrder) Stations() (stations []string, items map[string][]CreateOrderItem) {
+	for _, item := range m.Items {
+		if item.Station == "" {
+			continue
+		}
+		if items == nil {
+			items = make(map[string][]CreateOrderItem)
+		}
+		if _, ok := items[item.Station]; !ok {
+			stations = append(stations, item.Station)
+		}
+		items[item.Station] = append(items[item.Station], item)
+	}
+	return stations, items
 }
 
 // - **Generate `order_number`:** Create a unique order number using the format `ORD_YYYYMMDD_NNN`.
@@ -88,7 +166,24 @@ func (m *CreateOrder) SetNumber(date string, sequence int) {
 }
 
 type OrderCreatedInfo struct {
+	LocationID  string
 	Number      string
 	Status      string
 	TotalAmount float64
+	ETA         *ETA // nil if estimation failed or order is scheduled
+
+	Subtotal       float64
+	Discounts      []Discount
+	TaxAmount      float64
+	ServiceCharge  float64
+	DeliveryFee    float64
+	DeliveryZone   string
+	Tip            float64
+	PointsEarned   int
+	PointsRedeemed int
+
+	ScheduledFor *time.Time
+	ReleaseAt    *time.Time
+
+	CoverageWarning string // set if no kitchen shift covers the order type
 }
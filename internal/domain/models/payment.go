@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// ErrPaymentDeclined is returned by payment providers, wrapped with the decline reason.
+var ErrPaymentDeclined = errors.New("payment declined")
+
+// Payment of an order, made by payment-service before the order is sent to the kitchen.
+type Payment struct {
+	ID           int        `json:"-"`
+	OrderID      int        `json:"-"`
+	LocationID   string     `json:"-"`
+	OrderNumber  string     `json:"-"`
+	Status       string     `json:"status"`
+	Amount       float64    `json:"amount"`
+	Provider     string     `json:"provider,omitempty"`
+	ProviderRef  *string    `json:"provider_ref,omitempty"`
+	Attempts     int        `json:"attempts"`
+	LastError    *string    `json:"last_error,omitempty"`
+	AuthorizedAt *time.Time `json:"authorized_at,omitempty"`
+	RefundedAt   *time.Time `json:"refunded_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// PaymentRequest is what a payment provider is asked to authorize.
+type PaymentRequest struct {
+	Reference string // idempotency key, the same for every attempt of a payment
+	Amount    float64
+}
+
+// Reference returns idempotency key of the payment for payment providers.
+func (p Payment) Reference() string {
+	return fmt.Sprintf("%s/%s", p.LocationID, p.OrderNumber)
+}
+
+// ParsePaymentScript parses comma-separated outcomes of the fake payment provider.
+func ParsePaymentScript(s string) ([]string, error) {
+	var script []string
+	for outcome := range strings.SplitSeq(s, ",") {
+		outcome = strings.TrimSpace(outcome)
+		switch outcome {
+		case types.FakePaymentApprove, types.FakePaymentDecline, types.FakePaymentTimeout:
+			script = append(script, outcome)
+		default:
+			return nil, fmt.Errorf("unknown outcome %q, must be one of: approve, decline, timeout", outcome)
+		}
+	}
+	return script, nil
+}
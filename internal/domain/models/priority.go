@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// PriorityInput is what a priority policy knows about a new order.
+type PriorityInput struct {
+	TotalAmount float64 // gross amount of the items, before discounts
+	OrderType   string
+	ItemCount   int // sum of item quantities
+	VIP         bool
+	CreatedAt   time.Time
+	DistanceKm  *float64 // delivery distance, nil if unknown
+}
+
+// PriorityDecision is the priority of an order and the rule it came from.
+type PriorityDecision struct {
+	Priority int           `json:"priority"`
+	Rule     string        `json:"rule"`    // name of the matched rule, empty if no rule matched
+	Reasons  []string      `json:"reasons"` // conditions of the matched rule
+	Skipped  []SkippedRule `json:"skipped,omitempty"`
+}
+
+// SkippedRule is a rule checked before the matched one, with the first condition it failed.
+type SkippedRule struct {
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+var (
+	ErrPromotionNotFound      = errors.New("promo code is not found")
+	ErrPromotionExists        = errors.New("promotion with this code already exists")
+	ErrPromotionInactive      = errors.New("promo code is expired or not active yet")
+	ErrPromotionExhausted     = errors.New("promo code usage limit is reached")
+	ErrPromotionNotApplicable = errors.New("promo code does not apply to the order items")
+	ErrNotEnoughPoints        = errors.New("customer does not have enough loyalty points")
+)
+
+// Promotion is a promo code customers enter at checkout.
+type Promotion struct {
+	ID          int        `json:"id"`
+	Code        string     `json:"code"` // upper case
+	Kind        string     `json:"kind"`
+	Value       float64    `json:"value,omitempty"`     // percent for 'percent', amount for 'fixed'
+	ItemName    *string    `json:"item_name,omitempty"` // menu item of 'buy_x_get_y'
+	BuyQuantity int        `json:"buy_quantity,omitempty"`
+	GetQuantity int        `json:"get_quantity,omitempty"`
+	MaxUses     *int       `json:"max_uses,omitempty"` // nil is unlimited
+	Uses        int        `json:"uses"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Usable checks validity period and usage limit of the promotion at t.
+// The usage limit is checked again when the order is stored, as uses may change in between.
+func (p Promotion) Usable(t time.Time) error {
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return ErrPromotionInactive
+	}
+	if p.ExpiresAt != nil && !t.Before(*p.ExpiresAt) {
+		return ErrPromotionInactive
+	}
+	if p.MaxUses != nil && p.Uses >= *p.MaxUses {
+		return ErrPromotionExhausted
+	}
+	return nil
+}
+
+// Discount returns discount of the promotion on the order items with the given subtotal.
+func (p Promotion) Discount(items []CreateOrderItem, subtotal float64) (Discount, error) {
+	d := Discount{
+		Kind:        p.Kind,
+		PromotionID: &p.ID,
+		Code:        p.Code,
+	}
+
+	switch p.Kind {
+	case types.PromotionPercent:
+		d.Amount = RoundMoney(subtotal * p.Value / 100)
+		d.Description = fmt.Sprintf("%s%% off", formatNumber(p.Value))
+	case types.PromotionFixed:
+		d.Amount = math.Min(p.Value, subtotal)
+		d.Description = fmt.Sprintf("%.2f off", p.Value)
+	case types.PromotionBuyXGetY:
+		if p.ItemName == nil || p.BuyQuantity+p.GetQuantity == 0 {
+			return Discount{}, ErrPromotionNotApplicable
+		}
+
+		// Free units are priced at the cheapest price the item is ordered at.
+		var (
+			quantity int
+			price    float64
+		)
+		for _, item := range items {
+			if !strings.EqualFold(item.Name, *p.ItemName) {
+				continue
+			}
+			if quantity == 0 || item.Price < price {
+				price = item.Price
+			}
+			quantity += item.Quantity
+		}
+
+		free := quantity / (p.BuyQuantity + p.GetQuantity) * p.GetQuantity
+		d.Amount = RoundMoney(float64(free) * price)
+		d.ItemName = p.ItemName
+		d.Description = fmt.Sprintf("buy %d get %d free: %d x %s", p.BuyQuantity, p.GetQuantity, free, *p.ItemName)
+	default:
+		return Discount{}, fmt.Errorf("unknown promotion kind %q", p.Kind)
+	}
+
+	if d.Amount <= 0 {
+		return Discount{}, ErrPromotionNotApplicable
+	}
+	return d, nil
+}
+
+// Discount is a line of the order discount breakdown.
+type Discount struct {
+	Kind        string  `json:"kind"` // promotion kind or 'loyalty'
+	PromotionID *int    `json:"-"`
+	Code        string  `json:"code,omitempty"`
+	ItemName    *string `json:"item_name,omitempty"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+// RoundMoney rounds amount to cents.
+func RoundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// formatNumber formats v without trailing zeros, e.g. 10 or 12.5.
+func formatNumber(v float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
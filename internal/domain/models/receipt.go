@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// Receipt is the itemized bill of an order.
+type Receipt struct {
+	LocationID   string        `json:"location_id"`
+	OrderNumber  string        `json:"order_number"`
+	CustomerName string        `json:"customer_name"`
+	OrderType    string        `json:"order_type"`
+	TableNumber  *int          `json:"table_number,omitempty"`
+	Status       string        `json:"status"`
+	CreatedAt    time.Time     `json:"created_at"`
+	Lines        []ReceiptLine `json:"lines"`
+
+	Subtotal      float64    `json:"subtotal"`
+	Discounts     []Discount `json:"discounts,omitempty"`
+	Net           float64    `json:"net"` // subtotal - discounts
+	Taxes         []TaxLine  `json:"taxes,omitempty"`
+	TaxAmount     float64    `json:"tax_amount"`
+	ServiceCharge float64    `json:"service_charge,omitempty"`
+	DeliveryFee   float64    `json:"delivery_fee,omitempty"`
+	Tip           float64    `json:"tip,omitempty"`
+	Total         float64    `json:"total"`
+
+	PointsEarned   int `json:"points_earned,omitempty"`
+	PointsRedeemed int `json:"points_redeemed,omitempty"`
+}
+
+// ReceiptLine is an order item on the receipt.
+type ReceiptLine struct {
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	Amount    float64 `json:"amount"`
+	Category  string  `json:"category"`
+	TaxRate   float64 `json:"tax_rate"`
+}
+
+// NewReceipt makes receipt of the order. Tax per category is calculated again from the rates stored with
+// the items, the same way as when the order was created.
+func NewReceipt(order Order) Receipt {
+	r := Receipt{
+		LocationID:     order.LocationID,
+		OrderNumber:    order.Number,
+		CustomerName:   order.CustomerName,
+		OrderType:      order.Type,
+		TableNumber:    order.TableNumber,
+		Status:         order.Status,
+		CreatedAt:      order.CreatedAt,
+		Subtotal:       order.Subtotal,
+		Discounts:      order.Discounts,
+		TaxAmount:      order.TaxAmount,
+		ServiceCharge:  order.ServiceCharge,
+		DeliveryFee:    order.DeliveryFee,
+		Tip:            order.Tip,
+		Total:          order.TotalAmount,
+		PointsEarned:   order.PointsEarned,
+		PointsRedeemed: order.PointsRedeemed,
+	}
+
+	r.Net = order.Subtotal
+	for _, d := range order.Discounts {
+		r.Net -= d.Amount
+	}
+	r.Net = RoundMoney(r.Net)
+
+	taxable := make([]TaxableLine, len(order.Items))
+	for i, item := range order.Items {
+		amount := RoundMoney(item.Price * float64(item.Quantity))
+		r.Lines = append(r.Lines, ReceiptLine{
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+			Amount:    amount,
+			Category:  item.Category,
+			TaxRate:   item.TaxRate,
+		})
+		taxable[i] = TaxableLine{Category: item.Category, Rate: item.TaxRate, Amount: item.Price * float64(item.Quantity)}
+	}
+	r.Taxes = CalculateTax(taxable, order.Subtotal, r.Net)
+
+	return r
+}
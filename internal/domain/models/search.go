@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	DefaultOrdersLimit = 20
+	MaxOrdersLimit     = 100
+)
+
+// OrderFilter describes search of orders. Empty fields are not filtered.
+type OrderFilter struct {
+	LocationID     string // empty matches all locations
+	CustomerID     *int
+	Status         string
+	Type           string
+	From           *time.Time // created_at >= From
+	To             *time.Time // created_at < To
+	CustomerPrefix string
+	Worker         string // processed_by
+	Priority       *int
+
+	// Keyset pagination on (created_at, id)
+	After *OrderCursor
+	Limit int
+	Asc   bool // sort by created_at ascending, newest first by default
+}
+
+// OrderCursor points to the last order of the previous page.
+type OrderCursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+type OrderPage struct {
+	Orders []Order
+	Next   *OrderCursor // nil on the last page
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// Shift is a weekly window a kitchen worker is on duty, in local time.
+type Shift struct {
+	WorkerName string       `json:"worker_name"`
+	Weekday    time.Weekday `json:"weekday"` // day the shift starts, 0 is Sunday
+	Start      string       `json:"start"`   // HH:MM
+	End        string       `json:"end"`     // HH:MM, at or before start for shifts over midnight
+	OrderTypes []string     `json:"order_types,omitempty"`
+}
+
+// ShiftSchedule is shifts of a location with windows of the week no shift covers, by order type.
+type ShiftSchedule struct {
+	Shifts    []Shift               `json:"shifts"`
+	Uncovered map[string][]ShiftGap `json:"uncovered"`
+}
+
+// ShiftGap is a weekly window no shift covers.
+type ShiftGap struct {
+	From string `json:"from"` // e.g. "Mon 09:00"
+	To   string `json:"to"`
+}
+
+// ParseShiftTime parses HH:MM into minutes after midnight.
+func ParseShiftTime(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, must be HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// window returns start and end of the shift in minutes of the week. End may exceed the week.
+func (s Shift) window() (int, int) {
+	start, _ := ParseShiftTime(s.Start)
+	end, _ := ParseShiftTime(s.End)
+	if end <= start {
+		end += minutesPerDay
+	}
+
+	day := int(s.Weekday) * minutesPerDay
+	return day + start, day + end
+}
+
+// Covers reports whether the shift cooks orders of the type.
+func (s Shift) Covers(orderType string) bool {
+	return len(s.OrderTypes) == 0 || slices.Contains(s.OrderTypes, orderType)
+}
+
+// Contains reports whether t is inside the shift.
+func (s Shift) Contains(t time.Time) bool {
+	start, end := s.window()
+	m := minuteOfWeek(t)
+	return (m >= start && m < end) || (m+minutesPerWeek >= start && m+minutesPerWeek < end)
+}
+
+// untilStart returns time from t to the next start of the shift.
+func (s Shift) untilStart(t time.Time) time.Duration {
+	start, _ := s.window()
+	minutes := (start - minuteOfWeek(t) + minutesPerWeek) % minutesPerWeek
+	return time.Duration(minutes) * time.Minute
+}
+
+// OnDuty reports whether a worker with the shifts is on duty at t. Workers without shifts are always on duty.
+func OnDuty(shifts []Shift, t time.Time) bool {
+	if len(shifts) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(shifts, func(s Shift) bool { return s.Contains(t) })
+}
+
+// Duty returns duty of a worker with the shifts at t: on duty inside a shift, scheduled if
+// a shift starts within a day and off duty otherwise. Empty for workers without shifts.
+func Duty(shifts []Shift, t time.Time) string {
+	if len(shifts) == 0 {
+		return ""
+	}
+
+	duty := types.DutyOffDuty
+	for _, s := range shifts {
+		if s.Contains(t) {
+			return types.DutyOnDuty
+		}
+		if s.untilStart(t) <= 24*time.Hour {
+			duty = types.DutyScheduled
+		}
+	}
+	return duty
+}
+
+// Covered reports whether any of the shifts cooks orders of the type at t.
+func Covered(shifts []Shift, orderType string, t time.Time) bool {
+	return slices.ContainsFunc(shifts, func(s Shift) bool { return s.Covers(orderType) && s.Contains(t) })
+}
+
+// CoverageGaps returns weekly windows no shift covers, for each of the order types.
+// Order types covered all week are left out.
+func CoverageGaps(shifts []Shift, orderTypes []string) map[string][]ShiftGap {
+	gaps := make(map[string][]ShiftGap)
+
+	for _, ot := range orderTypes {
+		var covered [minutesPerWeek]bool
+		for _, s := range shifts {
+			if !s.Covers(ot) {
+				continue
+			}
+			start, end := s.window()
+			for m := start; m < end; m++ {
+				covered[m%minutesPerWeek] = true
+			}
+		}
+
+		var windows [][2]int
+		for m := 0; m < minutesPerWeek; m++ {
+			if covered[m] {
+				continue
+			}
+			if n := len(windows); n > 0 && windows[n-1][1] == m {
+				windows[n-1][1] = m + 1
+			} else {
+				windows = append(windows, [2]int{m, m + 1})
+			}
+		}
+
+		// Gap over the end of the week continues from Sunday.
+		if n := len(windows); n > 1 && windows[0][0] == 0 && windows[n-1][1] == minutesPerWeek {
+			windows[0][0] = windows[n-1][0]
+			windows = windows[:n-1]
+		}
+
+		for _, w := range windows {
+			gaps[ot] = append(gaps[ot], ShiftGap{From: formatMinuteOfWeek(w[0]), To: formatMinuteOfWeek(w[1])})
+		}
+	}
+
+	return gaps
+}
+
+func minuteOfWeek(t time.Time) int {
+	return int(t.Weekday())*minutesPerDay + t.Hour()*60 + t.Minute()
+}
+
+func formatMinuteOfWeek(m int) string {
+	m %= minutesPerWeek
+	day := time.Weekday(m / minutesPerDay).String()[:3]
+	return fmt.Sprintf("%s %02d:%02d", day, m%minutesPerDay/60, m%60)
+}
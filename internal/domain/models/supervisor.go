@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// KitchenWorkerSpec is a kitchen worker started by --mode=all.
+type KitchenWorkerSpec struct {
+	Name       string
+	OrderTypes []string // empty for every order type
+}
+
+// ParseKitchenWorkers parses comma-separated workers in form `<name>[:<type>+<type>...]`,
+// e.g. "chef_mario:dine_in+takeout,chef_luigi". A worker without types handles every order type.
+func ParseKitchenWorkers(s string) ([]KitchenWorkerSpec, error) {
+	var workers []KitchenWorkerSpec
+
+	for part := range strings.SplitSeq(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, orderTypes, _ := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		if name == "" || len(name) > 100 {
+			return nil, fmt.Errorf("invalid kitchen worker %q: name must be 1-100 characters", part)
+		}
+
+		if slices.ContainsFunc(workers, func(w KitchenWorkerSpec) bool { return w.Name == name }) {
+			return nil, fmt.Errorf("duplicate kitchen worker %q", name)
+		}
+
+		worker := KitchenWorkerSpec{Name: name}
+		for orderType := range strings.SplitSeq(orderTypes, "+") {
+			orderType = strings.TrimSpace(orderType)
+			if orderType == "" {
+				continue
+			}
+			if !types.IsValidOrderType(orderType) || slices.Contains(worker.OrderTypes, orderType) {
+				return nil, fmt.Errorf("invalid kitchen worker %q: order types must be distinct, one of %v", part, types.AllOrderTypes)
+			}
+			worker.OrderTypes = append(worker.OrderTypes, orderType)
+		}
+
+		workers = append(workers, worker)
+	}
+
+	if len(workers) == 0 {
+		return nil, fmt.Errorf("at least one kitchen worker is required")
+	}
+
+	return workers, nil
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+var (
+	ErrTableNotFound    = errors.New("table not found")
+	ErrTableExists      = errors.New("table already exists")
+	ErrTableNotFree     = errors.New("table is not free")
+	ErrTableClosed      = errors.New("table has no open session")
+	ErrTableNotCleaning = errors.New("table does not need cleaning")
+	ErrTableCapacity    = errors.New("guests exceed capacity of the table")
+	ErrTableNoSessions  = errors.New("table has no sessions")
+)
+
+// Table is a dining table of a location.
+type Table struct {
+	LocationID string        `json:"location_id"`
+	Number     int           `json:"number"`
+	Capacity   int           `json:"capacity"`
+	Status     string        `json:"status"`
+	Session    *TableSession `json:"session,omitempty"` // open session of an occupied table
+	UpdatedAt  time.Time     `json:"updated_at"`
+
+	ID int `json:"-"`
+}
+
+// TableSession is the time guests sit at a table, dine-in orders placed on the table belong to it.
+type TableSession struct {
+	ID       int          `json:"id"`
+	Guests   int          `json:"guests"`
+	OpenedAt time.Time    `json:"opened_at"`
+	ClosedAt *time.Time   `json:"closed_at,omitempty"`
+	Orders   []TableOrder `json:"orders,omitempty"`
+}
+
+// TableOrder is an order of a table session with its amounts.
+type TableOrder struct {
+	Number        string    `json:"order_number"`
+	Status        string    `json:"status"`
+	Subtotal      float64   `json:"subtotal"`
+	Discounts     float64   `json:"discounts,omitempty"`
+	TaxAmount     float64   `json:"tax_amount"`
+	ServiceCharge float64   `json:"service_charge,omitempty"`
+	Tip           float64   `json:"tip,omitempty"`
+	TotalAmount   float64   `json:"total_amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableBill is the combined bill of all orders of a table session. Cancelled orders are listed but not billed.
+type TableBill struct {
+	LocationID  string       `json:"location_id"`
+	TableNumber int          `json:"table_number"`
+	SessionID   int          `json:"session_id"`
+	Guests      int          `json:"guests"`
+	OpenedAt    time.Time    `json:"opened_at"`
+	ClosedAt    *time.Time   `json:"closed_at,omitempty"`
+	Orders      []TableOrder `json:"orders"`
+
+	Subtotal      float64 `json:"subtotal"`
+	Discounts     float64 `json:"discounts,omitempty"`
+	TaxAmount     float64 `json:"tax_amount"`
+	ServiceCharge float64 `json:"service_charge,omitempty"`
+	Tip           float64 `json:"tip,omitempty"`
+	Total         float64 `json:"total"`
+}
+
+// NewTableBill sums the orders of the session.
+func NewTableBill(table Table, session TableSession) TableBill {
+	bill := TableBill{
+		LocationID:  table.LocationID,
+		TableNumber: table.Number,
+		SessionID:   session.ID,
+		Guests:      session.Guests,
+		OpenedAt:    session.OpenedAt,
+		ClosedAt:    session.ClosedAt,
+		Orders:      session.Orders,
+	}
+	if bill.Orders == nil {
+		bill.Orders = []TableOrder{}
+	}
+
+	for _, o := range session.Orders {
+		if o.Status == types.StatusOrderCancelled {
+			continue
+		}
+		bill.Subtotal += o.Subtotal
+		bill.Discounts += o.Discounts
+		bill.TaxAmount += o.TaxAmount
+		bill.ServiceCharge += o.ServiceCharge
+		bill.Tip += o.Tip
+		bill.Total += o.TotalAmount
+	}
+
+	bill.Subtotal = RoundMoney(bill.Subtotal)
+	bill.Discounts = RoundMoney(bill.Discounts)
+	bill.TaxAmount = RoundMoney(bill.TaxAmount)
+	bill.ServiceCharge = RoundMoney(bill.ServiceCharge)
+	bill.Tip = RoundMoney(bill.Tip)
+	bill.Total = RoundMoney(bill.Total)
+
+	return bill
+}
+
+// Floor is the state of all tables of a location.
+type Floor struct {
+	LocationID    string  `json:"location_id"`
+	Tables        []Table `json:"tables"`
+	Free          int     `json:"free"`
+	Occupied      int     `json:"occupied"`
+	NeedsCleaning int     `json:"needs_cleaning"`
+	Guests        int     `json:"guests"` // seated at occupied tables
+}
+
+// NewFloor counts tables by status.
+func NewFloor(locationID string, tables []Table) Floor {
+	floor := Floor{LocationID: locationID, Tables: tables}
+	if floor.Tables == nil {
+		floor.Tables = []Table{}
+	}
+
+	for _, t := range tables {
+		switch t.Status {
+		case types.TableFree:
+			floor.Free++
+		case types.TableOccupied:
+			floor.Occupied++
+		case types.TableNeedsCleaning:
+			floor.NeedsCleaning++
+		}
+		if t.Session != nil {
+			floor.Guests += t.Session.Guests
+		}
+	}
+
+	return floor
+}
@@ -0,0 +1,63 @@
+package models
+
+// DefaultTaxCategory is the category of items no tax category lists.
+const DefaultTaxCategory = "general"
+
+// TaxableLine is an order line with the category and tax rate of its item.
+type TaxableLine struct {
+	Category string
+	Rate     float64 // percent
+	Amount   float64 // price * quantity
+}
+
+// TaxLine is the tax of one category and rate.
+type TaxLine struct {
+	Category string  `json:"category"`
+	Rate     float64 `json:"rate"`    // percent
+	Taxable  float64 `json:"taxable"` // share of the net amount
+	Amount   float64 `json:"amount"`
+}
+
+// CalculateTax returns tax of every category of the lines, in order of first appearance.
+// Discounts are spread over the lines in proportion to their amounts, so a category is taxed
+// on its share of the net amount.
+func CalculateTax(lines []TaxableLine, subtotal, net float64) []TaxLine {
+	if subtotal <= 0 {
+		return nil
+	}
+	ratio := net / subtotal
+
+	type key struct {
+		category string
+		rate     float64
+	}
+	var (
+		taxes []TaxLine
+		index = make(map[key]int)
+	)
+	for _, line := range lines {
+		k := key{line.Category, line.Rate}
+		i, ok := index[k]
+		if !ok {
+			i = len(taxes)
+			index[k] = i
+			taxes = append(taxes, TaxLine{Category: line.Category, Rate: line.Rate})
+		}
+		taxes[i].Taxable += line.Amount * ratio
+	}
+
+	for i := range taxes {
+		taxes[i].Taxable = RoundMoney(taxes[i].Taxable)
+		taxes[i].Amount = RoundMoney(taxes[i].Taxable * taxes[i].Rate / 100)
+	}
+	return taxes
+}
+
+// TotalTax sums tax of the lines.
+func TotalTax(taxes []TaxLine) float64 {
+	var total float64
+	for _, t := range taxes {
+		total += t.Amount
+	}
+	return RoundMoney(total)
+}
@@ -3,15 +3,26 @@ package models
 import "time"
 
 type OrderStatus struct {
-	OrderNumber string     `json:"order_number"`
-	Status      string     `json:"current_status"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	Completion  *time.Time `json:"estimated_completion"` // nullable
-	ProcessedBy *string    `json:"processed_by"`         // nullable
+	LocationID    string     `json:"location_id"`
+	OrderNumber   string     `json:"order_number"`
+	Status        string     `json:"current_status"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Completion    *time.Time `json:"estimated_completion"`     // nullable
+	ProcessedBy   *string    `json:"processed_by"`             // nullable
+	ETA           *ETA       `json:"eta,omitempty"`            // only for received orders
+	ScheduledFor  *time.Time `json:"scheduled_for,omitempty"`  // only for scheduled orders
+	PaymentStatus *string    `json:"payment_status,omitempty"` // only for orders paid through payment-service
+
+	OrderType  string        `json:"-"`
+	QueuedAt   time.Time     `json:"-"` // time the order entered the kitchen queue
+	TravelTime time.Duration `json:"-"` // travel time of the delivery zone
 }
 
 type OrderHistory struct {
 	Status    string    `json:"status"`
 	Timestamp time.Time `json:"timestamp"`
 	ChangedBy string    `json:"changed_by"`
+	Priority  int       `json:"priority"` // effective priority at the moment of the change
+	Notes     string    `json:"notes,omitempty"`
+	Station   string    `json:"station,omitempty"` // set for progress of a kitchen station
 }
@@ -3,8 +3,51 @@ package models
 import "time"
 
 type Worker struct {
-	Name            string    `json:"worker_name"`
-	Status          string    `json:"status"`
-	ProcessedOrders int       `json:"orders_processed"`
-	LastSeen        time.Time `json:"last_seen"`
+	Name              string    `json:"worker_name"`
+	LocationID        string    `json:"location_id"`
+	Status            string    `json:"status"`
+	OrderTypes        string    `json:"order_types"`
+	Stations          string    `json:"stations,omitempty"`
+	Command           *string   `json:"pending_command,omitempty"` // admin command the worker has not picked up yet
+	ProcessedOrders   int       `json:"orders_processed"`
+	ActiveOrders      int       `json:"active_orders"`
+	CurrentOrder      *string   `json:"current_order,omitempty"` // latest order the worker started cooking
+	Version           string    `json:"version,omitempty"`
+	Host              string    `json:"host,omitempty"`
+	PID               int       `json:"pid,omitempty"`
+	HeartbeatInterval int       `json:"heartbeat_interval,omitempty"` // seconds
+	LastSeen          time.Time `json:"last_seen"`
+	Duty              string    `json:"duty,omitempty"` // by shifts: on_duty, scheduled or off_duty
+}
+
+// Heartbeat is the state a worker reports on registration and every heartbeat.
+type Heartbeat struct {
+	Version      string
+	Host         string
+	PID          int
+	OrderTypes   string // comma-separated
+	Stations     string // comma-separated
+	Interval     time.Duration
+	ActiveOrders int
+	CurrentOrder string
+}
+
+// WorkerSession is an online period of a worker.
+type WorkerSession struct {
+	ID              int        `json:"id"`
+	Version         string     `json:"version,omitempty"`
+	Host            string     `json:"host,omitempty"`
+	PID             int        `json:"pid,omitempty"`
+	OrderTypes      string     `json:"order_types"`
+	Stations        string     `json:"stations,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	EndReason       *string    `json:"end_reason,omitempty"` // graceful, evicted or heartbeat_timeout
+	ProcessedOrders int        `json:"orders_processed"`
+}
+
+// WorkerDetail is a worker with its latest sessions.
+type WorkerDetail struct {
+	Worker
+	Sessions []WorkerSession `json:"sessions"`
 }
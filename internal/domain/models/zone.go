@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAddressNotFound     = errors.New("address is not found")
+	ErrOutsideDeliveryZone = errors.New("address is outside of the delivery zones")
+	ErrBelowZoneMinimum    = errors.New("order is below the minimum of the delivery zone")
+)
+
+// GeoPoint is a coordinate in degrees.
+type GeoPoint struct {
+	Lat float64
+	Lng float64
+}
+
+// earthRadiusKm is the mean radius of the Earth.
+const earthRadiusKm = 6371.0
+
+// ParseGeoPoint parses "lat lng" coordinate.
+func ParseGeoPoint(s string) (GeoPoint, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return GeoPoint{}, fmt.Errorf("point %q must be \"lat lng\"", strings.TrimSpace(s))
+	}
+
+	lat, errLat := strconv.ParseFloat(fields[0], 64)
+	lng, errLng := strconv.ParseFloat(fields[1], 64)
+	if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return GeoPoint{}, fmt.Errorf("point %q is not a valid coordinate", strings.TrimSpace(s))
+	}
+	return GeoPoint{Lat: lat, Lng: lng}, nil
+}
+
+// DistanceKm returns great-circle distance between the points.
+func (p GeoPoint) DistanceKm(q GeoPoint) float64 {
+	lat1, lat2 := p.Lat*math.Pi/180, q.Lat*math.Pi/180
+	dLat, dLng := lat2-lat1, (q.Lng-p.Lng)*math.Pi/180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// GeoLocation is a geocoded address. Point is nil if only the postcode of the address is known.
+type GeoLocation struct {
+	Point    *GeoPoint
+	Postcode string
+}
+
+// DeliveryZone is an area delivery orders are accepted from, given as a polygon or a list of postcodes.
+type DeliveryZone struct {
+	Name       string
+	Polygon    []GeoPoint
+	Postcodes  []string
+	Fee        float64       // added to the total of the order
+	MinOrder   float64       // minimum subtotal of the order
+	TravelTime time.Duration // added to the ready time to estimate delivery
+}
+
+// Contains reports if the location is inside the zone, by its postcode or its point.
+func (z DeliveryZone) Contains(loc GeoLocation) bool {
+	if loc.Postcode != "" && slices.Contains(z.Postcodes, loc.Postcode) {
+		return true
+	}
+	return loc.Point != nil && len(z.Polygon) >= 3 && inPolygon(*loc.Point, z.Polygon)
+}
+
+// inPolygon is a ray casting test, zones are small enough to treat degrees as plane coordinates.
+func inPolygon(p GeoPoint, polygon []GeoPoint) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,8 @@
+package types
+
+// Results of an order in a batch import.
+const (
+	BatchCreated = "created"
+	BatchInvalid = "invalid"
+	BatchFailed  = "failed"
+)
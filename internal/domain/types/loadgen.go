@@ -0,0 +1,16 @@
+package types
+
+import "slices"
+
+// Traffic shapes of the load generator
+const (
+	LoadProfileSteady = "steady" // target rate all the time
+	LoadProfileRamp   = "ramp"   // from zero up to the target rate
+	LoadProfileBurst  = "burst"  // target rate in bursts, a tenth of it between them
+)
+
+var AllLoadProfiles = []string{LoadProfileSteady, LoadProfileRamp, LoadProfileBurst}
+
+func IsValidLoadProfile(s string) bool {
+	return slices.Contains(AllLoadProfiles, s)
+}
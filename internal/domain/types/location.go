@@ -0,0 +1,15 @@
+package types
+
+import "regexp"
+
+// DefaultLocation is the location used when none is given. Data created before
+// multi-location support belongs to it.
+const DefaultLocation = "main"
+
+// Location id is used in routing keys and queue names, so dots and spaces are not allowed.
+var locationRX = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// IsValidLocation checks if given string can be used as location id
+func IsValidLocation(s string) bool {
+	return locationRX.MatchString(s)
+}
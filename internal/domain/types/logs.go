@@ -2,11 +2,31 @@ package types
 
 const (
 	// Info level actions
-	ActionServiceStarted    = "service_started"
-	ActionDBConnected       = "db_connected"
-	ActionRabbitMQConnected = "rabbitmq_connected"
-	ActionWorkerRegistered  = "worker_registered"
-	ActionGracefulShutdown  = "graceful_shutdown"
+	ActionServiceStarted     = "service_started"
+	ActionDBConnected        = "db_connected"
+	ActionRabbitMQConnected  = "rabbitmq_connected"
+	ActionWorkerRegistered   = "worker_registered"
+	ActionGracefulShutdown   = "graceful_shutdown"
+	ActionConfigReloaded     = "config_reloaded"
+	ActionOrderPriorityAged  = "order_priority_aged"
+	ActionOrderReleased      = "order_released"
+	ActionOrderCancelled     = "order_cancelled"
+	ActionOrderModified      = "order_modified"
+	ActionOrderBatchImported = "order_batch_imported"
+	ActionLowStock           = "low_stock"
+	ActionStockRestocked     = "stock_restocked"
+	ActionWorkerCommand      = "worker_command"
+	ActionWorkerShift        = "worker_shift"
+	ActionCustomerCreated    = "customer_created"
+	ActionCustomersLinked    = "customers_linked"
+	ActionPromotionCreated   = "promotion_created"
+	ActionPaymentAuthorized  = "payment_authorized"
+	ActionPaymentFailed      = "payment_failed"
+	ActionPaymentRefunded    = "payment_refunded"
+	ActionTableChanged       = "table_changed"
+	ActionLoadGenStarted     = "loadgen_started"
+	ActionLoadGenFinished    = "loadgen_finished"
+	ActionServiceRestarted   = "service_restarted"
 
 	// Debug level actions
 	ActionWorkerStop              = "worker_stop"
@@ -20,6 +40,9 @@ const (
 	ActionRabbitConnectionClosed  = "rabbitmq_connection_closed"
 	ActionRabbitConnectionClosing = "rabbitmq_connection_closing"
 	ActionRabbitReconnect         = "rabbitmq_reconnect"
+	ActionConfigReloadRejected    = "config_reload_rejected"
+	ActionOrderSkipped            = "order_skipped"
+	ActionLoadGenRequestFailed    = "loadgen_request_failed"
 
 	// Error level actions
 	ActionValidationFailed         = "validation_failed"
@@ -31,4 +54,6 @@ const (
 	ActionDBConnectionFailed       = "db_connection_failed"
 	ActionRabbitConnectionFailed   = "rabbitmq_connection_failed"
 	ActionOrderProccessingFailed   = "order_proccess_failed"
+	ActionConfigReloadFailed       = "config_reload_failed"
+	ActionServiceFailed            = "service_failed"
 )
@@ -7,4 +7,7 @@ const (
 	ModeKitchenWorker          ServiceMode = "kitchen-worker"
 	ModeTracking               ServiceMode = "tracking-service"
 	ModeNotificationSubscriber ServiceMode = "notification-subscriber"
+	ModePayment                ServiceMode = "payment-service"
+	ModeLoadGen                ServiceMode = "loadgen"
+	ModeAll                    ServiceMode = "all" // order, tracking, kitchen workers and notification subscriber in one process
 )
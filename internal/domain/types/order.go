@@ -6,11 +6,13 @@ import (
 )
 
 const (
-	StatusOrderReceived  = "received"
-	StatusOrderCooking   = "cooking"
-	StatusOrderReady     = "ready"
-	StatusOrderCompleted = "completed"
-	StatusOrderCancelled = "cancelled"
+	StatusOrderScheduled      = "scheduled"       // waits for release to the kitchen at its scheduled time
+	StatusOrderPendingPayment = "pending_payment" // waits for payment authorization by payment-service
+	StatusOrderReceived       = "received"
+	StatusOrderCooking        = "cooking"
+	StatusOrderReady          = "ready"
+	StatusOrderCompleted      = "completed"
+	StatusOrderCancelled      = "cancelled"
 )
 
 // Must be one of: `'dine_in'`, `'takeout'`, or `'delivery'`.
@@ -26,6 +28,9 @@ const (
 	DefaultCookingTime  time.Duration = time.Second * 5
 )
 
+// MaxOrderPriority is the highest order priority, kitchen queues are declared with it as `x-max-priority`.
+const MaxOrderPriority = 10
+
 // All order types
 var AllOrderTypes = []string{
 	OrderTypeDineIn,
@@ -0,0 +1,23 @@
+package types
+
+// Payment statuses
+const (
+	PaymentPending       = "pending"        // waits for authorization
+	PaymentAuthorized    = "authorized"     // order is sent to the kitchen
+	PaymentDeclined      = "declined"       // provider declined, order is cancelled
+	PaymentFailed        = "failed"         // no answer after all attempts, order is cancelled
+	PaymentCancelled     = "cancelled"      // order was cancelled before authorization
+	PaymentRefundPending = "refund_pending" // order was cancelled after authorization
+	PaymentRefunded      = "refunded"
+	PaymentRefundFailed  = "refund_failed" // no refund after all attempts, refunded by hand
+)
+
+// Outcomes of the fake payment provider
+const (
+	FakePaymentApprove = "approve"
+	FakePaymentDecline = "decline"
+	FakePaymentTimeout = "timeout"
+)
+
+// PaymentProviderFake is the local payment provider, answering by a script of outcomes.
+const PaymentProviderFake = "fake"
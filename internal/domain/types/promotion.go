@@ -0,0 +1,24 @@
+package types
+
+import "slices"
+
+// Promotion kinds
+const (
+	PromotionPercent  = "percent"     // percent of the subtotal
+	PromotionFixed    = "fixed"       // fixed amount off the subtotal
+	PromotionBuyXGetY = "buy_x_get_y" // free units of a menu item
+
+	// DiscountLoyalty is the kind of discount paid with loyalty points.
+	DiscountLoyalty = "loyalty"
+)
+
+var AllPromotionKinds = []string{
+	PromotionPercent,
+	PromotionFixed,
+	PromotionBuyXGetY,
+}
+
+// IsValidPromotionKind checks if given string is promotion kind
+func IsValidPromotionKind(s string) bool {
+	return slices.Contains(AllPromotionKinds, s)
+}
@@ -0,0 +1,13 @@
+package types
+
+import "regexp"
+
+// DefaultStation cooks items which are not assigned to any station by station definitions.
+const DefaultStation = "kitchen"
+
+var stationNameRX = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// IsValidStationName checks station name, it is a part of queue names and routing keys.
+func IsValidStationName(s string) bool {
+	return stationNameRX.MatchString(s)
+}
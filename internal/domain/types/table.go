@@ -0,0 +1,8 @@
+package types
+
+// Table statuses
+const (
+	TableFree          = "free"
+	TableOccupied      = "occupied"       // has an open session
+	TableNeedsCleaning = "needs_cleaning" // session is closed, table is free again once cleaned
+)
@@ -1,6 +1,43 @@
 package types
 
 const (
-	WorkerOnline  = "online"
-	WorkerOffline = "offline"
+	WorkerOnline   = "online"
+	WorkerOffline  = "offline"
+	WorkerPaused   = "paused"   // heartbeating, but not consuming orders
+	WorkerDraining = "draining" // finishing active orders before going offline
+	WorkerOffDuty  = "off_duty" // heartbeating, but not consuming orders outside its shifts
 )
+
+// Admin commands picked up by workers on heartbeat.
+const (
+	WorkerCommandPause  = "pause"
+	WorkerCommandResume = "resume"
+	WorkerCommandDrain  = "drain"
+	WorkerCommandEvict  = "evict"
+)
+
+// IsValidWorkerCommand reports whether the command is known.
+func IsValidWorkerCommand(command string) bool {
+	switch command {
+	case WorkerCommandPause, WorkerCommandResume, WorkerCommandDrain, WorkerCommandEvict:
+		return true
+	}
+	return false
+}
+
+// Duty of a worker by its shifts.
+const (
+	DutyOnDuty    = "on_duty"
+	DutyScheduled = "scheduled"
+	DutyOffDuty   = "off_duty"
+)
+
+// Reasons a worker session ended.
+const (
+	SessionEndGraceful         = "graceful"
+	SessionEndEvicted          = "evicted"
+	SessionEndHeartbeatTimeout = "heartbeat_timeout"
+)
+
+// Version of the build reported by workers, set with -ldflags "-X .../types.Version=...".
+var Version = "dev"
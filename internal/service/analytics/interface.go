@@ -0,0 +1,17 @@
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Repository contracts
+
+type AnalyticsRepo interface {
+	Throughput(ctx context.Context, window models.AnalyticsWindow, bucket time.Duration) ([]models.ThroughputPoint, error)
+	Durations(ctx context.Context, window models.AnalyticsWindow) (models.KitchenDurations, error)
+	Workers(ctx context.Context, window models.AnalyticsWindow) ([]models.WorkerPerformance, error)
+	SLA(ctx context.Context, window models.AnalyticsWindow, sla time.Duration) (models.SLAReport, error)
+}
@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// Service computes kitchen analytics from the order status log.
+type Service struct {
+	repo AnalyticsRepo
+	sla  atomic.Int64 // time.Duration
+
+	log logger.Logger
+}
+
+func NewService(repo AnalyticsRepo, sla time.Duration, log logger.Logger) *Service {
+	s := &Service{
+		repo: repo,
+		log:  log,
+	}
+	s.sla.Store(int64(sla))
+
+	return s
+}
+
+// SetSLA changes default SLA of the SLA report.
+func (s *Service) SetSLA(sla time.Duration) {
+	s.sla.Store(int64(sla))
+}
+
+// Throughput counts ready orders by type in every interval of the window.
+func (s *Service) Throughput(ctx context.Context, window models.AnalyticsWindow, bucket time.Duration) ([]models.ThroughputPoint, error) {
+	const op = "Service.Throughput"
+
+	points, err := s.repo.Throughput(ctx, window, bucket)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get throughput", err)
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if points == nil {
+		points = []models.ThroughputPoint{}
+	}
+
+	return points, nil
+}
+
+// Durations returns percentiles of queue wait and cooking time.
+func (s *Service) Durations(ctx context.Context, window models.AnalyticsWindow) (models.KitchenDurations, error) {
+	const op = "Service.Durations"
+
+	durations, err := s.repo.Durations(ctx, window)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get kitchen durations", err)
+		return models.KitchenDurations{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return durations, nil
+}
+
+// Workers returns performance of every worker of the location.
+func (s *Service) Workers(ctx context.Context, window models.AnalyticsWindow) ([]models.WorkerPerformance, error) {
+	const op = "Service.Workers"
+
+	workers, err := s.repo.Workers(ctx, window)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get worker performance", err)
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if workers == nil {
+		workers = []models.WorkerPerformance{}
+	}
+
+	return workers, nil
+}
+
+// SLA returns the share of orders not ready within sla. If sla is 0, the configured SLA is used.
+func (s *Service) SLA(ctx context.Context, window models.AnalyticsWindow, sla time.Duration) (models.SLAReport, error) {
+	const op = "Service.SLA"
+
+	if sla <= 0 {
+		sla = time.Duration(s.sla.Load())
+	}
+
+	report, err := s.repo.SLA(ctx, window, sla)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get SLA report", err)
+		return models.SLAReport{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if report.Orders > 0 {
+		report.BreachedShare = float64(report.Breached) / float64(report.Orders)
+	}
+
+	return report, nil
+}
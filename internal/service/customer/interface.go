@@ -0,0 +1,16 @@
+package customer
+
+import (
+	"context"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Repository contracts
+
+type CustomerRepo interface {
+	Create(ctx context.Context, c models.Customer) (models.Customer, error)
+	Get(ctx context.Context, id int) (models.Customer, error)
+	GetByPhone(ctx context.Context, phone string) (models.Customer, error)
+	BackfillOrders(ctx context.Context) (int, error)
+}
@@ -0,0 +1,81 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// Service manages customer accounts. Orders of a customer are listed by tracking-service.
+type Service struct {
+	repo CustomerRepo
+
+	log logger.Logger
+}
+
+func NewService(repo CustomerRepo, log logger.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Create creates a customer. Phone numbers are unique.
+func (s *Service) Create(ctx context.Context, c models.Customer) (models.Customer, error) {
+	const op = "Service.Create"
+
+	customer, err := s.repo.Create(ctx, c)
+	if err != nil {
+		if errors.Is(err, models.ErrCustomerExists) {
+			return models.Customer{}, err
+		}
+
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to create customer", err)
+		return models.Customer{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	s.log.Info(ctx, types.ActionCustomerCreated, "customer created", "customer_id", customer.ID)
+	return customer, nil
+}
+
+// Get returns the customer by id.
+func (s *Service) Get(ctx context.Context, id int) (models.Customer, error) {
+	return s.lookup(ctx, "Service.Get", func() (models.Customer, error) { return s.repo.Get(ctx, id) })
+}
+
+// FindByPhone returns the customer by normalized phone number, for the order counter.
+func (s *Service) FindByPhone(ctx context.Context, phone string) (models.Customer, error) {
+	return s.lookup(ctx, "Service.FindByPhone", func() (models.Customer, error) { return s.repo.GetByPhone(ctx, phone) })
+}
+
+// BackfillOrders links anonymous orders to customers with the same name.
+func (s *Service) BackfillOrders(ctx context.Context) (int, error) {
+	const op = "Service.BackfillOrders"
+
+	linked, err := s.repo.BackfillOrders(ctx)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to backfill orders customers", err)
+		return 0, fmt.Errorf("%s: %v", op, err)
+	}
+
+	s.log.Info(ctx, types.ActionCustomersLinked, "orders linked to customers", "orders", linked)
+	return linked, nil
+}
+
+func (s *Service) lookup(ctx context.Context, op string, get func() (models.Customer, error)) (models.Customer, error) {
+	customer, err := get()
+	if err != nil {
+		if errors.Is(err, models.ErrCustomerNotFound) {
+			return models.Customer{}, err
+		}
+
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get customer", err)
+		return models.Customer{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return customer, nil
+}
@@ -0,0 +1,86 @@
+package eta
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// Cook times needed for full confidence in the sample size.
+const fullConfidenceSamples = 20
+
+// Estimator predicts when an order waiting in the queue will be ready.
+type Estimator struct {
+	repo         ETARepo
+	heartbeatInt atomic.Int64 // seconds, workers not seen for two intervals are offline
+}
+
+func NewEstimator(repo ETARepo, heartbeatInt int) *Estimator {
+	e := &Estimator{
+		repo: repo,
+	}
+	e.heartbeatInt.Store(int64(heartbeatInt))
+
+	return e
+}
+
+// SetHeartbeatInterval changes interval used to detect offline workers.
+func (e *Estimator) SetHeartbeatInterval(heartbeatInt int) {
+	e.heartbeatInt.Store(int64(heartbeatInt))
+}
+
+// Estimate predicts ready time of the received order of the given type queued at queuedAt.
+func (e *Estimator) Estimate(ctx context.Context, locationID, orderType string, queuedAt time.Time) (models.ETA, error) {
+	const op = "Estimator.Estimate"
+
+	now := time.Now()
+	onlineSince := now.Add(-2 * time.Duration(e.heartbeatInt.Load()) * time.Second)
+
+	in, err := e.repo.Inputs(ctx, locationID, orderType, queuedAt, onlineSince)
+	if err != nil {
+		return models.ETA{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return Predict(in, orderType, now), nil
+}
+
+// Predict returns ETA of an order from the kitchen state. Online workers cook the queue in rounds,
+// one order per worker per round, and every round takes the median recent cook time.
+//
+// Confidence grows with the number of recent cook times and falls with their spread (p50/p90).
+// It is low when simulated cook time is used because there is no history, or when nobody can cook the order.
+func Predict(in models.ETAInputs, orderType string, now time.Time) models.ETA {
+	position := max(in.QueueAhead, 1)
+	workers := max(in.OnlineWorkers, 1)
+	rounds := (position + workers - 1) / workers
+
+	cookTime := time.Duration(in.CookTime.P50 * float64(time.Second))
+	confidence := 0.2
+	if in.CookTime.Count > 0 && cookTime > 0 {
+		sample := math.Min(1, float64(in.CookTime.Count)/fullConfidenceSamples)
+		spread := 1.0
+		if in.CookTime.P90 > 0 {
+			spread = in.CookTime.P50 / in.CookTime.P90
+		}
+		confidence = sample * spread
+	} else {
+		cookTime = types.GetSimulateCookingDuration(orderType)
+	}
+
+	if in.OnlineWorkers == 0 {
+		confidence /= 4
+	}
+	confidence = math.Round(math.Max(0.05, math.Min(0.95, confidence))*100) / 100
+
+	return models.ETA{
+		ReadyAt:       now.Add(time.Duration(rounds) * cookTime),
+		Confidence:    confidence,
+		QueuePosition: position,
+		OnlineWorkers: in.OnlineWorkers,
+	}
+}
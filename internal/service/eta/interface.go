@@ -0,0 +1,14 @@
+package eta
+
+import (
+	"context"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Repository contracts
+
+type ETARepo interface {
+	Inputs(ctx context.Context, locationID, orderType string, queuedAt, onlineSince time.Time) (models.ETAInputs, error)
+}
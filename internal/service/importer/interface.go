@@ -0,0 +1,12 @@
+package importer
+
+import (
+	"context"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// OrderClient sends batches of JSON encoded orders to a running order-service.
+type OrderClient interface {
+	CreateBatch(ctx context.Context, locationID string, orders [][]byte) (models.BatchSummary, []models.BatchRejection, error)
+}
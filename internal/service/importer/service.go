@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// maxLineBytes limits length of one order line of the file.
+const maxLineBytes = 1 << 20
+
+type Service struct {
+	client      OrderClient
+	location    string // empty for the default location of the order-service
+	batchSize   int
+	concurrency int
+
+	log logger.Logger
+}
+
+func NewService(client OrderClient, location string, batchSize, concurrency int, log logger.Logger) *Service {
+	return &Service{
+		client:      client,
+		location:    location,
+		batchSize:   max(batchSize, 1),
+		concurrency: max(concurrency, 1),
+		log:         log,
+	}
+}
+
+// Import imports orders from JSONL, one create order request per line. Lines are sent in batches, at most
+// concurrency batches at once. A batch that could not be sent is counted as failed.
+func (s *Service) Import(ctx context.Context, r io.Reader) (models.BatchSummary, error) {
+	var (
+		summary models.BatchSummary
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		slots   = make(chan struct{}, s.concurrency)
+	)
+
+	send := func(numbers []int, lines [][]byte) {
+		defer wg.Done()
+		defer func() { <-slots }()
+
+		batch, rejected, err := s.client.CreateBatch(ctx, s.location, lines)
+		if err != nil {
+			s.log.Error(ctx, types.ActionOrderBatchImported, "failed to import batch", err, "first-line", numbers[0], "orders", len(lines))
+			batch = models.BatchSummary{Total: len(lines), Failed: len(lines)}
+		}
+		for _, rej := range rejected {
+			if rej.Index >= 0 && rej.Index < len(numbers) {
+				s.log.Warn(ctx, types.ActionOrderBatchImported, "order not imported", "line", numbers[rej.Index], "reason", rej.Reason)
+			}
+		}
+
+		mu.Lock()
+		summary.Add(batch)
+		mu.Unlock()
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	var (
+		lines   [][]byte
+		numbers []int // line numbers of the lines, rejected orders are reported by them
+		lineNo  int
+	)
+	flush := func() {
+		if len(lines) == 0 {
+			return
+		}
+		slots <- struct{}{}
+		wg.Add(1)
+		go send(numbers, lines)
+		lines, numbers = nil, nil
+	}
+
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		lines = append(lines, append([]byte(nil), line...))
+		numbers = append(numbers, lineNo)
+
+		if len(lines) == s.batchSize {
+			flush()
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	flush()
+	wg.Wait()
+
+	if err := scanner.Err(); err != nil {
+		return summary, fmt.Errorf("failed to read orders at line %d: %w", lineNo+1, err)
+	}
+
+	return summary, ctx.Err()
+}
@@ -0,0 +1,16 @@
+package inventory
+
+import (
+	"context"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Repository contracts
+
+type InventoryRepo interface {
+	List(ctx context.Context, locationID string) ([]models.StockItem, error)
+	Restock(ctx context.Context, req models.Restock) (models.StockItem, error)
+	Recipes(ctx context.Context, locationID string) ([]models.Recipe, error)
+	SetRecipe(ctx context.Context, recipe models.Recipe) error
+}
@@ -0,0 +1,84 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// Service manages ingredient stock and recipes. Stock is reserved and consumed by orders in the order repository.
+type Service struct {
+	repo InventoryRepo
+
+	log logger.Logger
+}
+
+func NewService(repo InventoryRepo, log logger.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// ListStock returns stock of all ingredients of the location.
+func (s *Service) ListStock(ctx context.Context, locationID string) ([]models.StockItem, error) {
+	const op = "Service.ListStock"
+
+	stock, err := s.repo.List(ctx, locationID)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to list stock", err)
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if stock == nil {
+		stock = []models.StockItem{}
+	}
+
+	return stock, nil
+}
+
+// Restock adds to the stock of the ingredient.
+func (s *Service) Restock(ctx context.Context, req models.Restock) (models.StockItem, error) {
+	const op = "Service.Restock"
+
+	stock, err := s.repo.Restock(ctx, req)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to restock", err, "ingredient", req.Ingredient)
+		return models.StockItem{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	s.log.Info(ctx, types.ActionStockRestocked, "ingredient restocked", "ingredient", req.Ingredient, "location_id", req.LocationID, "available", stock.Available)
+	return stock, nil
+}
+
+// ListRecipes returns recipes with availability of the dishes in the location.
+func (s *Service) ListRecipes(ctx context.Context, locationID string) ([]models.Recipe, error) {
+	const op = "Service.ListRecipes"
+
+	recipes, err := s.repo.Recipes(ctx, locationID)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to list recipes", err)
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if recipes == nil {
+		recipes = []models.Recipe{}
+	}
+
+	return recipes, nil
+}
+
+// SetRecipe replaces ingredients of the dish.
+func (s *Service) SetRecipe(ctx context.Context, recipe models.Recipe) error {
+	const op = "Service.SetRecipe"
+
+	if err := s.repo.SetRecipe(ctx, recipe); err != nil {
+		s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to set recipe", err, "item", recipe.ItemName)
+		return fmt.Errorf("%s: %v", op, err)
+	}
+
+	return nil
+}
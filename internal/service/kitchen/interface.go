@@ -2,7 +2,6 @@ package kitchen
 
 import (
 	"context"
-	"time"
 
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
 )
@@ -10,26 +9,57 @@ import (
 // Repository contract
 type WorkerRepository interface {
 	// MarkOnline marks worker by inserting (or updating) a record in the
-	// workers table with its unique name and type, marking it online.
-	MarkOnline(ctx context.Context, name, orderTypes string, heartbeat time.Duration) error
+	// workers table with its unique name and type, marking it online and opening a new session.
+	// Returns id of the session, which identifies this instance of the worker.
+	MarkOnline(ctx context.Context, locationID, name string, hb models.Heartbeat) (int, error)
 
-	// MarkOffline marks worker offline.
-	MarkOffline(ctx context.Context, name string) error
+	// MarkOffline marks worker offline and ends the session. A worker registered by a newer instance is left alone.
+	MarkOffline(ctx context.Context, locationID, name string, sessionID int) error
 
-	// UpdateLastSeen updates last seen timestamp
-	UpdateLastSeen(ctx context.Context, name string) error
+	// Heartbeat updates last seen timestamp with the reported state and returns admin command waiting for the worker, if any.
+	// Returns models.ErrWorkerReplaced if a newer instance registered under the name, e.g. after an evict.
+	Heartbeat(ctx context.Context, locationID, name string, sessionID int, hb models.Heartbeat) (string, error)
+
+	// AckCommand clears the picked up command and sets the resulting worker status.
+	AckCommand(ctx context.Context, locationID, name, command, status string) error
+
+	// SetStatus sets status of the worker.
+	SetStatus(ctx context.Context, locationID, name, status string) error
 
 	// Incerements number of proccessed orders for worker.
-	IncrOrdersProcessed(ctx context.Context, name string) error
+	IncrOrdersProcessed(ctx context.Context, locationID, name string) error
+}
+
+type ShiftRepository interface {
+	// ListByWorker returns shifts of the worker. Workers without shifts are always on duty.
+	ListByWorker(ctx context.Context, locationID, workerName string) ([]models.Shift, error)
 }
 
 type OrderRepository interface {
-	// SetStatus sets new status and returns old status
-	SetStatus(ctx context.Context, orderNumber, workerName, status string, notes string) (string, error)
+	// SetStatus sets new status of the location order and returns old status
+	SetStatus(ctx context.Context, locationID, orderNumber, workerName, status string, notes string) (string, error)
+
+	// StartCooking sets 'cooking' status if the order still has the priority and version of the message.
+	// Returns models.ErrOrderSuperseded if the order was republished with a higher priority or a new version,
+	// or is not in the kitchen (a release or payment which published it did not commit).
+	StartCooking(ctx context.Context, locationID, orderNumber, workerName string, priority, version int) (string, error)
+
+	// StartStation sets 'cooking' status of the station items, and of the order if it is the first station.
+	// Returns old order status, models.ErrOrderSuperseded like StartCooking, and models.ErrStationDone
+	// if the station items are already ready.
+	StartStation(ctx context.Context, locationID, orderNumber, station, workerName string, priority, version int) (string, error)
+
+	// FinishStation sets 'ready' status of the station items. When all items of the order are ready,
+	// the order becomes 'ready' too and its old status is returned, otherwise the status is empty.
+	FinishStation(ctx context.Context, locationID, orderNumber, station, workerName string) (string, error)
 }
 
 type Consumer interface {
 	Consume(ctx context.Context, orderType string, handler func(ctx context.Context, req *models.CreateOrder) error) error
+	ConsumeStation(ctx context.Context, station string, handler func(ctx context.Context, req *models.CreateOrder) error) error
+
+	// SetPrefetch changes number of unacknowledged orders the worker can hold.
+	SetPrefetch(prefetch int) error
 }
 
 type Producer interface {
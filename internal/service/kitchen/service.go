@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -15,31 +17,50 @@ import (
 )
 
 var (
-	ErrWorkerStopped = errors.New("worker stopped")
-	ErrNilOrder      = errors.New("nil order")
+	ErrWorkerStopped  = errors.New("worker stopped")
+	ErrWorkerDrained  = errors.New("worker drained by admin")
+	ErrWorkerEvicted  = errors.New("worker evicted by admin")
+	ErrNilOrder       = errors.New("nil order")
+	ErrWrongLocation  = errors.New("order belongs to another location")
+	ErrStaleOrder     = errors.New("order was republished")
+	ErrWorkerStopping = errors.New("worker is stopping, cannot process new orders")
 )
 
 type (
 	KitchenWorker struct {
 		workerRepo WorkerRepository
 		orderRepo  OrderRepository
+		shiftRepo  ShiftRepository
 		consumer   Consumer
 		producer   Producer
 
 		isWorking bool
 		worker    *worker
+		sessionID int // session of this instance of the worker
 
 		mu           sync.Mutex
+		ordersMu     sync.Mutex      // guards ctx and active, Stop holds mu while waiting for active orders
+		ctx          context.Context // context of the working worker, cancelled on stop
 		cancel       func()
-		activeOrders sync.WaitGroup // activeOrders for monitor processing orders
-		stopping     chan struct{}  // stopping channel to stop signal for proccessing orders
+		state        string        // online, paused, off_duty or draining
+		exitErr      error         // reason the worker stops, set by admin commands
+		stopConsume  func()        // stops consuming orders
+		resumeCh     chan struct{} // wakes up the paused worker
+		active       []string      // numbers of orders being cooked, in order they were taken
+		host         string
+		activeOrders sync.WaitGroup     // activeOrders for monitor processing orders
+		stopping     chan struct{}      // stopping channel to stop signal for proccessing orders
+		heartbeatCh  chan time.Duration // heartbeatCh notifies heartbeat loop about new interval
+		cookingTime  func(orderType string) time.Duration
 
 		log logger.Logger
 	}
 
 	worker struct {
 		name       string
+		location   string   // location the worker is registered for
 		orderTypes []string // Comma-separated list of order types the worker can handle (e.g., `dine_in,takeout`). If omitted, handles all.
+		stations   []string // kitchen stations the worker cooks items for
 		heartbeat  time.Duration
 	}
 )
@@ -48,43 +69,63 @@ type (
 func NewWorker(
 	workerRepo WorkerRepository,
 	orderRepo OrderRepository,
+	shiftRepo ShiftRepository,
 	consumer Consumer,
 	producer Producer,
 	workerName string,
+	location string,
 	orderTypes []string,
+	stations []string,
 	heartbeat time.Duration,
 	log logger.Logger,
 ) *KitchenWorker {
+	host, _ := os.Hostname()
+
 	return &KitchenWorker{
 		workerRepo: workerRepo,
 		orderRepo:  orderRepo,
+		shiftRepo:  shiftRepo,
 		consumer:   consumer,
 		producer:   producer,
 		mu:         sync.Mutex{},
 
 		worker: &worker{
 			name:       workerName,
+			location:   location,
 			orderTypes: orderTypes,
+			stations:   stations,
 			heartbeat:  heartbeat,
 		},
 
 		activeOrders: sync.WaitGroup{},
 		stopping:     make(chan struct{}),
+		heartbeatCh:  make(chan time.Duration, 1),
+		resumeCh:     make(chan struct{}, 1),
+		stopConsume:  func() {},
+		cookingTime:  types.GetSimulateCookingDuration,
+		host:         host,
 
 		log: log,
 	}
 }
 
-// Work starts consuming orders and proccesses them
+// Work starts consuming orders and proccesses them. Paused or off duty worker keeps heartbeating
+// and starts consuming again on resume or at the start of its shift.
 func (s *KitchenWorker) Work(ctx context.Context, errCh chan<- error) {
 	defer func() {
-		// stop the worker from consuming and updating database.
-		s.Stop(ctx)
-		errCh <- ErrWorkerStopped
+		// stop the worker from consuming and updating database. The context is already cancelled
+		// on shutdown, the worker is still marked offline.
+		s.Stop(context.WithoutCancel(ctx))
+		errCh <- s.exitReason()
 	}()
 
 	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
 	s.cancel = cancel
+	s.mu.Unlock()
+	s.ordersMu.Lock()
+	s.ctx = ctx
+	s.ordersMu.Unlock()
 
 	// marking kitchen-worker as 'online'
 	if err := s.markOnline(ctx); err != nil {
@@ -93,6 +134,36 @@ func (s *KitchenWorker) Work(ctx context.Context, errCh chan<- error) {
 		return
 	}
 
+	s.mu.Lock()
+	interval := s.worker.heartbeat
+	s.mu.Unlock()
+	go func() {
+		s.heartbeatLoop(ctx, interval)
+	}()
+
+	s.checkShift(ctx)
+
+	for s.waitResume(ctx) {
+		consumeCtx, stopConsume := context.WithCancel(ctx)
+		s.mu.Lock()
+		s.stopConsume = stopConsume
+		s.mu.Unlock()
+
+		s.consume(consumeCtx, errCh)
+		stopConsume()
+
+		// consumers stopped by themselves, e.g. connection is closed
+		s.mu.Lock()
+		state := s.state
+		s.mu.Unlock()
+		if state == types.WorkerOnline {
+			return
+		}
+	}
+}
+
+// consume consumes queues of the worker order types and stations until the context is cancelled.
+func (s *KitchenWorker) consume(ctx context.Context, errCh chan<- error) {
 	wg := sync.WaitGroup{}
 	for _, ot := range s.worker.orderTypes {
 		wg.Add(1)
@@ -114,13 +185,62 @@ func (s *KitchenWorker) Work(ctx context.Context, errCh chan<- error) {
 		}(ot)
 	}
 
-	go func() {
-		s.heartbeatLoop(ctx, s.worker.heartbeat)
-	}()
+	for _, station := range s.worker.stations {
+		wg.Add(1)
+		go func(station string) {
+			defer func() {
+				wg.Done()
+				s.log.Info(ctx, "kitchen_worker_stop_consume", "stopped consuming station orders", "station", station)
+			}()
+
+			err := s.consumer.ConsumeStation(ctx, station, s.processOrderWrapper)
+			if err != nil {
+				select {
+				case errCh <- fmt.Errorf("failed to start consuming: %w", err):
+				default:
+					s.log.Error(ctx, "error_channel_full", "failed to send error to channel", err)
+				}
+			}
+		}(station)
+	}
 
 	wg.Wait()
 }
 
+// waitResume blocks while the worker is paused or off duty. Returns false if the worker must stop.
+func (s *KitchenWorker) waitResume(ctx context.Context) bool {
+	for {
+		s.mu.Lock()
+		state := s.state
+		s.mu.Unlock()
+
+		switch state {
+		case types.WorkerOnline:
+			return true
+		case types.WorkerPaused, types.WorkerOffDuty:
+		default:
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-s.resumeCh:
+		}
+	}
+}
+
+// exitReason returns error the worker stopped with.
+func (s *KitchenWorker) exitReason() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.exitErr != nil {
+		return s.exitErr
+	}
+	return ErrWorkerStopped
+}
+
 // Stop worker from work
 func (s *KitchenWorker) Stop(ctx context.Context) {
 	s.mu.Lock()
@@ -130,8 +250,12 @@ func (s *KitchenWorker) Stop(ctx context.Context) {
 		return
 	}
 
-	// stop proccessing new orders
-	close(s.stopping)
+	// stop proccessing new orders, the channel is already closed if the previous stop failed
+	select {
+	case <-s.stopping:
+	default:
+		close(s.stopping)
+	}
 	s.log.Debug(ctx, types.ActionWorkerStop, "waiting for active orders to finish", "worker-name", s.worker.name)
 	// waiting for active proccesses to finish
 	s.activeOrders.Wait()
@@ -142,7 +266,7 @@ func (s *KitchenWorker) Stop(ctx context.Context) {
 	}
 
 	// Mark worker offline
-	if err := s.workerRepo.MarkOffline(ctx, s.worker.name); err != nil {
+	if err := s.workerRepo.MarkOffline(ctx, s.worker.location, s.worker.name, s.sessionID); err != nil {
 		s.log.Error(ctx, types.ActionWorkerStop, "failed to mark worker offline", err, "worker-name", s.worker.name)
 		return
 	}
@@ -151,21 +275,78 @@ func (s *KitchenWorker) Stop(ctx context.Context) {
 	s.log.Info(ctx, "worker_stop", "stopping worker", "worker-name", s.worker.name)
 }
 
+// SetHeartbeat changes heartbeat interval of the working worker.
+func (s *KitchenWorker) SetHeartbeat(interval time.Duration) {
+	s.mu.Lock()
+	s.worker.heartbeat = interval
+	s.mu.Unlock()
+
+	// keep only the latest interval in the channel
+	select {
+	case <-s.heartbeatCh:
+	default:
+	}
+	s.heartbeatCh <- interval
+}
+
+// SetCookingTime replaces simulated cooking time of orders, e.g. to speed up tests.
+// Must be called before the worker starts.
+func (s *KitchenWorker) SetCookingTime(fn func(orderType string) time.Duration) {
+	s.cookingTime = fn
+}
+
+// SetCapacity changes number of orders the worker can take at once.
+func (s *KitchenWorker) SetCapacity(prefetch int) error {
+	return s.consumer.SetPrefetch(prefetch)
+}
+
 func (s *KitchenWorker) processOrderWrapper(ctx context.Context, req *models.CreateOrder) error {
-	// Check if order proccessing stoppped.
+	// Check if order proccessing stoppped. The order is requeued once consuming stops,
+	// so it is not redelivered to this worker while active orders are finished.
 	select {
 	case <-s.stopping:
 		s.log.Info(ctx, types.ActionWorkerStop, "rejecting new order due to worker stopping", "order-number", req.Number)
-		return errors.New("worker is stopping, cannot process new orders")
+		<-ctx.Done()
+		return ErrWorkerStopping
 	default:
 	}
 
 	s.activeOrders.Add(1)
 	defer s.activeOrders.Done()
 
+	var number string
+	if req != nil {
+		number = req.Number
+	}
+	workCtx, done := s.trackOrder(number)
+	defer done()
+
+	// Pausing or draining the worker stops consuming, but the taken order is cooked to the end.
+	// Only stopping the worker itself interrupts cooking.
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	defer context.AfterFunc(workCtx, cancel)()
+
 	return s.proccessOrder(ctx, req)
 }
 
+// trackOrder reports the order as active on heartbeat until done is called.
+// Returns context of the working worker.
+func (s *KitchenWorker) trackOrder(number string) (context.Context, func()) {
+	s.ordersMu.Lock()
+	defer s.ordersMu.Unlock()
+
+	s.active = append(s.active, number)
+	return s.ctx, func() {
+		s.ordersMu.Lock()
+		defer s.ordersMu.Unlock()
+
+		if i := slices.Index(s.active, number); i >= 0 {
+			s.active = slices.Delete(s.active, i, i+1)
+		}
+	}
+}
+
 // proccessOrder processes created order
 func (s *KitchenWorker) proccessOrder(ctx context.Context, req *models.CreateOrder) error {
 	if req == nil {
@@ -173,7 +354,18 @@ func (s *KitchenWorker) proccessOrder(ctx context.Context, req *models.CreateOrd
 		return ErrNilOrder
 	}
 
-	cookingTime := types.GetSimulateCookingDuration(req.Type) // Simulated time
+	// Queues are per location, but never cook an order of another restaurant.
+	if req.LocationID != s.worker.location {
+		s.log.Error(ctx, types.ActionValidationFailed, "order of another location", ErrWrongLocation, "worker-name", s.worker.name, "order-location", req.LocationID)
+		return ErrWrongLocation
+	}
+
+	// Station part of the order, the order is ready when all its stations are.
+	if req.Station != "" {
+		return s.proccessStation(ctx, req)
+	}
+
+	cookingTime := s.cookingTime(req.Type) // Simulated time
 
 	s.log.Debug(
 		ctx,
@@ -183,8 +375,13 @@ func (s *KitchenWorker) proccessOrder(ctx context.Context, req *models.CreateOrd
 		"order-number", req.Number,
 		"cooking-time", utils.PrettyDuration(cookingTime))
 
-	// Set status cooking
-	oldStatus, err := s.orderRepo.SetStatus(ctx, req.Number, s.worker.name, types.StatusOrderCooking, "")
+	// Set status cooking. The message may be an old copy of an order promoted by priority aging or modified,
+	// then the current copy is still in the queue and this one is skipped.
+	oldStatus, err := s.orderRepo.StartCooking(ctx, s.worker.location, req.Number, s.worker.name, req.Priority, req.Version)
+	if errors.Is(err, models.ErrOrderSuperseded) {
+		s.log.Debug(ctx, types.ActionOrderSkipped, "skipping stale copy of order", "worker-name", s.worker.name, "order-number", req.Number, "priority", req.Priority, "version", req.Version)
+		return ErrStaleOrder
+	}
 	if err != nil {
 		s.log.Error(ctx, types.ActionMessageProcessingFailed, "failed to set cooking status for order", err, "worker-name", s.worker.name)
 		return fmt.Errorf("failed to set cooking status for order : %w", err)
@@ -201,6 +398,7 @@ func (s *KitchenWorker) proccessOrder(ctx context.Context, req *models.CreateOrd
 
 	// Publish status update message
 	if err := s.producer.StatusUpdate(ctx, &models.StatusUpdate{
+		LocationID:  s.worker.location,
 		OrderNumber: req.Number,
 		OldStatus:   oldStatus,
 		NewStatus:   types.StatusOrderCooking,
@@ -219,10 +417,11 @@ func (s *KitchenWorker) proccessOrder(ctx context.Context, req *models.CreateOrd
 		// cooked
 	case <-ctx.Done():
 		s.log.Warn(ctx, types.ActionMessageProcessingFailed, "order processing interrupted but completing", "order-number", req.Number, "context-error", ctx.Err())
+		ctx = context.WithoutCancel(ctx) // the order is still completed on shutdown
 	}
 
 	// Set status ready
-	oldStatus, err = s.orderRepo.SetStatus(ctx, req.Number, s.worker.name, types.StatusOrderReady, "")
+	oldStatus, err = s.orderRepo.SetStatus(ctx, s.worker.location, req.Number, s.worker.name, types.StatusOrderReady, "")
 	if err != nil {
 		s.log.Error(ctx, types.ActionMessageProcessingFailed, "failed to set ready status for order", err, "worker-name", s.worker.name)
 		return fmt.Errorf("failed to set ready status for order: %w", err)
@@ -231,6 +430,7 @@ func (s *KitchenWorker) proccessOrder(ctx context.Context, req *models.CreateOrd
 	// Publish status update message
 	timestamp = time.Now()
 	if err := s.producer.StatusUpdate(ctx, &models.StatusUpdate{
+		LocationID:  s.worker.location,
 		OrderNumber: req.Number,
 		OldStatus:   oldStatus,
 		NewStatus:   types.StatusOrderReady,
@@ -244,7 +444,7 @@ func (s *KitchenWorker) proccessOrder(ctx context.Context, req *models.CreateOrd
 	}
 
 	// Increment number of proccessed orders by the worker.
-	if err := s.workerRepo.IncrOrdersProcessed(ctx, s.worker.name); err != nil {
+	if err := s.workerRepo.IncrOrdersProcessed(ctx, s.worker.location, s.worker.name); err != nil {
 		s.log.Error(ctx, types.ActionMessageProcessingFailed, "failed to increment number of ordered", err, "worker-name", s.worker.name)
 		s.log.Warn(ctx, types.ActionMessageProcessingFailed, "order has been proccessed, but could not increment number of proccessed order for worker in the database", "worker-name", s.worker.name)
 	}
@@ -253,7 +453,8 @@ func (s *KitchenWorker) proccessOrder(ctx context.Context, req *models.CreateOrd
 	return nil
 }
 
-// heartbeatLoop tries to update last seen field in database each heartbeat interval.
+// heartbeatLoop tries to update last seen field in database each heartbeat interval
+// and applies admin commands given to the worker.
 func (s *KitchenWorker) heartbeatLoop(ctx context.Context, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -262,14 +463,140 @@ func (s *KitchenWorker) heartbeatLoop(ctx context.Context, interval time.Duratio
 		case <-ctx.Done():
 			s.log.Info(ctx, "worker_hearbeat_stop", "stopped hearbeat loop")
 			return
+		case interval := <-s.heartbeatCh:
+			ticker.Reset(interval)
+			s.log.Info(ctx, types.ActionConfigReloaded, "heartbeat interval changed", "worker-name", s.worker.name, "heartbeat-interval", utils.PrettyDuration(interval))
 		case <-ticker.C:
-			if err := s.workerRepo.UpdateLastSeen(ctx, s.worker.name); err != nil {
+			s.mu.Lock()
+			hb := s.heartbeatState()
+			sessionID := s.sessionID
+			s.mu.Unlock()
+
+			command, err := s.workerRepo.Heartbeat(ctx, s.worker.location, s.worker.name, sessionID, hb)
+			if errors.Is(err, models.ErrWorkerReplaced) {
+				// Evicted, and a new instance registered before this one picked up the command.
+				s.log.Warn(ctx, types.ActionWorkerCommand, "worker is replaced by a new instance, stopping", "worker-name", s.worker.name)
+				s.mu.Lock()
+				s.evict()
+				s.mu.Unlock()
+				continue
+			}
+			if err != nil {
 				s.log.Error(ctx, types.ActionDBQueryFailed, "failed to update last seen on worker", err, "worker-name", s.worker.name)
 				continue
 			}
 			s.log.Debug(ctx, types.ActionHeartbeatSent, "heartbeat was sent", "worker-name", s.worker.name)
+
+			if command != "" {
+				s.applyCommand(ctx, command)
+			}
+			s.checkShift(ctx)
+		}
+	}
+}
+
+// applyCommand applies admin command and reports the resulting worker status.
+func (s *KitchenWorker) applyCommand(ctx context.Context, command string) {
+	s.mu.Lock()
+	switch command {
+	case types.WorkerCommandPause:
+		if s.state == types.WorkerOnline || s.state == types.WorkerOffDuty {
+			s.state = types.WorkerPaused
+			s.stopConsume()
+		}
+	case types.WorkerCommandResume:
+		if s.state == types.WorkerPaused {
+			s.state = types.WorkerOnline
+			s.wake()
 		}
+	case types.WorkerCommandDrain:
+		s.state = types.WorkerDraining
+		s.exitErr = ErrWorkerDrained
+		s.stopConsume()
+		s.wake()
+	case types.WorkerCommandEvict:
+		s.evict()
+	default:
+		s.log.Warn(ctx, types.ActionWorkerCommand, "unknown worker command", "worker-name", s.worker.name, "command", command)
+	}
+	state := s.state
+	s.mu.Unlock()
+
+	if err := s.workerRepo.AckCommand(ctx, s.worker.location, s.worker.name, command, state); err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to acknowledge worker command", err, "worker-name", s.worker.name, "command", command)
+		return
+	}
+
+	s.log.Info(ctx, types.ActionWorkerCommand, "worker command applied", "worker-name", s.worker.name, "command", command, "status", state)
+}
+
+// checkShift stops consuming orders at the end of the worker shift, active orders are still cooked,
+// and starts consuming again when the next shift starts.
+func (s *KitchenWorker) checkShift(ctx context.Context) {
+	shifts, err := s.shiftRepo.ListByWorker(ctx, s.worker.location, s.worker.name)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get worker shifts", err, "worker-name", s.worker.name)
+		return
 	}
+	onDuty := models.OnDuty(shifts, time.Now())
+
+	s.mu.Lock()
+	switch {
+	case s.state == types.WorkerOnline && !onDuty:
+		s.state = types.WorkerOffDuty
+		s.stopConsume()
+	case s.state == types.WorkerOffDuty && onDuty:
+		s.state = types.WorkerOnline
+		s.wake()
+	default:
+		s.mu.Unlock()
+		return
+	}
+	state := s.state
+	s.mu.Unlock()
+
+	if err := s.workerRepo.SetStatus(ctx, s.worker.location, s.worker.name, state); err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to set worker status", err, "worker-name", s.worker.name, "status", state)
+	}
+
+	s.log.Info(ctx, types.ActionWorkerShift, "worker duty changed by shift", "worker-name", s.worker.name, "status", state)
+}
+
+// evict stops the evicted worker, must be called with the mutex held.
+func (s *KitchenWorker) evict() {
+	s.state = types.WorkerOffline
+	s.exitErr = ErrWorkerEvicted
+	s.stopConsume()
+	s.wake()
+}
+
+// wake wakes up the paused worker, must be called with the mutex held.
+func (s *KitchenWorker) wake() {
+	select {
+	case s.resumeCh <- struct{}{}:
+	default:
+	}
+}
+
+// heartbeatState returns state the worker reports on heartbeat, must be called with the mutex held.
+func (s *KitchenWorker) heartbeatState() models.Heartbeat {
+	hb := models.Heartbeat{
+		Version:    types.Version,
+		Host:       s.host,
+		PID:        os.Getpid(),
+		OrderTypes: strings.Join(s.worker.orderTypes, ","), // turning all order types that worker can handle into string to store in database.
+		Stations:   strings.Join(s.worker.stations, ","),
+		Interval:   s.worker.heartbeat,
+	}
+
+	s.ordersMu.Lock()
+	hb.ActiveOrders = len(s.active)
+	if n := len(s.active); n > 0 {
+		hb.CurrentOrder = s.active[n-1]
+	}
+	s.ordersMu.Unlock()
+
+	return hb
 }
 
 // markOnline marks kitchen-worker as online
@@ -282,20 +609,26 @@ func (s *KitchenWorker) markOnline(ctx context.Context) error {
 		return models.ErrWorkerAlreadyOnline
 	}
 
-	// turning all order types that worker can handle into string to store in database.
-	workerOrderTypes := strings.Join(s.worker.orderTypes, ",")
+	hb := s.heartbeatState()
 
 	// Marking worker as online
-	if err := s.workerRepo.MarkOnline(ctx, s.worker.name, workerOrderTypes, s.worker.heartbeat); err != nil {
+	sessionID, err := s.workerRepo.MarkOnline(ctx, s.worker.location, s.worker.name, hb)
+	if err != nil {
 		return err
 	}
+	s.sessionID = sessionID
 	s.isWorking = true
+	s.state = types.WorkerOnline
 
 	s.log.Info(ctx,
 		types.ActionWorkerRegistered,
 		"worker was successfully registered",
 		"worker-name", s.worker.name,
-		"order-types", workerOrderTypes,
+		"location", s.worker.location,
+		"order-types", hb.OrderTypes,
+		"stations", hb.Stations,
+		"host", hb.Host,
+		"pid", hb.PID,
 		"heartbeat-interval", utils.PrettyDuration(s.worker.heartbeat),
 	)
 
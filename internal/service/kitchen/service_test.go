@@ -0,0 +1,600 @@
+package kitchen_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/memory"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/rabbit"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/kitchen"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+const (
+	location       = "test"
+	orderExchange  = "orders_topic"
+	notifyExchange = "notifications_fanout"
+)
+
+var (
+	_ kitchen.WorkerRepository = (*memory.WorkerRepo)(nil)
+	_ kitchen.OrderRepository  = (*memory.OrderRepo)(nil)
+	_ kitchen.ShiftRepository  = (*memory.ShiftRepo)(nil)
+	_ kitchen.Consumer         = (*memory.OrderConsumer)(nil)
+	_ kitchen.Producer         = (*memory.NotificationProducer)(nil)
+)
+
+// kitchenEnv is a broker and a database shared by workers of a test.
+type kitchenEnv struct {
+	broker   *memory.Broker
+	store    *memory.Store
+	orders   *memory.OrderRepo
+	workers  *memory.WorkerRepo
+	producer *memory.OrderProducer
+	updates  chan models.Notification
+	log      logger.Logger
+}
+
+func newKitchenEnv(t *testing.T) *kitchenEnv {
+	t.Helper()
+
+	broker := memory.NewBroker()
+	store := memory.NewStore()
+	log := logger.InitLogger("kitchen-test", logger.LevelError)
+
+	producer, err := memory.NewOrderProducer(broker, orderExchange, location)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscriber := memory.NewNotificationSubscriber(broker, notifyExchange, log)
+	updates, err := subscriber.StartListening(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { subscriber.Close() })
+
+	return &kitchenEnv{
+		broker:   broker,
+		store:    store,
+		orders:   memory.NewOrderRepo(store),
+		workers:  memory.NewWorkerRepo(store),
+		producer: producer,
+		updates:  updates,
+		log:      log,
+	}
+}
+
+// newWorker creates worker cooking every order for cookingTime.
+func (e *kitchenEnv) newWorker(t *testing.T, name string, orderTypes, stations []string, cookingTime time.Duration) *kitchen.KitchenWorker {
+	t.Helper()
+
+	consumer, err := memory.NewOrderConsumer(e.broker, orderExchange, location, 1, orderTypes, stations, e.log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier, err := memory.NewNotificationProducer(e.broker, notifyExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := kitchen.NewWorker(e.workers, e.orders, memory.NewShiftRepo(e.store), consumer, notifier, name, location, orderTypes, stations, time.Hour, e.log)
+	w.SetCookingTime(func(string) time.Duration { return cookingTime })
+
+	return w
+}
+
+// start runs the worker until the returned function is called, which returns error the worker stopped with.
+func start(w *kitchen.KitchenWorker) func() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 2)
+	go w.Work(ctx, errCh)
+
+	return func() error {
+		cancel()
+		select {
+		case err := <-errCh:
+			return err
+		case <-time.After(5 * time.Second):
+			return errors.New("worker is not stopped")
+		}
+	}
+}
+
+// placeOrder stores the order as the order service does and publishes it to the kitchen.
+func (e *kitchenEnv) placeOrder(t *testing.T, number, orderType string, priority int, items ...models.CreateOrderItem) *models.CreateOrder {
+	t.Helper()
+
+	if len(items) == 0 {
+		items = []models.CreateOrderItem{{Name: "Margherita", Quantity: 1, Price: 10}}
+	}
+
+	req := &models.CreateOrder{
+		LocationID:   location,
+		Number:       number,
+		CustomerName: "Alice",
+		Type:         orderType,
+		Items:        items,
+		Priority:     priority,
+		Status:       types.StatusOrderReceived,
+	}
+	req.CalucalteTotalAmount()
+
+	order, err := e.orders.Create(context.Background(), req, "order-service", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Version = order.Version
+
+	if err := e.producer.PublishCreateOrder(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	return req
+}
+
+func (e *kitchenEnv) waitStatus(t *testing.T, number, status string) models.Order {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		order, err := e.orders.GetByNumber(context.Background(), location, number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if order.Status == status {
+			return order
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("order %s is %s, want %s", number, order.Status, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// nextUpdate returns the next status update of the notifications exchange.
+func (e *kitchenEnv) nextUpdate(t *testing.T) models.StatusUpdate {
+	t.Helper()
+
+	select {
+	case n := <-e.updates:
+		if n.StatusUpdate == nil {
+			t.Fatalf("notification %+v is not a status update", n)
+		}
+		return *n.StatusUpdate
+	case <-time.After(5 * time.Second):
+		t.Fatal("no status update")
+		return models.StatusUpdate{}
+	}
+}
+
+func (e *kitchenEnv) expectUpdate(t *testing.T, number, oldStatus, newStatus string) {
+	t.Helper()
+
+	u := e.nextUpdate(t)
+	if u.OrderNumber != number || u.OldStatus != oldStatus || u.NewStatus != newStatus {
+		t.Fatalf("update %s %s -> %s, want %s %s -> %s", u.OrderNumber, u.OldStatus, u.NewStatus, number, oldStatus, newStatus)
+	}
+}
+
+func (e *kitchenEnv) expectNoUpdate(t *testing.T) {
+	t.Helper()
+
+	select {
+	case n := <-e.updates:
+		t.Fatalf("unexpected notification %+v", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWorkerCooksOrder(t *testing.T) {
+	e := newKitchenEnv(t)
+	w := e.newWorker(t, "chef_1", []string{types.OrderTypeTakeOut}, nil, 10*time.Millisecond)
+	stop := start(w)
+
+	e.placeOrder(t, "ORD_1", types.OrderTypeTakeOut, 1)
+
+	e.expectUpdate(t, "ORD_1", types.StatusOrderReceived, types.StatusOrderCooking)
+	e.expectUpdate(t, "ORD_1", types.StatusOrderCooking, types.StatusOrderReady)
+
+	order := e.waitStatus(t, "ORD_1", types.StatusOrderReady)
+	if order.ProcessedBy == nil || *order.ProcessedBy != "chef_1" || order.CompletedAt == nil {
+		t.Fatalf("ready order processed by %v, completed at %v", order.ProcessedBy, order.CompletedAt)
+	}
+	for _, item := range order.Items {
+		if item.Status != types.StatusOrderReady {
+			t.Fatalf("item %s is %s, want ready", item.Name, item.Status)
+		}
+	}
+
+	queue := memory.QueueByOrderType(location, types.OrderTypeTakeOut)
+	waitFor(t, "order to be acknowledged", func() bool { return e.broker.Unacked(queue) == 0 })
+
+	if err := stop(); !errors.Is(err, kitchen.ErrWorkerStopped) {
+		t.Fatalf("worker stopped with %v, want %v", err, kitchen.ErrWorkerStopped)
+	}
+
+	worker, err := e.workers.Get(context.Background(), location, "chef_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worker.Status != types.WorkerOffline || worker.ProcessedOrders != 1 {
+		t.Fatalf("worker is %s with %d orders, want offline with 1", worker.Status, worker.ProcessedOrders)
+	}
+
+	sessions, err := e.workers.Sessions(context.Background(), location, "chef_1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].EndReason == nil || *sessions[0].EndReason != "graceful" || sessions[0].ProcessedOrders != 1 {
+		t.Fatalf("sessions = %+v, want one graceful session with 1 order", sessions)
+	}
+
+	if n := len(e.broker.Ready(queue)) + len(e.broker.Ready(memory.DLQByQueue(queue))); n != 0 {
+		t.Fatalf("%d messages left in queues", n)
+	}
+}
+
+func TestWorkerSkipsStaleCopy(t *testing.T) {
+	e := newKitchenEnv(t)
+	ctx := context.Background()
+
+	// Order promoted while waiting: its first message is stale, the republished one is cooked.
+	e.placeOrder(t, "ORD_1", types.OrderTypeTakeOut, 1)
+	_, err := e.orders.Modify(ctx, location, "ORD_1", "order-service",
+		func(req *models.CreateOrder) (string, error) {
+			req.Priority = 5
+			return "priority raised", nil
+		},
+		func(req *models.CreateOrder) error {
+			return e.producer.PublishCreateOrder(ctx, req)
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	queue := memory.QueueByOrderType(location, types.OrderTypeTakeOut)
+	if ready := e.broker.Ready(queue); len(ready) != 2 || ready[0].Priority != 5 {
+		t.Fatalf("queue has %d messages, want 2 with the republished one first", len(ready))
+	}
+
+	w := e.newWorker(t, "chef_1", []string{types.OrderTypeTakeOut}, nil, 10*time.Millisecond)
+	stop := start(w)
+	defer stop()
+
+	e.expectUpdate(t, "ORD_1", types.StatusOrderReceived, types.StatusOrderCooking)
+	e.expectUpdate(t, "ORD_1", types.StatusOrderCooking, types.StatusOrderReady)
+
+	// Stale copy is acknowledged without cooking the order again.
+	waitFor(t, "stale copy to be acknowledged", func() bool {
+		return len(e.broker.Ready(queue)) == 0 && e.broker.Unacked(queue) == 0
+	})
+	e.expectNoUpdate(t)
+
+	if n := len(e.broker.Ready(memory.DLQByQueue(queue))); n != 0 {
+		t.Fatalf("stale copy is dead-lettered, dlq has %d messages", n)
+	}
+
+	order, err := e.orders.GetByNumber(ctx, location, "ORD_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Version != 2 || order.Status != types.StatusOrderReady {
+		t.Fatalf("order version %d is %s, want version 2 ready", order.Version, order.Status)
+	}
+}
+
+func TestWorkerSkipsOrderNotInKitchen(t *testing.T) {
+	e := newKitchenEnv(t)
+	ctx := context.Background()
+
+	// Published by a release which did not commit: the order is still scheduled, and then cancelled.
+	req := &models.CreateOrder{
+		LocationID:   location,
+		Number:       "ORD_1",
+		CustomerName: "Alice",
+		Type:         types.OrderTypeTakeOut,
+		Items:        []models.CreateOrderItem{{Name: "Margherita", Quantity: 1, Price: 10}},
+		Priority:     1,
+		Status:       types.StatusOrderCancelled,
+	}
+	order, err := e.orders.Create(ctx, req, "order-service", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Version = order.Version
+	if err := e.producer.PublishCreateOrder(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	w := e.newWorker(t, "chef_1", []string{types.OrderTypeTakeOut}, nil, 10*time.Millisecond)
+	stop := start(w)
+	defer stop()
+
+	queue := memory.QueueByOrderType(location, types.OrderTypeTakeOut)
+	waitFor(t, "message to be acknowledged", func() bool {
+		return len(e.broker.Ready(queue)) == 0 && e.broker.Unacked(queue) == 0
+	})
+	e.expectNoUpdate(t)
+
+	got, err := e.orders.GetByNumber(ctx, location, "ORD_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != types.StatusOrderCancelled {
+		t.Fatalf("order is %s, want %s", got.Status, types.StatusOrderCancelled)
+	}
+}
+
+func TestWorkerDeadLettersInvalidMessages(t *testing.T) {
+	e := newKitchenEnv(t)
+	w := e.newWorker(t, "chef_1", []string{types.OrderTypeTakeOut}, nil, 10*time.Millisecond)
+	stop := start(w)
+	defer stop()
+
+	routingKey := "kitchen." + location + ".takeout.1"
+	if _, err := e.broker.Publish(orderExchange, routingKey, memory.Message{Body: []byte("not json")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Order of another location routed to the queue by mistake is never cooked here.
+	body, err := json.Marshal(rabbit.FromInternalToPublishOrder(context.Background(), &models.CreateOrder{
+		LocationID: "other",
+		Number:     "ORD_1",
+		Type:       types.OrderTypeTakeOut,
+		Priority:   1,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.broker.Publish(orderExchange, routingKey, memory.Message{Body: body, Priority: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	dlq := memory.DLQByQueue(memory.QueueByOrderType(location, types.OrderTypeTakeOut))
+	waitFor(t, "messages to be dead-lettered", func() bool { return len(e.broker.Ready(dlq)) == 2 })
+
+	for _, msg := range e.broker.Ready(dlq) {
+		if msg.Deaths != 1 || msg.RoutingKey != dlq {
+			t.Fatalf("dead message with %d deaths and key %s", msg.Deaths, msg.RoutingKey)
+		}
+	}
+	e.expectNoUpdate(t)
+}
+
+func TestWorkerFinishesOrderOnStop(t *testing.T) {
+	e := newKitchenEnv(t)
+	w := e.newWorker(t, "chef_1", []string{types.OrderTypeDelivery}, nil, time.Minute)
+	stop := start(w)
+
+	e.placeOrder(t, "ORD_1", types.OrderTypeDelivery, 1)
+	e.expectUpdate(t, "ORD_1", types.StatusOrderReceived, types.StatusOrderCooking)
+
+	// Stopping interrupts cooking, the taken order is still completed and acknowledged.
+	started := time.Now()
+	if err := stop(); !errors.Is(err, kitchen.ErrWorkerStopped) {
+		t.Fatalf("worker stopped with %v, want %v", err, kitchen.ErrWorkerStopped)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("stop took %s", elapsed)
+	}
+
+	e.expectUpdate(t, "ORD_1", types.StatusOrderCooking, types.StatusOrderReady)
+	e.waitStatus(t, "ORD_1", types.StatusOrderReady)
+
+	queue := memory.QueueByOrderType(location, types.OrderTypeDelivery)
+	if n := len(e.broker.Ready(queue)) + e.broker.Unacked(queue); n != 0 {
+		t.Fatalf("%d messages left in the queue", n)
+	}
+
+	worker, err := e.workers.Get(context.Background(), location, "chef_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worker.Status != types.WorkerOffline {
+		t.Fatalf("worker is %s, want offline", worker.Status)
+	}
+}
+
+func TestOrderTakenWhileStoppingIsRequeued(t *testing.T) {
+	e := newKitchenEnv(t)
+	w := e.newWorker(t, "chef_1", []string{types.OrderTypeTakeOut, types.OrderTypeDelivery}, nil, 200*time.Millisecond)
+	stopWork := start(w)
+
+	e.placeOrder(t, "ORD_1", types.OrderTypeTakeOut, 1)
+	e.expectUpdate(t, "ORD_1", types.StatusOrderReceived, types.StatusOrderCooking)
+
+	// Worker is stopping while it cooks the first order, a new order is delivered meanwhile.
+	stopped := make(chan struct{})
+	go func() {
+		w.Stop(context.Background())
+		close(stopped)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	e.placeOrder(t, "ORD_2", types.OrderTypeDelivery, 1)
+
+	<-stopped
+	if err := stopWork(); !errors.Is(err, kitchen.ErrWorkerStopped) {
+		t.Fatalf("worker stopped with %v, want %v", err, kitchen.ErrWorkerStopped)
+	}
+	e.expectUpdate(t, "ORD_1", types.StatusOrderCooking, types.StatusOrderReady)
+
+	// The new order is returned to the queue, not dead-lettered.
+	queue := memory.QueueByOrderType(location, types.OrderTypeDelivery)
+	ready := e.broker.Ready(queue)
+	if len(ready) != 1 || !ready[0].Redelivered {
+		t.Fatalf("queue has %d messages, want the redelivered order", len(ready))
+	}
+	if n := len(e.broker.Ready(memory.DLQByQueue(queue))); n != 0 {
+		t.Fatalf("dlq has %d messages", n)
+	}
+	e.waitStatus(t, "ORD_2", types.StatusOrderReceived)
+
+	// Another worker cooks the redelivered order.
+	stop := start(e.newWorker(t, "chef_2", []string{types.OrderTypeDelivery}, nil, 10*time.Millisecond))
+	defer stop()
+
+	e.expectUpdate(t, "ORD_2", types.StatusOrderReceived, types.StatusOrderCooking)
+	e.expectUpdate(t, "ORD_2", types.StatusOrderCooking, types.StatusOrderReady)
+	if order := e.waitStatus(t, "ORD_2", types.StatusOrderReady); *order.ProcessedBy != "chef_2" {
+		t.Fatalf("order processed by %s, want chef_2", *order.ProcessedBy)
+	}
+}
+
+func TestWorkerCanRegisterAgainAfterStop(t *testing.T) {
+	e := newKitchenEnv(t)
+
+	first := e.newWorker(t, "chef_1", []string{types.OrderTypeTakeOut}, nil, 10*time.Millisecond)
+	stopFirst := start(first)
+	waitFor(t, "worker to register", func() bool {
+		worker, err := e.workers.Get(context.Background(), location, "chef_1")
+		return err == nil && worker.Status == types.WorkerOnline
+	})
+
+	// The name is taken while the worker is online.
+	errCh := make(chan error, 2)
+	go e.newWorker(t, "chef_1", []string{types.OrderTypeTakeOut}, nil, 10*time.Millisecond).Work(context.Background(), errCh)
+	select {
+	case err := <-errCh:
+		if err == nil || errors.Is(err, kitchen.ErrWorkerStopped) {
+			t.Fatalf("duplicate worker stopped with %v, want registration error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("duplicate worker is registered")
+	}
+
+	if err := stopFirst(); !errors.Is(err, kitchen.ErrWorkerStopped) {
+		t.Fatalf("worker stopped with %v, want %v", err, kitchen.ErrWorkerStopped)
+	}
+
+	stop := start(e.newWorker(t, "chef_1", []string{types.OrderTypeTakeOut}, nil, 10*time.Millisecond))
+	defer stop()
+
+	e.placeOrder(t, "ORD_1", types.OrderTypeTakeOut, 1)
+	e.waitStatus(t, "ORD_1", types.StatusOrderReady)
+
+	sessions, err := e.workers.Sessions(context.Background(), location, "chef_1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 || sessions[0].EndedAt != nil || sessions[1].EndReason == nil || *sessions[1].EndReason != "graceful" {
+		t.Fatalf("sessions = %+v, want open session after graceful one", sessions)
+	}
+}
+
+func TestEvictedWorkerLeavesNewInstanceAlone(t *testing.T) {
+	e := newKitchenEnv(t)
+	ctx := context.Background()
+
+	old := e.newWorker(t, "chef_1", []string{types.OrderTypeTakeOut}, nil, 10*time.Millisecond)
+	errCh := make(chan error, 2)
+	go old.Work(ctx, errCh)
+	waitFor(t, "worker to register", func() bool {
+		worker, err := e.workers.Get(ctx, location, "chef_1")
+		return err == nil && worker.Status == types.WorkerOnline
+	})
+
+	// A new instance registers before the evicted one picks up the command.
+	if _, err := e.workers.SetCommand(ctx, location, "chef_1", types.WorkerCommandEvict, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	stop := start(e.newWorker(t, "chef_1", []string{types.OrderTypeTakeOut}, nil, 10*time.Millisecond))
+	defer stop()
+	waitFor(t, "new instance to register", func() bool {
+		worker, err := e.workers.Get(ctx, location, "chef_1")
+		return err == nil && worker.Status == types.WorkerOnline
+	})
+
+	old.SetHeartbeat(10 * time.Millisecond)
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, kitchen.ErrWorkerEvicted) {
+			t.Fatalf("old instance stopped with %v, want %v", err, kitchen.ErrWorkerEvicted)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("old instance is not stopped")
+	}
+
+	worker, err := e.workers.Get(ctx, location, "chef_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := e.workers.Sessions(ctx, location, "chef_1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if worker.Status != types.WorkerOnline || len(sessions) != 2 || sessions[0].EndedAt != nil {
+		t.Fatalf("new instance is %s with sessions %+v, want online with open session", worker.Status, sessions)
+	}
+	if sessions[1].EndReason == nil || *sessions[1].EndReason != "evicted" {
+		t.Fatalf("old session = %+v, want evicted", sessions[1])
+	}
+}
+
+func TestStationsCompleteOrder(t *testing.T) {
+	e := newKitchenEnv(t)
+
+	stopGrill := start(e.newWorker(t, "grill_1", nil, []string{"grill"}, 10*time.Millisecond))
+	defer stopGrill()
+	stopBar := start(e.newWorker(t, "bar_1", nil, []string{"bar"}, 50*time.Millisecond))
+	defer stopBar()
+
+	e.placeOrder(t, "ORD_1", types.OrderTypeDineIn, 1,
+		models.CreateOrderItem{Name: "Steak", Quantity: 1, Price: 20, Station: "grill"},
+		models.CreateOrderItem{Name: "Lemonade", Quantity: 2, Price: 3, Station: "bar"},
+	)
+
+	// The first started station moves the order to cooking, the last finished one makes it ready.
+	e.expectUpdate(t, "ORD_1", types.StatusOrderReceived, types.StatusOrderCooking)
+	e.expectUpdate(t, "ORD_1", types.StatusOrderCooking, types.StatusOrderReady)
+	e.expectNoUpdate(t)
+
+	order := e.waitStatus(t, "ORD_1", types.StatusOrderReady)
+	for _, item := range order.Items {
+		if item.Status != types.StatusOrderReady {
+			t.Fatalf("item %s is %s, want ready", item.Name, item.Status)
+		}
+	}
+
+	history, err := memory.NewStatusRepo(e.store).ListOrderHistory(context.Background(), location, "ORD_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var statuses, stations []string
+	var ready models.OrderHistory
+	for _, h := range history {
+		if h.Station == "" {
+			statuses = append(statuses, h.Status)
+			ready = h
+		} else {
+			stations = append(stations, h.Station+":"+h.Status)
+		}
+	}
+	if len(statuses) != 3 || statuses[0] != types.StatusOrderReceived || statuses[1] != types.StatusOrderCooking || statuses[2] != types.StatusOrderReady {
+		t.Fatalf("order statuses = %v", statuses)
+	}
+	if len(stations) != 4 {
+		t.Fatalf("station progress = %v, want cooking and ready of both stations", stations)
+	}
+	if ready.Notes != "all stations ready" || ready.ChangedBy != "bar_1" {
+		t.Fatalf("ready entry = %+v, want the order made ready by the last station", ready)
+	}
+}
@@ -0,0 +1,92 @@
+package kitchen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/utils"
+)
+
+// proccessStation cooks items of one kitchen station of the order. Status updates are published when
+// the first station starts the order and when the last station finishes it.
+func (s *KitchenWorker) proccessStation(ctx context.Context, req *models.CreateOrder) error {
+	cookingTime := s.cookingTime(req.Type) // Simulated time
+
+	s.log.Debug(
+		ctx,
+		types.ActionOrderProcessingStarted,
+		"kitchen worker started proccessing station items",
+		"worker-name", s.worker.name,
+		"order-number", req.Number,
+		"station", req.Station,
+		"cooking-time", utils.PrettyDuration(cookingTime))
+
+	oldStatus, err := s.orderRepo.StartStation(ctx, s.worker.location, req.Number, req.Station, s.worker.name, req.Priority, req.Version)
+	if errors.Is(err, models.ErrOrderSuperseded) || errors.Is(err, models.ErrStationDone) {
+		s.log.Debug(ctx, types.ActionOrderSkipped, "skipping stale copy of station items", "worker-name", s.worker.name, "order-number", req.Number, "station", req.Station, "reason", err.Error())
+		return ErrStaleOrder
+	}
+	if err != nil {
+		s.log.Error(ctx, types.ActionMessageProcessingFailed, "failed to start station items", err, "worker-name", s.worker.name, "station", req.Station)
+		return fmt.Errorf("failed to start station items: %w", err)
+	}
+
+	completion := time.Now().Add(cookingTime)
+
+	var requestID string
+	if reqID, ok := ctx.Value(models.GetRequestIDKey()).(string); ok {
+		requestID = reqID
+	}
+
+	// Only the first station changes status of the order.
+	if oldStatus == types.StatusOrderReceived {
+		s.publishStatus(ctx, req.Number, oldStatus, types.StatusOrderCooking, completion, requestID)
+	}
+
+	// Simulating working process with context cancellation support
+	select {
+	case <-time.After(cookingTime):
+		// cooked
+	case <-ctx.Done():
+		s.log.Warn(ctx, types.ActionMessageProcessingFailed, "station processing interrupted but completing", "order-number", req.Number, "station", req.Station, "context-error", ctx.Err())
+		ctx = context.WithoutCancel(ctx) // the items are still completed on shutdown
+	}
+
+	oldStatus, err = s.orderRepo.FinishStation(ctx, s.worker.location, req.Number, req.Station, s.worker.name)
+	if err != nil {
+		s.log.Error(ctx, types.ActionMessageProcessingFailed, "failed to finish station items", err, "worker-name", s.worker.name, "station", req.Station)
+		return fmt.Errorf("failed to finish station items: %w", err)
+	}
+
+	// Status is returned only by the last station, which completed the order.
+	if oldStatus != "" {
+		s.publishStatus(ctx, req.Number, oldStatus, types.StatusOrderReady, completion, requestID)
+	}
+
+	if err := s.workerRepo.IncrOrdersProcessed(ctx, s.worker.location, s.worker.name); err != nil {
+		s.log.Error(ctx, types.ActionMessageProcessingFailed, "failed to increment number of ordered", err, "worker-name", s.worker.name)
+	}
+
+	s.log.Debug(ctx, types.ActionOrderCompleted, "station items finished", "worker-name", s.worker.name, "station", req.Station, "order-ready", oldStatus != "")
+	return nil
+}
+
+// publishStatus publishes status update of the order. Status is already stored, so failures are only logged.
+func (s *KitchenWorker) publishStatus(ctx context.Context, orderNumber, oldStatus, newStatus string, completion time.Time, requestID string) {
+	if err := s.producer.StatusUpdate(ctx, &models.StatusUpdate{
+		LocationID:  s.worker.location,
+		OrderNumber: orderNumber,
+		OldStatus:   oldStatus,
+		NewStatus:   newStatus,
+		ChangedBy:   s.worker.name,
+		Timestamp:   time.Now(),
+		Completion:  completion,
+		RequestID:   requestID,
+	}); err != nil {
+		s.log.Error(ctx, types.ActionRabbitMQPublishFailed, "failed to publish status update", err, "worker-name", s.worker.name, "new-status", newStatus)
+	}
+}
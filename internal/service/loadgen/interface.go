@@ -0,0 +1,18 @@
+package loadgen
+
+import (
+	"context"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// OrderClient sends JSON encoded orders to the order-service under load.
+type OrderClient interface {
+	CreateOrder(ctx context.Context, locationID string, order []byte) (status int, orderNumber string, err error)
+}
+
+// NotificationConsumer delivers status updates of the orders, time to ready is measured with them.
+type NotificationConsumer interface {
+	StartListening(ctx context.Context) (chan models.Notification, error)
+	Close() error
+}
@@ -0,0 +1,31 @@
+package loadgen
+
+import (
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// Profile is the traffic shape of a run, the request rate at every moment of it.
+type Profile struct {
+	Name        string
+	Rate        float64 // requests per second, the peak of ramp and burst
+	Duration    time.Duration
+	BurstEvery  time.Duration
+	BurstLength time.Duration
+}
+
+// RateAt returns requests per second the profile sends at elapsed time since the start.
+func (p Profile) RateAt(elapsed time.Duration) float64 {
+	switch p.Name {
+	case types.LoadProfileRamp:
+		return p.Rate * min(float64(elapsed)/float64(p.Duration), 1)
+	case types.LoadProfileBurst:
+		if elapsed%p.BurstEvery < p.BurstLength {
+			return p.Rate
+		}
+		return p.Rate / 10
+	default:
+		return p.Rate
+	}
+}
@@ -0,0 +1,266 @@
+package loadgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// tick is how often the rate of the profile is checked and due requests are sent.
+const tick = 10 * time.Millisecond
+
+type Service struct {
+	client      OrderClient
+	consumer    NotificationConsumer
+	templates   *Templates
+	profile     Profile
+	location    string
+	targetURL   string // reported only
+	maxInFlight int
+	drain       time.Duration
+	reportPath  string
+
+	log logger.Logger
+}
+
+func NewService(client OrderClient, consumer NotificationConsumer, templates *Templates, profile Profile, location, targetURL string, maxInFlight int, drain time.Duration, reportPath string, log logger.Logger) *Service {
+	return &Service{
+		client:      client,
+		consumer:    consumer,
+		templates:   templates,
+		profile:     profile,
+		location:    location,
+		targetURL:   targetURL,
+		maxInFlight: maxInFlight,
+		drain:       drain,
+		reportPath:  reportPath,
+		log:         log,
+	}
+}
+
+// stats are collected by requests and notifications of a run.
+type stats struct {
+	mu        sync.Mutex
+	sent      int
+	skipped   int
+	errors    int
+	codes     map[int]int
+	latencies []time.Duration
+	created   map[string]time.Time // order number -> request sent
+	ready     map[string]time.Time // order number -> ready notification received
+}
+
+// Run sends orders by the traffic profile, waits up to drain for created orders to be ready
+// and writes the report. Cancelling ctx stops sending, the report is written anyway.
+func (s *Service) Run(ctx context.Context) (*models.LoadReport, error) {
+	st := &stats{
+		codes:   make(map[int]int),
+		created: make(map[string]time.Time),
+		ready:   make(map[string]time.Time),
+	}
+
+	updates, err := s.consumer.StartListening(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for notifications: %w", err)
+	}
+	go s.collectReady(st, updates)
+
+	s.log.Info(ctx, types.ActionLoadGenStarted, "load generation started", "profile", s.profile.Name, "rate", s.profile.Rate, "duration", s.profile.Duration.String())
+
+	started := time.Now()
+	s.generate(ctx, st, started)
+	sending := time.Since(started)
+
+	s.waitReady(ctx, st)
+
+	report := s.report(st, started, sending)
+	if err := s.writeReport(report); err != nil {
+		return report, err
+	}
+
+	s.log.Info(ctx, types.ActionLoadGenFinished, "load generation finished",
+		"sent", report.Sent, "created", report.Created, "ready", report.Ready, "errors", report.Errors,
+		"latency-p99-ms", report.Latency.P99, "ready-p99-ms", report.TimeToReady.P99, "report", s.reportPath)
+
+	return report, nil
+}
+
+// generate sends requests at the rate of the profile until its duration is over or ctx is cancelled.
+// Requests due while maxInFlight requests wait for a response are skipped, so a slow service does not slow the rate.
+func (s *Service) generate(ctx context.Context, st *stats, started time.Time) {
+	var (
+		wg     sync.WaitGroup
+		slots  = make(chan struct{}, s.maxInFlight)
+		tokens float64
+		last   = started
+	)
+
+	// Requests in flight are finished when the run is stopped.
+	reqCtx := context.WithoutCancel(ctx)
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case now := <-ticker.C:
+			elapsed := now.Sub(started)
+			if elapsed >= s.profile.Duration {
+				break loop
+			}
+
+			tokens += s.profile.RateAt(elapsed) * now.Sub(last).Seconds()
+			last = now
+
+			for ; tokens >= 1; tokens-- {
+				select {
+				case slots <- struct{}{}:
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						defer func() { <-slots }()
+						s.send(reqCtx, st)
+					}()
+				default:
+					st.mu.Lock()
+					st.skipped++
+					st.mu.Unlock()
+				}
+			}
+		}
+	}
+
+	wg.Wait()
+}
+
+func (s *Service) send(ctx context.Context, st *stats) {
+	body := s.templates.Random()
+
+	sentAt := time.Now()
+	code, number, err := s.client.CreateOrder(ctx, s.location, body)
+	latency := time.Since(sentAt)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.sent++
+	if err != nil && code == 0 {
+		st.errors++
+		s.log.Debug(ctx, types.ActionLoadGenRequestFailed, "request failed", "error", err.Error())
+		return
+	}
+
+	st.codes[code]++
+	st.latencies = append(st.latencies, latency)
+	if code == http.StatusCreated && number != "" {
+		st.created[number] = sentAt
+	}
+}
+
+// collectReady records when orders of the location become ready.
+func (s *Service) collectReady(st *stats, updates chan models.Notification) {
+	for n := range updates {
+		update := n.StatusUpdate
+		if update == nil || update.NewStatus != types.StatusOrderReady || update.LocationID != s.location {
+			continue
+		}
+
+		st.mu.Lock()
+		st.ready[update.OrderNumber] = time.Now()
+		st.mu.Unlock()
+	}
+}
+
+// waitReady waits until every created order is ready, at most drain.
+func (s *Service) waitReady(ctx context.Context, st *stats) {
+	deadline := time.After(s.drain)
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		st.mu.Lock()
+		pending := 0
+		for number := range st.created {
+			if _, ok := st.ready[number]; !ok {
+				pending++
+			}
+		}
+		st.mu.Unlock()
+
+		if pending == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-deadline:
+			s.log.Warn(ctx, types.ActionLoadGenFinished, "orders not ready after drain", "orders", pending)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) report(st *stats, started time.Time, sending time.Duration) *models.LoadReport {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	report := &models.LoadReport{
+		Profile:     s.profile.Name,
+		TargetRate:  s.profile.Rate,
+		Duration:    s.profile.Duration.String(),
+		StartedAt:   started,
+		FinishedAt:  time.Now(),
+		LocationID:  s.location,
+		TargetURL:   s.targetURL,
+		Sent:        st.sent,
+		Skipped:     st.skipped,
+		SentRate:    math.Round(float64(st.sent)/sending.Seconds()*100) / 100,
+		Created:     len(st.created),
+		Errors:      st.errors,
+		StatusCodes: make(map[string]int, len(st.codes)),
+		Latency:     models.NewPercentiles(st.latencies),
+	}
+	for code, n := range st.codes {
+		report.StatusCodes[strconv.Itoa(code)] = n
+	}
+
+	var ready []time.Duration
+	for number, sentAt := range st.created {
+		if readyAt, ok := st.ready[number]; ok {
+			ready = append(ready, readyAt.Sub(sentAt))
+		}
+	}
+	report.Ready = len(ready)
+	report.NotReady = report.Created - report.Ready
+	report.TimeToReady = models.NewPercentiles(ready)
+
+	return report
+}
+
+func (s *Service) writeReport(report *models.LoadReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(s.reportPath, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package loadgen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+)
+
+// customerNames are given to generated orders, names must pass order validation.
+var customerNames = []string{
+	"John Smith", "Jane Doe", "Aigerim Nurlanova", "Marco Rossi", "Li Wei", "Olivia Brown",
+	"Arman Serikov", "Sofia Garcia", "Noah Wilson", "Dana O'Neil", "Timur Akhmetov", "Emma Muller",
+}
+
+// Templates are create order requests orders are generated from.
+type Templates struct {
+	orders [][]byte
+}
+
+// LoadTemplates reads templates from the JSONL file, a create order request in every line.
+func LoadTemplates(path string) (*Templates, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	t := &Templates{}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var order map[string]any
+		if err := json.Unmarshal(line, &order); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		if items, _ := order["items"].([]any); len(items) == 0 {
+			return nil, fmt.Errorf("line %d: order has no items", n)
+		}
+
+		t.orders = append(t.orders, bytes.Clone(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(t.orders) == 0 {
+		return nil, fmt.Errorf("%s has no templates", path)
+	}
+
+	return t, nil
+}
+
+// Random returns a random template with randomized fields: customer name of orders without customer account,
+// a random subset of the items and quantities from 1 to 3. Other fields are kept as is.
+func (t *Templates) Random() []byte {
+	var order map[string]any
+	json.Unmarshal(t.orders[rand.IntN(len(t.orders))], &order) // validated by LoadTemplates
+
+	if _, ok := order["customer_id"]; !ok {
+		order["customer_name"] = customerNames[rand.IntN(len(customerNames))]
+	}
+
+	items := order["items"].([]any)
+	rand.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+	items = items[:1+rand.IntN(len(items))]
+	for _, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			m["quantity"] = 1 + rand.IntN(3)
+		}
+	}
+	order["items"] = items
+
+	body, _ := json.Marshal(order)
+	return body
+}
@@ -7,10 +7,11 @@ import (
 )
 
 type NotificationConsumer interface {
-	StartListening(ctx context.Context) (chan models.StatusUpdate, error)
+	StartListening(ctx context.Context) (chan models.Notification, error)
 	Close() error
 }
 
 type Notifier interface {
 	StatusUpdate(ctx context.Context, req models.StatusUpdate)
+	LowStock(ctx context.Context, alert models.LowStockAlert)
 }
@@ -21,18 +21,21 @@ func NewNotifyPrinter(log logger.Logger) *NotifyPrinter {
 
 // StatusUpdate just prints status update information to the console
 func (s *NotifyPrinter) StatusUpdate(ctx context.Context, update models.StatusUpdate) {
-	fmt.Printf("Notification for order %s: Status changed from '%s' to '%s' by %s\n",
+	fmt.Printf("Notification for order %s (location %s): Status changed from '%s' to '%s' by %s\n",
 		update.OrderNumber,
+		update.LocationID,
 		update.OldStatus,
 		update.NewStatus,
 		update.ChangedBy,
 	)
 
 	details := struct {
-		Number    string `json:"order_number"`
-		NewStatus string `json:"new_status"`
+		LocationID string `json:"location_id"`
+		Number     string `json:"order_number"`
+		NewStatus  string `json:"new_status"`
 	}{
-		Number:    update.OrderNumber,
+		LocationID: update.LocationID,
+		Number:     update.OrderNumber,
 		NewStatus: update.NewStatus,
 	}
 
@@ -43,3 +46,21 @@ func (s *NotifyPrinter) StatusUpdate(ctx context.Context, update models.StatusUp
 		"details", details,
 	)
 }
+
+// LowStock prints low stock alert to the console
+func (s *NotifyPrinter) LowStock(ctx context.Context, alert models.LowStockAlert) {
+	fmt.Printf("Low stock at location %s: %s has %g %s left (threshold %g)\n",
+		alert.LocationID,
+		alert.Ingredient,
+		alert.Available,
+		alert.Unit,
+		alert.Threshold,
+	)
+
+	s.log.Info(ctx, types.ActionNotificationReceived,
+		fmt.Sprintf("Received low stock alert for %s", alert.Ingredient),
+		"location_id", alert.LocationID,
+		"available", alert.Available,
+		"threshold", alert.Threshold,
+	)
+}
@@ -36,8 +36,13 @@ func (s *Service) Notify(ctx context.Context, errCh chan error) {
 		errCh <- ErrNotificationStopped
 	}()
 
-	for update := range updateCh {
-		s.writer.StatusUpdate(ctx, update)
+	for notification := range updateCh {
+		switch {
+		case notification.StatusUpdate != nil:
+			s.writer.StatusUpdate(ctx, *notification.StatusUpdate)
+		case notification.LowStock != nil:
+			s.writer.LowStock(ctx, *notification.LowStock)
+		}
 	}
 }
 
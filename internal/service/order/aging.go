@@ -0,0 +1,78 @@
+package order
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// Max orders promoted by one rule in one check, the rest are promoted on the next check.
+const agingBatchSize = 100
+
+// Ager prevents starvation of low priority orders. Kitchen queues are priority queues, so a stream of
+// expensive orders could delay cheap ones forever. Ager raises priority of orders which have been waiting
+// longer than aging rules allow and republishes them. Stale copies in the queue are skipped by kitchen workers.
+type Ager struct {
+	repo   AgingRepository
+	writer MessageBroker
+	rules  atomic.Pointer[[]models.AgingRule]
+
+	log logger.Logger
+}
+
+func NewAger(repo AgingRepository, writer MessageBroker, rules []models.AgingRule, log logger.Logger) *Ager {
+	a := &Ager{
+		repo:   repo,
+		writer: writer,
+		log:    log,
+	}
+	a.SetRules(rules)
+
+	return a
+}
+
+// SetRules replaces aging rules. No rules disables aging.
+func (a *Ager) SetRules(rules []models.AgingRule) {
+	a.rules.Store(&rules)
+}
+
+// Run checks waiting orders every interval until ctx is done.
+func (a *Ager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.promote(ctx)
+		}
+	}
+}
+
+// promote applies rules from the longest wait, so an order waiting long enough for several rules
+// is republished once with the highest priority.
+func (a *Ager) promote(ctx context.Context) {
+	rules := *a.rules.Load()
+
+	for i := len(rules) - 1; i >= 0; i-- {
+		rule := rules[i]
+
+		n, err := a.repo.PromoteWaiting(ctx, rule.After, rule.Priority, servicename, agingBatchSize, func(order *models.CreateOrder) error {
+			return a.writer.PublishCreateOrder(ctx, order)
+		})
+		if err != nil {
+			a.log.Error(ctx, types.ActionDBTransactionFailed, "failed to promote waiting orders", err, "priority", rule.Priority)
+			continue
+		}
+
+		if n > 0 {
+			a.log.Info(ctx, types.ActionOrderPriorityAged, "promoted waiting orders", "orders", n, "priority", rule.Priority, "waited", rule.After.String())
+		}
+	}
+}
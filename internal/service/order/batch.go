@@ -0,0 +1,71 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// CreateBatch creates orders of one location. Every order is created on its own and takes a slot like a single
+// order, so a failed order does not cancel the others. Orders are checked first and numbers are reserved in one
+// block for the orders which passed; an order rejected when it is stored (e.g. out of stock) leaves a gap.
+func (s *Service) CreateBatch(ctx context.Context, locationID string, reqs []*models.CreateOrder) []models.BatchResult {
+	results := make([]models.BatchResult, len(reqs))
+	if len(reqs) == 0 {
+		return results
+	}
+
+	if locationID == "" {
+		locationID = s.cfg.Location
+	}
+
+	prepared := make([]int, 0, len(reqs))
+	for i, req := range reqs {
+		req.LocationID = locationID
+
+		results[i].Err = s.inSlot(ctx, func() error {
+			return s.prepareOrder(ctx, req)
+		})
+		if results[i].Err == nil {
+			prepared = append(prepared, i)
+		}
+	}
+
+	if len(prepared) == 0 {
+		return results
+	}
+
+	today := todayDate()
+	first, err := s.orderRepo.ReserveSequences(ctx, locationID, today, len(prepared))
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to reserve order sequences. generating random order_numbers", err)
+		first = 0
+	}
+
+	for j, i := range prepared {
+		number := first + j
+		if first == 0 {
+			number = getRandomOrderNumber()
+		}
+
+		results[i].Err = s.inSlot(ctx, func() error {
+			results[i].Info, err = s.storeOrder(ctx, reqs[i], today, number)
+			return err
+		})
+	}
+
+	return results
+}
+
+// inSlot runs fn in a slot of concurrently created orders. Returns ErrTooManyRequest if no slot is free in time.
+func (s *Service) inSlot(ctx context.Context, fn func() error) error {
+	if !s.sem.TryAcquire(time.Duration(s.semWait.Load())) {
+		s.log.Error(ctx, types.ActionOrderProccessingFailed, "failed to proccess order", ErrTooManyRequest)
+		return ErrTooManyRequest
+	}
+	defer s.sem.Release()
+
+	return fn()
+}
@@ -0,0 +1,73 @@
+package order_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/order"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/semaphore"
+)
+
+func batchOrder(promoCode string) *models.CreateOrder {
+	return &models.CreateOrder{
+		CustomerName: "Alice",
+		Type:         types.OrderTypeTakeOut,
+		Items:        []models.CreateOrderItem{{Name: "Margherita", Quantity: 1, Price: 10}},
+		PromoCode:    promoCode,
+	}
+}
+
+func TestCreateBatchSkipsNumbersOfRejectedOrders(t *testing.T) {
+	s, _ := newTestService(t, deps{sem: semaphore.NewSemaphore(1)})
+
+	results := s.CreateBatch(context.Background(), "", []*models.CreateOrder{
+		batchOrder(""),
+		batchOrder("UNKNOWN"),
+		batchOrder(""),
+	})
+
+	if !errors.Is(results[1].Err, models.ErrPromotionNotFound) {
+		t.Fatalf("order with unknown promo code: %v", results[1].Err)
+	}
+
+	today := time.Now().UTC().Format("20060102")
+	for i, want := range map[int]string{0: "ORD_" + today + "_001", 2: "ORD_" + today + "_002"} {
+		if results[i].Err != nil {
+			t.Fatalf("order %d: %v", i, results[i].Err)
+		}
+		if results[i].Info.Number != want {
+			t.Fatalf("order %d number %s, want %s", i, results[i].Info.Number, want)
+		}
+	}
+}
+
+func TestCreateBatchTakesSlotPerOrder(t *testing.T) {
+	sem := semaphore.NewSemaphore(1)
+	s, _ := newTestService(t, deps{sem: sem})
+
+	// A single order holds the only slot, so no order of the batch gets in.
+	if !sem.TryAcquire(0) {
+		t.Fatal("slot is taken")
+	}
+	results := s.CreateBatch(context.Background(), "", []*models.CreateOrder{batchOrder(""), batchOrder("")})
+	for i, res := range results {
+		if !errors.Is(res.Err, order.ErrTooManyRequest) {
+			t.Fatalf("order %d: %v, want %v", i, res.Err, order.ErrTooManyRequest)
+		}
+	}
+	sem.Release()
+
+	results = s.CreateBatch(context.Background(), "", []*models.CreateOrder{batchOrder(""), batchOrder("")})
+	for i, res := range results {
+		if res.Err != nil {
+			t.Fatalf("order %d: %v", i, res.Err)
+		}
+	}
+	if sem.Used() != 0 {
+		t.Fatalf("%d slots are left taken", sem.Used())
+	}
+}
@@ -9,16 +9,72 @@ import (
 
 type OrderRepository interface {
 	Create(ctx context.Context, req *models.CreateOrder, changedBy, notes string) (*models.Order, error)
-	GetAndIncrementSequence(ctx context.Context, date string) (int, error)
+	GetAndIncrementSequence(ctx context.Context, locationID, date string) (int, error)
+
+	// ReserveSequences reserves n order sequences of the location for the date and returns the first of them.
+	ReserveSequences(ctx context.Context, locationID, date string, n int) (int, error)
+
+	// CancelScheduled cancels a scheduled order. Returns models.ErrOrderNotCancellable if it is already released.
+	CancelScheduled(ctx context.Context, locationID, orderNumber, changedBy, notes string) error
+
+	// Modify changes a received order with modify and republishes it with publish, both inside the transaction.
+	// Returns models.ErrOrderNotModifiable if the kitchen has taken the order and models.ErrOrderPaid if it has a payment.
+	Modify(ctx context.Context, locationID, orderNumber, changedBy string, modify func(order *models.CreateOrder) (string, error), publish func(order *models.CreateOrder) error) (*models.Order, error)
+}
+
+// PriorityPolicy decides priority of a new order.
+type PriorityPolicy interface {
+	Priority(in models.PriorityInput) models.PriorityDecision
+}
+
+type AgingRepository interface {
+	// PromoteWaiting raises priority of orders waiting in the queue and republishes them with publish.
+	PromoteWaiting(ctx context.Context, waited time.Duration, priority int, changedBy string, limit int, publish func(order *models.CreateOrder) error) (int, error)
+}
+
+type SchedulerRepository interface {
+	// ReleaseDue moves scheduled orders whose release time has come to the kitchen and publishes them with publish.
+	ReleaseDue(ctx context.Context, changedBy string, limit int, publish func(order *models.CreateOrder) error) (int, error)
+}
+
+// ShiftRepository returns kitchen shifts, used to warn about orders no shift covers.
+type ShiftRepository interface {
+	List(ctx context.Context, locationID string) ([]models.Shift, error)
+}
+
+// CustomerRepository returns customer accounts orders refer to.
+type CustomerRepository interface {
+	Get(ctx context.Context, id int) (models.Customer, error)
+}
+
+// PromotionRepository returns promo codes orders are discounted with.
+type PromotionRepository interface {
+	GetByCode(ctx context.Context, code string) (models.Promotion, error)
+}
+
+// Geocoder resolves delivery addresses to locations delivery zones are matched by.
+// It returns models.ErrAddressNotFound for addresses it does not know.
+type Geocoder interface {
+	Geocode(ctx context.Context, address string) (models.GeoLocation, error)
+}
+
+type ETAEstimator interface {
+	Estimate(ctx context.Context, locationID, orderType string, queuedAt time.Time) (models.ETA, error)
 }
 
 type MessageBroker interface {
 	PublishCreateOrder(ctx context.Context, order *models.CreateOrder) error
 }
 
+// StockNotifier sends low stock alerts to the notifications exchange.
+type StockNotifier interface {
+	LowStock(ctx context.Context, alert *models.LowStockAlert) error
+}
+
 type Semaphore interface {
 	TryAcquire(timeout time.Duration) bool
 	Release()
+	Resize(max int)
 	Available() int
 	Used() int
 }
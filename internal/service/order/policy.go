@@ -0,0 +1,272 @@
+package order
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/configparser"
+)
+
+// Priority of an order no rule matched.
+const fallbackPriority = 1
+
+// defaultPriorityRules reproduce the original priorities:
+// 10 if total amount is greater than $100, 5 if it is greater than $50, 1 otherwise.
+var defaultPriorityRules = []map[string]string{
+	{"name": "large order", "priority": "10", "total_above": "100"},
+	{"name": "medium order", "priority": "5", "total_above": "50"},
+	{"name": "standard order", "priority": "1"},
+}
+
+// RulePolicy assigns priority of the first rule whose conditions all match the order.
+type RulePolicy struct {
+	rules []PriorityRule
+}
+
+// PriorityRule is a named priority with conditions. Rule without conditions matches any order.
+type PriorityRule struct {
+	Name       string
+	Priority   int
+	conditions []condition
+}
+
+// condition reports if the order matches and explains why.
+type condition func(in models.PriorityInput) (bool, string)
+
+func NewRulePolicy(rules []PriorityRule) *RulePolicy {
+	return &RulePolicy{rules: rules}
+}
+
+// DefaultPriorityPolicy returns policy which sets priority by total amount only.
+func DefaultPriorityPolicy() *RulePolicy {
+	rules, err := ParsePriorityRules(defaultPriorityRules)
+	if err != nil {
+		panic(err)
+	}
+	return NewRulePolicy(rules)
+}
+
+// LoadPriorityPolicy reads rules from the YAML file. Rules are checked in the order of the file.
+func LoadPriorityPolicy(path string) (*RulePolicy, error) {
+	records, err := configparser.ReadYamlList(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := ParsePriorityRules(records)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return NewRulePolicy(rules), nil
+}
+
+// Priority returns priority of the first matching rule, with the rules skipped before it.
+func (p *RulePolicy) Priority(in models.PriorityInput) models.PriorityDecision {
+	var skipped []models.SkippedRule
+
+rules:
+	for _, rule := range p.rules {
+		reasons := make([]string, 0, len(rule.conditions))
+		for _, cond := range rule.conditions {
+			ok, reason := cond(in)
+			if !ok {
+				skipped = append(skipped, models.SkippedRule{Rule: rule.Name, Reason: "failed: " + reason})
+				continue rules
+			}
+			reasons = append(reasons, reason)
+		}
+
+		if len(reasons) == 0 {
+			reasons = append(reasons, "rule has no conditions")
+		}
+
+		return models.PriorityDecision{
+			Priority: rule.Priority,
+			Rule:     rule.Name,
+			Reasons:  reasons,
+			Skipped:  skipped,
+		}
+	}
+
+	return models.PriorityDecision{
+		Priority: fallbackPriority,
+		Reasons:  []string{"no rule matched"},
+		Skipped:  skipped,
+	}
+}
+
+// ParsePriorityRules builds rules from key-value records. Supported keys:
+//
+//	name, priority                   - required, priority is 1-10
+//	total_above, total_at_most       - total amount > or <= the value
+//	order_types                      - comma-separated order types
+//	min_items, max_items             - total quantity of items
+//	vip                              - true or false
+//	hours                            - local time of day, "HH:MM-HH:MM", may wrap over midnight
+//	min_distance_km, max_distance_km - delivery distance, never matches if distance is unknown
+func ParsePriorityRules(records []map[string]string) ([]PriorityRule, error) {
+	rules := make([]PriorityRule, 0, len(records))
+
+	for i, rec := range records {
+		rule, err := parsePriorityRule(rec)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func parsePriorityRule(rec map[string]string) (PriorityRule, error) {
+	rule := PriorityRule{Name: rec["name"]}
+	if rule.Name == "" {
+		return PriorityRule{}, fmt.Errorf("name is required")
+	}
+
+	priority, err := strconv.Atoi(rec["priority"])
+	if err != nil || priority < 1 || priority > types.MaxOrderPriority {
+		return PriorityRule{}, fmt.Errorf("%s: priority must be between 1 and %d", rule.Name, types.MaxOrderPriority)
+	}
+	rule.Priority = priority
+
+	// Keys are checked in fixed order, so explanations are stable.
+	keys := make([]string, 0, len(rec))
+	for key := range rec {
+		if key != "name" && key != "priority" {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		cond, err := parseCondition(key, rec[key])
+		if err != nil {
+			return PriorityRule{}, fmt.Errorf("%s: %s: %w", rule.Name, key, err)
+		}
+		rule.conditions = append(rule.conditions, cond)
+	}
+
+	return rule, nil
+}
+
+func parseCondition(key, value string) (condition, error) {
+	switch key {
+	case "total_above":
+		limit, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		return func(in models.PriorityInput) (bool, string) {
+			return in.TotalAmount > limit, fmt.Sprintf("total_amount %.2f > %.2f", in.TotalAmount, limit)
+		}, nil
+
+	case "total_at_most":
+		limit, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		return func(in models.PriorityInput) (bool, string) {
+			return in.TotalAmount <= limit, fmt.Sprintf("total_amount %.2f <= %.2f", in.TotalAmount, limit)
+		}, nil
+
+	case "order_types":
+		orderTypes := strings.Split(value, ",")
+		for i, ot := range orderTypes {
+			orderTypes[i] = strings.TrimSpace(ot)
+			if !types.IsValidOrderType(orderTypes[i]) {
+				return nil, fmt.Errorf("invalid order type %q", orderTypes[i])
+			}
+		}
+		return func(in models.PriorityInput) (bool, string) {
+			return slices.Contains(orderTypes, in.OrderType), fmt.Sprintf("order_type %s in [%s]", in.OrderType, strings.Join(orderTypes, ", "))
+		}, nil
+
+	case "min_items":
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(in models.PriorityInput) (bool, string) {
+			return in.ItemCount >= limit, fmt.Sprintf("items %d >= %d", in.ItemCount, limit)
+		}, nil
+
+	case "max_items":
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(in models.PriorityInput) (bool, string) {
+			return in.ItemCount <= limit, fmt.Sprintf("items %d <= %d", in.ItemCount, limit)
+		}, nil
+
+	case "vip":
+		vip, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(in models.PriorityInput) (bool, string) {
+			return in.VIP == vip, fmt.Sprintf("vip is %t", in.VIP)
+		}, nil
+
+	case "hours":
+		from, to, err := parseHours(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(in models.PriorityInput) (bool, string) {
+			t := in.CreatedAt.Local()
+			minute := t.Hour()*60 + t.Minute()
+
+			ok := from <= minute && minute < to
+			if from > to { // over midnight
+				ok = minute >= from || minute < to
+			}
+			return ok, fmt.Sprintf("time %s in %s", t.Format("15:04"), value)
+		}, nil
+
+	case "min_distance_km", "max_distance_km":
+		limit, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, err
+		}
+		isMin := key == "min_distance_km"
+		return func(in models.PriorityInput) (bool, string) {
+			if in.DistanceKm == nil {
+				return false, "delivery distance is unknown"
+			}
+			if isMin {
+				return *in.DistanceKm >= limit, fmt.Sprintf("distance %.1fkm >= %.1fkm", *in.DistanceKm, limit)
+			}
+			return *in.DistanceKm <= limit, fmt.Sprintf("distance %.1fkm <= %.1fkm", *in.DistanceKm, limit)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown condition")
+}
+
+// parseHours parses "HH:MM-HH:MM" into minutes of the day.
+func parseHours(s string) (int, int, error) {
+	fromStr, toStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected HH:MM-HH:MM")
+	}
+
+	from, err := time.Parse("15:04", strings.TrimSpace(fromStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("expected HH:MM-HH:MM")
+	}
+
+	to, err := time.Parse("15:04", strings.TrimSpace(toStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("expected HH:MM-HH:MM")
+	}
+
+	return from.Hour()*60 + from.Minute(), to.Hour()*60 + to.Minute(), nil
+}
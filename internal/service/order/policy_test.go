@@ -0,0 +1,149 @@
+package order_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/order"
+)
+
+var distanceRules = []map[string]string{
+	{"name": "far delivery", "priority": "8", "order_types": "delivery", "min_distance_km": "5"},
+	{"name": "near delivery", "priority": "3", "order_types": "delivery", "max_distance_km": "2"},
+	{"name": "standard order", "priority": "1"},
+}
+
+func TestDistanceRules(t *testing.T) {
+	rules, err := order.ParsePriorityRules(distanceRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := order.NewRulePolicy(rules)
+
+	km := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		distance *float64
+		want     string
+	}{
+		{"far", km(7.5), "far delivery"},
+		{"exactly min", km(5), "far delivery"},
+		{"near", km(1.2), "near delivery"},
+		{"between", km(3), "standard order"},
+		{"unknown", nil, "standard order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := policy.Priority(models.PriorityInput{OrderType: types.OrderTypeDelivery, DistanceKm: tt.distance, CreatedAt: time.Now()})
+			if decision.Rule != tt.want {
+				t.Fatalf("rule %q, want %q, skipped %+v", decision.Rule, tt.want, decision.Skipped)
+			}
+		})
+	}
+
+	// Unknown distance never matches either distance condition.
+	decision := policy.Priority(models.PriorityInput{OrderType: types.OrderTypeDelivery})
+	for _, skipped := range decision.Skipped {
+		if skipped.Reason != "failed: delivery distance is unknown" {
+			t.Fatalf("rule %s skipped with %q", skipped.Rule, skipped.Reason)
+		}
+	}
+
+	if _, err := order.ParsePriorityRules([]map[string]string{{"name": "bad", "priority": "5", "min_distance_km": "far"}}); err == nil {
+		t.Fatal("invalid distance is accepted")
+	}
+}
+
+// geocoderStub resolves addresses from the map.
+type geocoderStub map[string]models.GeoLocation
+
+func (g geocoderStub) Geocode(_ context.Context, address string) (models.GeoLocation, error) {
+	loc, ok := g[address]
+	if !ok {
+		return models.GeoLocation{}, models.ErrAddressNotFound
+	}
+	return loc, nil
+}
+
+func TestPriorityByDeliveryDistance(t *testing.T) {
+	zones, err := order.ParseDeliveryZones([]map[string]string{
+		{"zone": "city", "polygon": "43.0 76.0; 44.0 76.0; 44.0 77.0; 43.0 77.0"},
+		{"zone": "suburbs", "postcodes": "050060"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := order.ParsePriorityRules(distanceRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	geocoder := geocoderStub{
+		"near":     {Point: &models.GeoPoint{Lat: 43.2405, Lng: 76.9115}},
+		"far":      {Point: &models.GeoPoint{Lat: 43.3305, Lng: 76.9115}}, // 0.09 degrees of latitude north, 10km
+		"postcode": {Postcode: "050060"},
+	}
+
+	d := deps{policy: order.NewRulePolicy(rules), zones: zones, geocoder: geocoder}
+	d.cfg.Services.Order.Origin = "43.2400 76.9100"
+	s, repo := newTestService(t, d)
+
+	tests := []struct {
+		address      string
+		want         string
+		wantPriority int
+		wantErr      error
+	}{
+		{"near", "near delivery", 3, nil},
+		{"far", "far delivery", 8, nil},
+		{"postcode", "standard order", 1, nil}, // in a zone, but the point is unknown
+		{"unknown", "standard order", 0, models.ErrAddressNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			newOrder := func() *models.CreateOrder {
+				address := tt.address
+				return &models.CreateOrder{
+					CustomerName:    "Alice",
+					Type:            types.OrderTypeDelivery,
+					DeliveryAddress: &address,
+					Items:           []models.CreateOrderItem{{Name: "Margherita", Quantity: 1, Price: 10}},
+				}
+			}
+
+			if decision := s.ExplainPriority(context.Background(), newOrder()); decision.Rule != tt.want {
+				t.Fatalf("rule %q, want %q, skipped %+v", decision.Rule, tt.want, decision.Skipped)
+			}
+
+			// Orders get the same priority when they are created.
+			info, err := s.CreateOrder(context.Background(), newOrder())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("created order: %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			created, err := repo.GetByNumber(context.Background(), location, info.Number)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created.Priority != tt.wantPriority {
+				t.Fatalf("created order priority %d, want %d", created.Priority, tt.wantPriority)
+			}
+		})
+	}
+
+	origin, _ := models.ParseGeoPoint(d.cfg.Services.Order.Origin)
+	far, _ := models.ParseGeoPoint("43.3305 76.9115")
+	if km := origin.DistanceKm(far); math.Abs(km-10.07) > 0.05 {
+		t.Fatalf("distance %.2fkm, want about 10.07km", km)
+	}
+}
@@ -0,0 +1,63 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+)
+
+// applyDiscounts applies promo code and redeemed loyalty points of the order, in this order, and sets points
+// the customer earns with it. Promo code usage and points balance are checked again when the order is stored.
+// Promo codes of modified orders are not checked for usability, they were used when the order was placed.
+func (s *Service) applyDiscounts(ctx context.Context, req *models.CreateOrder, modified bool) error {
+	if req.PromoCode != "" {
+		promotion, err := s.promotions.GetByCode(ctx, req.PromoCode)
+		if err != nil {
+			if errors.Is(err, models.ErrPromotionNotFound) {
+				s.log.Error(ctx, types.ActionValidationFailed, "order rejected", err, "promo_code", req.PromoCode)
+				return err
+			}
+			s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get promotion", err)
+			return fmt.Errorf("failed to get promotion: %w", err)
+		}
+
+		if !modified {
+			if err := promotion.Usable(time.Now()); err != nil {
+				s.log.Error(ctx, types.ActionValidationFailed, "order rejected", err, "promo_code", req.PromoCode)
+				return err
+			}
+		}
+
+		discount, err := promotion.Discount(req.Items, req.Subtotal)
+		if err != nil {
+			s.log.Error(ctx, types.ActionValidationFailed, "order rejected", err, "promo_code", req.PromoCode)
+			return err
+		}
+		req.AddDiscount(discount)
+	}
+
+	if req.RedeemPoints > 0 {
+		// Only points needed to pay the rest of the total are redeemed.
+		value := s.cfg.Services.Order.LoyaltyPointValue
+		req.RedeemPoints = min(req.RedeemPoints, int(math.Floor(req.TotalAmount/value+1e-9)))
+
+		if req.RedeemPoints > 0 {
+			req.AddDiscount(models.Discount{
+				Kind:        types.DiscountLoyalty,
+				Description: fmt.Sprintf("%d loyalty points", req.RedeemPoints),
+				Amount:      models.RoundMoney(float64(req.RedeemPoints) * value),
+			})
+		}
+	}
+
+	if req.CustomerID != nil {
+		req.PointsEarned = int(math.Floor(req.TotalAmount * s.cfg.Services.Order.LoyaltyEarnRate))
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package order
+
+import (
+	"context"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// Max orders released in one check, the rest are released on the next check.
+const releaseBatchSize = 100
+
+// Scheduler publishes scheduled orders to the kitchen when their release time comes. State is kept in
+// database only, so scheduled orders survive restarts and several order-services can run schedulers at once.
+type Scheduler struct {
+	repo   SchedulerRepository
+	writer MessageBroker
+
+	log logger.Logger
+}
+
+func NewScheduler(repo SchedulerRepository, writer MessageBroker, log logger.Logger) *Scheduler {
+	return &Scheduler{
+		repo:   repo,
+		writer: writer,
+		log:    log,
+	}
+}
+
+// Run releases due orders every interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.release(ctx)
+		}
+	}
+}
+
+// release publishes due orders batch by batch until none are left.
+func (s *Scheduler) release(ctx context.Context) {
+	for {
+		n, err := s.repo.ReleaseDue(ctx, servicename, releaseBatchSize, func(order *models.CreateOrder) error {
+			return s.writer.PublishCreateOrder(ctx, order)
+		})
+		if err != nil {
+			s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to release scheduled orders", err)
+			return
+		}
+
+		if n > 0 {
+			s.log.Info(ctx, types.ActionOrderReleased, "released scheduled orders", "orders", n)
+		}
+
+		if n < releaseBatchSize {
+			return
+		}
+	}
+}
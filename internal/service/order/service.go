@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/Temutjin2k/wheres-my-pizza/config"
@@ -18,25 +20,68 @@ var ErrTooManyRequest = errors.New("too many requests")
 const servicename = "order-service"
 
 type Service struct {
-	orderRepo OrderRepository
-	writer    MessageBroker
-	sem       Semaphore
-	semWait   time.Duration
+	orderRepo  OrderRepository
+	writer     MessageBroker
+	notifier   StockNotifier
+	sem        Semaphore
+	estimator  ETAEstimator
+	policy     PriorityPolicy
+	stations   *StationRouter // nil if orders are cooked whole
+	taxes      *TaxTable
+	shifts     ShiftRepository
+	customers  CustomerRepository
+	promotions PromotionRepository
+	zones      *DeliveryZones // nil if delivery is accepted from any address
+	geocoder   Geocoder
+	origin     *models.GeoPoint // restaurant point delivery distance is measured from, nil if not configured
+	semWait    atomic.Int64     // time.Duration
+	lead       atomic.Int64     // time.Duration, release lead of scheduled orders
 
 	cfg config.Config
 	log logger.Logger
 }
 
-func NewService(cfg config.Config, repo OrderRepository, writer MessageBroker, sem Semaphore, semWait time.Duration, log logger.Logger) *Service {
-	return &Service{
-		orderRepo: repo,
-		writer:    writer,
-		sem:       sem,
-		semWait:   time.Second,
+func NewService(cfg config.Config, repo OrderRepository, writer MessageBroker, notifier StockNotifier, sem Semaphore, estimator ETAEstimator, policy PriorityPolicy, stations *StationRouter, taxes *TaxTable, shifts ShiftRepository, customers CustomerRepository, promotions PromotionRepository, zones *DeliveryZones, geocoder Geocoder, semWait time.Duration, log logger.Logger) *Service {
+	s := &Service{
+		orderRepo:  repo,
+		writer:     writer,
+		notifier:   notifier,
+		sem:        sem,
+		estimator:  estimator,
+		policy:     policy,
+		stations:   stations,
+		taxes:      taxes,
+		shifts:     shifts,
+		customers:  customers,
+		promotions: promotions,
+		zones:      zones,
+		geocoder:   geocoder,
 
 		cfg: cfg,
 		log: log,
 	}
+	s.semWait.Store(int64(semWait))
+	s.lead.Store(int64(cfg.Services.Order.ScheduleLead))
+
+	// Origin is validated by config.
+	if cfg.Services.Order.Origin != "" {
+		if origin, err := models.ParseGeoPoint(cfg.Services.Order.Origin); err == nil {
+			s.origin = &origin
+		}
+	}
+
+	return s
+}
+
+// SetLimits changes maximum number of concurrent orders and time to wait for a free slot at runtime.
+func (s *Service) SetLimits(maxConcurrent int, semWait time.Duration) {
+	s.sem.Resize(maxConcurrent)
+	s.semWait.Store(int64(semWait))
+}
+
+// SetScheduleLead changes how long before expected cook time scheduled orders are released.
+func (s *Service) SetScheduleLead(lead time.Duration) {
+	s.lead.Store(int64(lead))
 }
 
 // CreateOrder creates new order
@@ -50,32 +95,126 @@ func (s *Service) CreateOrder(ctx context.Context, req *models.CreateOrder) (*mo
 	)
 
 	// Trying to take slot under s.semWait seconds if not returning error.
-	if !s.sem.TryAcquire(s.semWait) {
+	if !s.sem.TryAcquire(time.Duration(s.semWait.Load())) {
 		s.log.Error(ctx, types.ActionOrderProccessingFailed, "failed to proccess order", ErrTooManyRequest)
 		return nil, ErrTooManyRequest
 	}
 	defer s.sem.Release()
 
+	// Orders without location belong to the location of the service
+	if req.LocationID == "" {
+		req.LocationID = s.cfg.Location
+	}
+
 	today := todayDate()
-	number, err := s.orderRepo.GetAndIncrementSequence(ctx, today)
+	number, err := s.orderRepo.GetAndIncrementSequence(ctx, req.LocationID, today)
 	if err != nil {
 		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get next order sequence. generating random order_number", err)
 		number = getRandomOrderNumber()
 	}
 
-	req.SetNumber(today, number)
+	return s.createOrder(ctx, req, today, number)
+}
+
+// createOrder creates the order with the given number of the date.
+func (s *Service) createOrder(ctx context.Context, req *models.CreateOrder, today string, number int) (*models.OrderCreatedInfo, error) {
+	if err := s.prepareOrder(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.storeOrder(ctx, req, today, number)
+}
+
+// prepareOrder checks the customer, delivery zone and promo code of the order and calculates its totals,
+// priority and status. Stock, loyalty points and tables are checked only when the order is stored.
+func (s *Service) prepareOrder(ctx context.Context, req *models.CreateOrder) error {
+	if err := s.applyCustomer(ctx, req); err != nil {
+		return err
+	}
+
 	req.CalucalteTotalAmount()
-	req.CalculatePriority()
 	req.Status = types.StatusOrderReceived
 
+	// Delivery zone gives the distance priority rules may depend on.
+	if err := s.applyDeliveryZone(ctx, req); err != nil {
+		return err
+	}
+	req.Priority = s.policy.Priority(s.priorityInput(req, time.Now())).Priority
+
+	if err := s.applyDiscounts(ctx, req, false); err != nil {
+		return err
+	}
+
+	s.taxes.Apply(req.Items)
+	req.ApplyCharges(s.cfg.Services.Order.ServiceCharge)
+
+	if s.stations != nil {
+		s.stations.Route(req.Items)
+	}
+
+	// Scheduled orders wait in database until the scheduler releases them to the kitchen.
+	if req.ScheduledFor != nil {
+		releaseAt := req.ScheduledFor.Add(-(types.GetSimulateCookingDuration(req.Type) + time.Duration(s.lead.Load())))
+		req.ReleaseAt = &releaseAt
+		req.Status = types.StatusOrderScheduled
+	}
+
+	// Orders waiting for payment are sent on by payment-service once the payment is authorized.
+	// Orders paid in full with loyalty points need no payment.
+	if s.cfg.Services.Order.RequirePayment && req.TotalAmount > 0 {
+		req.Status = types.StatusOrderPendingPayment
+	}
+
+	return nil
+}
+
+// storeOrder stores the prepared order with the given number of the date and publishes it to the kitchen.
+func (s *Service) storeOrder(ctx context.Context, req *models.CreateOrder, today string, number int) (*models.OrderCreatedInfo, error) {
+	req.SetNumber(today, number)
+
 	// Store order to database
 	order, err := s.orderRepo.Create(ctx, req, servicename, "")
 	if err != nil {
-		s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to create new order", err)
+		if errors.Is(err, models.ErrOutOfStock) || errors.Is(err, models.ErrPromotionExhausted) || errors.Is(err, models.ErrNotEnoughPoints) ||
+			errors.Is(err, models.ErrTableNotFound) || errors.Is(err, models.ErrTableClosed) {
+			s.log.Error(ctx, types.ActionValidationFailed, "order rejected", err)
+		} else {
+			s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to create new order", err)
+		}
 		return nil, fmt.Errorf("failed to create new order: %w", err)
 	}
+	s.notifyLowStock(ctx, order.LowStock)
+
+	coverageAt := time.Now()
+	if req.ScheduledFor != nil {
+		coverageAt = *req.ScheduledFor
+	}
+
+	info := &models.OrderCreatedInfo{
+		LocationID:      order.LocationID,
+		Number:          order.Number,
+		Status:          order.Status,
+		TotalAmount:     order.TotalAmount,
+		Subtotal:        order.Subtotal,
+		Discounts:       order.Discounts,
+		TaxAmount:       order.TaxAmount,
+		ServiceCharge:   order.ServiceCharge,
+		DeliveryFee:     order.DeliveryFee,
+		DeliveryZone:    req.DeliveryZone,
+		Tip:             order.Tip,
+		PointsEarned:    order.PointsEarned,
+		PointsRedeemed:  order.PointsRedeemed,
+		ScheduledFor:    req.ScheduledFor,
+		ReleaseAt:       req.ReleaseAt,
+		CoverageWarning: s.coverageWarning(ctx, order.LocationID, order.Type, coverageAt),
+	}
+
+	// Scheduled orders and orders waiting for payment are not in the kitchen queue yet.
+	if order.Status != types.StatusOrderReceived {
+		return info, nil
+	}
 
 	// Send request info about publishing order with retry
+	req.Version = order.Version
 	if err := retry(s.cfg.RabbitMQ.ReconnectAttempt, s.cfg.RabbitMQ.ReconnectDelay, func() error {
 		return s.writer.PublishCreateOrder(ctx, req)
 	}); err != nil {
@@ -83,11 +222,193 @@ func (s *Service) CreateOrder(ctx context.Context, req *models.CreateOrder) (*mo
 		return nil, fmt.Errorf("failed to publish order: %w", err)
 	}
 
-	return &models.OrderCreatedInfo{
-		Number:      order.Number,
-		Status:      order.Status,
-		TotalAmount: order.TotalAmount,
-	}, nil
+	// Order is already accepted, so it is returned without estimate if estimation fails.
+	eta, err := s.estimator.Estimate(ctx, order.LocationID, order.Type, order.CreatedAt)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to estimate order ready time", err)
+		return info, nil
+	}
+	eta.AddTravel(req.TravelTime)
+	info.ETA = &eta
+
+	return info, nil
+}
+
+// applyCustomer fills customer name and delivery address the order omits from its customer account.
+func (s *Service) applyCustomer(ctx context.Context, req *models.CreateOrder) error {
+	if req.CustomerID == nil {
+		return nil
+	}
+
+	customer, err := s.customers.Get(ctx, *req.CustomerID)
+	if err != nil {
+		if errors.Is(err, models.ErrCustomerNotFound) {
+			s.log.Error(ctx, types.ActionValidationFailed, "order rejected", err, "customer_id", *req.CustomerID)
+			return err
+		}
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get customer", err)
+		return fmt.Errorf("failed to get customer: %w", err)
+	}
+
+	if req.CustomerName == "" {
+		req.CustomerName = customer.Name
+	}
+
+	if req.Type == types.OrderTypeDelivery && req.DeliveryAddress == nil {
+		if customer.DefaultAddress == nil {
+			return models.ErrCustomerNoAddress
+		}
+		req.DeliveryAddress = customer.DefaultAddress
+	}
+
+	return nil
+}
+
+// coverageWarning returns warning if no kitchen shift of the location covers the order type at t.
+// Locations without shifts are not checked.
+func (s *Service) coverageWarning(ctx context.Context, locationID, orderType string, t time.Time) string {
+	shifts, err := s.shifts.List(ctx, locationID)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get worker shifts", err)
+		return ""
+	}
+
+	if len(shifts) == 0 || models.Covered(shifts, orderType, t) {
+		return ""
+	}
+
+	s.log.Warn(ctx, types.ActionWorkerShift, "no kitchen shift covers the order", "location", locationID, "order-type", orderType)
+	return fmt.Sprintf("no kitchen shift covers %s orders at %s", orderType, t.Format("Mon 15:04"))
+}
+
+// notifyLowStock publishes alerts about ingredients running low. The order is already accepted, so failures are only logged.
+func (s *Service) notifyLowStock(ctx context.Context, stock []models.StockItem) {
+	for _, item := range stock {
+		alert := &models.LowStockAlert{
+			LocationID: item.LocationID,
+			Ingredient: item.Ingredient,
+			Unit:       item.Unit,
+			Available:  item.Available,
+			Threshold:  item.LowStockThreshold,
+			Timestamp:  time.Now(),
+		}
+
+		if err := s.notifier.LowStock(ctx, alert); err != nil {
+			s.log.Error(ctx, types.ActionRabbitMQPublishFailed, "failed to publish low stock alert", err, "ingredient", item.Ingredient)
+			continue
+		}
+		s.log.Info(ctx, types.ActionLowStock, "ingredient stock is low", "ingredient", item.Ingredient, "available", item.Available)
+	}
+}
+
+// CancelOrder cancels a scheduled order of the location before it is released to the kitchen.
+func (s *Service) CancelOrder(ctx context.Context, locationID, orderNumber string) error {
+	if locationID == "" {
+		locationID = s.cfg.Location
+	}
+
+	if err := s.orderRepo.CancelScheduled(ctx, locationID, orderNumber, servicename, "cancelled by customer"); err != nil {
+		return err
+	}
+
+	s.log.Info(ctx, types.ActionOrderCancelled, "order cancelled", "order_number", orderNumber, "location_id", locationID)
+	return nil
+}
+
+// ModifyOrder changes items of the order until the kitchen takes it. Totals, discounts and priority are
+// recalculated and the order is republished with a new version, the kitchen skips its old messages.
+func (s *Service) ModifyOrder(ctx context.Context, locationID, orderNumber string, mod models.OrderModification) (*models.Order, error) {
+	if locationID == "" {
+		locationID = s.cfg.Location
+	}
+
+	modify := func(req *models.CreateOrder) (string, error) {
+		notes, err := req.Modify(mod)
+		if err != nil {
+			return "", err
+		}
+
+		req.CalucalteTotalAmount()
+
+		if err := s.applyDeliveryZone(ctx, req); err != nil {
+			return "", err
+		}
+		req.Priority = s.policy.Priority(s.priorityInput(req, time.Now())).Priority
+		if err := s.applyDiscounts(ctx, req, true); err != nil {
+			return "", err
+		}
+
+		s.taxes.Apply(req.Items)
+		req.ApplyCharges(s.cfg.Services.Order.ServiceCharge)
+
+		if s.stations != nil {
+			s.stations.Route(req.Items)
+		}
+
+		return notes, nil
+	}
+
+	publish := func(req *models.CreateOrder) error {
+		return retry(s.cfg.RabbitMQ.ReconnectAttempt, s.cfg.RabbitMQ.ReconnectDelay, func() error {
+			return s.writer.PublishCreateOrder(ctx, req)
+		})
+	}
+
+	order, err := s.orderRepo.Modify(ctx, locationID, orderNumber, servicename, modify, publish)
+	if err != nil {
+		if errors.Is(err, models.ErrOrderNotFound) || errors.Is(err, models.ErrOrderNotModifiable) || errors.Is(err, models.ErrOrderPaid) ||
+			errors.Is(err, models.ErrInvalidModification) || errors.Is(err, models.ErrOutOfStock) {
+			s.log.Error(ctx, types.ActionValidationFailed, "order modification rejected", err, "order_number", orderNumber)
+		} else {
+			s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to modify order", err, "order_number", orderNumber)
+		}
+		return nil, err
+	}
+	s.notifyLowStock(ctx, order.LowStock)
+
+	s.log.Info(ctx, types.ActionOrderModified, "order modified", "order_number", orderNumber, "location_id", locationID, "version", order.Version, "total_amount", order.TotalAmount)
+	return order, nil
+}
+
+// ExplainPriority returns priority the order would get and the rule it comes from, without creating the order.
+func (s *Service) ExplainPriority(ctx context.Context, req *models.CreateOrder) models.PriorityDecision {
+	req.CalucalteTotalAmount()
+
+	// Address out of the zones only leaves the distance unknown, the order is not validated here.
+	if s.zones != nil && req.Type == types.OrderTypeDelivery && req.DeliveryAddress != nil {
+		if loc, err := s.geocoder.Geocode(ctx, *req.DeliveryAddress); err == nil {
+			req.DistanceKm = s.distanceKm(loc)
+		}
+	}
+
+	return s.policy.Priority(s.priorityInput(req, time.Now()))
+}
+
+func (s *Service) priorityInput(req *models.CreateOrder, now time.Time) models.PriorityInput {
+	var items int
+	for _, item := range req.Items {
+		items += item.Quantity
+	}
+
+	// Priority is decided on the gross amount, discounts do not lower it.
+	return models.PriorityInput{
+		TotalAmount: req.Subtotal,
+		OrderType:   req.Type,
+		ItemCount:   items,
+		VIP:         s.isVIP(req.CustomerName),
+		CreatedAt:   now,
+		DistanceKm:  req.DistanceKm,
+	}
+}
+
+// isVIP reports if customer is in the VIP customers list of the config.
+func (s *Service) isVIP(customerName string) bool {
+	for name := range strings.SplitSeq(s.cfg.Services.Order.VIPCustomers, ",") {
+		if strings.EqualFold(strings.TrimSpace(name), customerName) {
+			return true
+		}
+	}
+	return false
 }
 
 // Generate a random number between 10000 and 99999 (inclusive)
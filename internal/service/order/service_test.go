@@ -0,0 +1,56 @@
+package order_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/config"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/adapter/memory"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/service/order"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/semaphore"
+)
+
+const location = "test"
+
+// deps are dependencies of the service under test. Zero fields get defaults: 10 slots, the default priority
+// policy and no delivery zones. Promotions are in-memory, with no codes.
+type deps struct {
+	cfg      config.Config
+	sem      order.Semaphore
+	policy   order.PriorityPolicy
+	zones    *order.DeliveryZones
+	geocoder order.Geocoder
+}
+
+// newTestService creates an order service of the location on in-memory repositories and broker.
+// The returned repository shares the store of the service.
+func newTestService(t *testing.T, d deps) (*order.Service, *memory.OrderRepo) {
+	t.Helper()
+
+	broker := memory.NewBroker()
+	producer, err := memory.NewOrderProducer(broker, "orders_topic", location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier, err := memory.NewNotificationProducer(broker, "notifications_fanout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.cfg.Location = location
+	d.cfg.RabbitMQ.ReconnectAttempt = 1
+	if d.sem == nil {
+		d.sem = semaphore.NewSemaphore(10)
+	}
+	if d.policy == nil {
+		d.policy = order.DefaultPriorityPolicy()
+	}
+
+	store := memory.NewStore()
+	s := order.NewService(d.cfg, memory.NewOrderRepo(store), producer, notifier, d.sem, memory.NewEstimator(time.Minute), d.policy, nil,
+		order.NewTaxTable(0), memory.NewShiftRepo(store), nil, memory.NewPromotionRepo(store), d.zones, d.geocoder, 10*time.Millisecond,
+		logger.InitLogger("order-test", logger.LevelError))
+
+	return s, memory.NewOrderRepo(store)
+}
@@ -0,0 +1,66 @@
+package order
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/configparser"
+)
+
+// StationRouter assigns order items to kitchen stations. Items of no station go to types.DefaultStation.
+type StationRouter struct {
+	stations map[string]string // station by lower case item name
+}
+
+// LoadStations reads station definitions from the YAML file. Every entry has a station name and
+// comma-separated menu items cooked at it.
+func LoadStations(path string) (*StationRouter, error) {
+	records, err := configparser.ReadYamlList(path)
+	if err != nil {
+		return nil, err
+	}
+
+	router, err := ParseStations(records)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return router, nil
+}
+
+func ParseStations(records []map[string]string) (*StationRouter, error) {
+	router := &StationRouter{stations: make(map[string]string)}
+
+	for i, rec := range records {
+		station := rec["station"]
+		if !types.IsValidStationName(station) {
+			return nil, fmt.Errorf("station %d: name %q must be 1-32 lower case letters, digits or '_'", i+1, station)
+		}
+
+		for item := range strings.SplitSeq(rec["items"], ",") {
+			item = strings.ToLower(strings.TrimSpace(item))
+			if item == "" {
+				continue
+			}
+			if other, ok := router.stations[item]; ok {
+				return nil, fmt.Errorf("%s: item %q is already cooked at %s", station, item, other)
+			}
+			router.stations[item] = station
+		}
+	}
+
+	return router, nil
+}
+
+// Route sets station of every item.
+func (r *StationRouter) Route(items []models.CreateOrderItem) {
+	for i := range items {
+		station, ok := r.stations[strings.ToLower(items[i].Name)]
+		if !ok {
+			station = types.DefaultStation
+		}
+		items[i].Station = station
+	}
+}
@@ -0,0 +1,85 @@
+package order
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/configparser"
+)
+
+// TaxTable assigns tax category and rate to order items. Items of no category are models.DefaultTaxCategory.
+type TaxTable struct {
+	categories map[string]string  // category by lower case item name
+	rates      map[string]float64 // rate by category
+}
+
+// NewTaxTable returns table taxing every item with the default rate.
+func NewTaxTable(defaultRate float64) *TaxTable {
+	return &TaxTable{
+		categories: make(map[string]string),
+		rates:      map[string]float64{models.DefaultTaxCategory: defaultRate},
+	}
+}
+
+// LoadTaxRates reads tax categories from the YAML file. Every entry has a category name, its rate in percent
+// and comma-separated menu items of the category.
+func LoadTaxRates(path string, defaultRate float64) (*TaxTable, error) {
+	records, err := configparser.ReadYamlList(path)
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := ParseTaxRates(records, defaultRate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return table, nil
+}
+
+func ParseTaxRates(records []map[string]string, defaultRate float64) (*TaxTable, error) {
+	table := NewTaxTable(defaultRate)
+
+	for i, rec := range records {
+		category := strings.TrimSpace(rec["category"])
+		if category == "" {
+			return nil, fmt.Errorf("category %d: name is required", i+1)
+		}
+		if _, ok := table.rates[category]; ok && category != models.DefaultTaxCategory {
+			return nil, fmt.Errorf("category %q is defined twice", category)
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rec["rate"]), 64)
+		if err != nil || rate < 0 || rate > 100 {
+			return nil, fmt.Errorf("%s: rate %q must be a percent between 0 and 100", category, rec["rate"])
+		}
+		table.rates[category] = rate
+
+		for item := range strings.SplitSeq(rec["items"], ",") {
+			item = strings.ToLower(strings.TrimSpace(item))
+			if item == "" {
+				continue
+			}
+			if other, ok := table.categories[item]; ok {
+				return nil, fmt.Errorf("%s: item %q is already in %s", category, item, other)
+			}
+			table.categories[item] = category
+		}
+	}
+
+	return table, nil
+}
+
+// Apply sets tax category and rate of every item.
+func (t *TaxTable) Apply(items []models.CreateOrderItem) {
+	for i := range items {
+		category, ok := t.categories[strings.ToLower(items[i].Name)]
+		if !ok {
+			category = models.DefaultTaxCategory
+		}
+		items[i].Category = category
+		items[i].TaxRate = t.rates[category]
+	}
+}
@@ -0,0 +1,180 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/configparser"
+)
+
+// DeliveryZones is the list of zones delivery orders are accepted from. An address in several zones belongs to
+// the first one of the list.
+type DeliveryZones struct {
+	zones []models.DeliveryZone
+}
+
+// LoadDeliveryZones reads delivery zones from the YAML file. Every entry has a zone name, either a polygon
+// ("lat lng" points separated by ';') or comma-separated postcodes, and optional fee, min_order and travel_time.
+func LoadDeliveryZones(path string) (*DeliveryZones, error) {
+	records, err := configparser.ReadYamlList(path)
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err := ParseDeliveryZones(records)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return zones, nil
+}
+
+func ParseDeliveryZones(records []map[string]string) (*DeliveryZones, error) {
+	if len(records) == 0 {
+		return nil, errors.New("no delivery zones")
+	}
+
+	zones := &DeliveryZones{}
+	names := make(map[string]bool)
+
+	for i, rec := range records {
+		zone := models.DeliveryZone{Name: strings.TrimSpace(rec["zone"])}
+		if zone.Name == "" {
+			return nil, fmt.Errorf("zone %d: name is required", i+1)
+		}
+		if names[zone.Name] {
+			return nil, fmt.Errorf("zone %q is defined twice", zone.Name)
+		}
+		names[zone.Name] = true
+
+		if polygon := strings.TrimSpace(rec["polygon"]); polygon != "" {
+			points, err := parsePolygon(polygon)
+			if err != nil {
+				return nil, fmt.Errorf("%s: polygon: %w", zone.Name, err)
+			}
+			zone.Polygon = points
+		}
+
+		for postcode := range strings.SplitSeq(rec["postcodes"], ",") {
+			if postcode = strings.TrimSpace(postcode); postcode != "" {
+				zone.Postcodes = append(zone.Postcodes, strings.ToUpper(postcode))
+			}
+		}
+
+		if zone.Polygon == nil && zone.Postcodes == nil {
+			return nil, fmt.Errorf("%s: polygon or postcodes is required", zone.Name)
+		}
+
+		var err error
+		if zone.Fee, err = parseAmount(rec["fee"]); err != nil {
+			return nil, fmt.Errorf("%s: fee %w", zone.Name, err)
+		}
+		if zone.MinOrder, err = parseAmount(rec["min_order"]); err != nil {
+			return nil, fmt.Errorf("%s: min_order %w", zone.Name, err)
+		}
+
+		if travel := strings.TrimSpace(rec["travel_time"]); travel != "" {
+			zone.TravelTime, err = time.ParseDuration(travel)
+			if err != nil || zone.TravelTime < 0 {
+				return nil, fmt.Errorf("%s: travel_time %q must be a duration like 20m", zone.Name, travel)
+			}
+		}
+
+		zones.zones = append(zones.zones, zone)
+	}
+
+	return zones, nil
+}
+
+// parsePolygon parses "lat lng; lat lng; ..." points, at least three.
+func parsePolygon(s string) ([]models.GeoPoint, error) {
+	var points []models.GeoPoint
+	for point := range strings.SplitSeq(s, ";") {
+		p, err := models.ParseGeoPoint(point)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+
+	if len(points) < 3 {
+		return nil, errors.New("at least 3 points are required")
+	}
+	return points, nil
+}
+
+// parseAmount parses optional non-negative money amount.
+func parseAmount(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	amount, err := strconv.ParseFloat(s, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("%q must be a non-negative amount", s)
+	}
+	return models.RoundMoney(amount), nil
+}
+
+// Match returns the first zone containing the location.
+func (z *DeliveryZones) Match(loc models.GeoLocation) (models.DeliveryZone, bool) {
+	for _, zone := range z.zones {
+		if zone.Contains(loc) {
+			return zone, true
+		}
+	}
+	return models.DeliveryZone{}, false
+}
+
+// distanceKm returns distance from the restaurant to the location, nil if either point is unknown.
+func (s *Service) distanceKm(loc models.GeoLocation) *float64 {
+	if s.origin == nil || loc.Point == nil {
+		return nil
+	}
+	distance := s.origin.DistanceKm(*loc.Point)
+	return &distance
+}
+
+// applyDeliveryZone finds delivery zone of the address and sets delivery fee, travel time and distance of the order.
+// Delivery orders are accepted from any address if zones are not configured.
+func (s *Service) applyDeliveryZone(ctx context.Context, req *models.CreateOrder) error {
+	if s.zones == nil || req.Type != types.OrderTypeDelivery || req.DeliveryAddress == nil {
+		return nil
+	}
+
+	loc, err := s.geocoder.Geocode(ctx, *req.DeliveryAddress)
+	if err != nil {
+		if errors.Is(err, models.ErrAddressNotFound) {
+			s.log.Error(ctx, types.ActionValidationFailed, "order rejected", err, "delivery_address", *req.DeliveryAddress)
+			return err
+		}
+		s.log.Error(ctx, types.ActionOrderProccessingFailed, "failed to geocode delivery address", err)
+		return fmt.Errorf("failed to geocode delivery address: %w", err)
+	}
+
+	zone, ok := s.zones.Match(loc)
+	if !ok {
+		s.log.Error(ctx, types.ActionValidationFailed, "order rejected", models.ErrOutsideDeliveryZone, "delivery_address", *req.DeliveryAddress)
+		return models.ErrOutsideDeliveryZone
+	}
+
+	if req.Subtotal < zone.MinOrder {
+		err := fmt.Errorf("%w: %s orders start from %.2f", models.ErrBelowZoneMinimum, zone.Name, zone.MinOrder)
+		s.log.Error(ctx, types.ActionValidationFailed, "order rejected", err)
+		return err
+	}
+
+	req.DeliveryZone = zone.Name
+	req.DeliveryFee = zone.Fee
+	req.TravelTime = zone.TravelTime
+	req.DistanceKm = s.distanceKm(loc)
+
+	return nil
+}
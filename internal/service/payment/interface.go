@@ -0,0 +1,33 @@
+package payment
+
+import (
+	"context"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Repository contracts
+
+type PaymentRepository interface {
+	ClaimDue(ctx context.Context, status string, limit int, lease time.Duration) ([]models.Payment, error)
+	// SaveAuthorization records the provider reference of an authorized payment before its order is moved on,
+	// so the payment is not authorized again if that fails. A payment cancelled meanwhile is refunded.
+	SaveAuthorization(ctx context.Context, paymentID int, provider, providerRef string) error
+	Authorize(ctx context.Context, paymentID int, provider, providerRef, changedBy string, publish func(order *models.CreateOrder) error) (string, error)
+	Fail(ctx context.Context, paymentID int, status, reason, changedBy string) error
+	Retry(ctx context.Context, paymentID int, reason string, delay time.Duration) error
+	Refunded(ctx context.Context, paymentID int) error
+}
+
+// Provider authorizes and refunds payments. Authorize returns an error wrapping models.ErrPaymentDeclined
+// if the payment is declined; any other error is retried.
+type Provider interface {
+	Name() string
+	Authorize(ctx context.Context, req models.PaymentRequest) (ref string, err error)
+	Refund(ctx context.Context, ref string, amount float64) error
+}
+
+type MessageBroker interface {
+	PublishCreateOrder(ctx context.Context, order *models.CreateOrder) error
+}
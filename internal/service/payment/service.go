@@ -0,0 +1,204 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+const servicename = "payment-service"
+
+// Max payments taken in one check, they are processed concurrently.
+const batchSize = 20
+
+// Service authorizes payments of orders waiting in 'pending_payment' and sends the orders to the kitchen,
+// and refunds payments of cancelled orders. State is kept in database only, so several payment-services
+// can run at once.
+type Service struct {
+	repo     PaymentRepository
+	provider Provider
+	writer   MessageBroker
+
+	timeout     time.Duration // time to wait for the provider
+	maxAttempts int
+	retryDelay  time.Duration
+
+	log logger.Logger
+}
+
+func NewService(repo PaymentRepository, provider Provider, writer MessageBroker, timeout time.Duration, maxAttempts int, retryDelay time.Duration, log logger.Logger) *Service {
+	return &Service{
+		repo:        repo,
+		provider:    provider,
+		writer:      writer,
+		timeout:     timeout,
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		log:         log,
+	}
+}
+
+// Run processes due payments and refunds every interval until ctx is done.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.process(ctx, types.PaymentPending, s.authorize)
+			s.process(ctx, types.PaymentRefundPending, s.refund)
+		}
+	}
+}
+
+// process claims due payments of the status and handles them concurrently, batch by batch until none are left.
+func (s *Service) process(ctx context.Context, status string, handle func(ctx context.Context, p models.Payment)) {
+	// Payments are leased for longer than the provider may take, so no other payment-service takes them meanwhile.
+	lease := 2 * s.timeout
+
+	for ctx.Err() == nil {
+		payments, err := s.repo.ClaimDue(ctx, status, batchSize, lease)
+		if err != nil {
+			s.log.Error(ctx, types.ActionDBQueryFailed, "failed to claim payments", err, "status", status)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, p := range payments {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				handle(ctx, p)
+			}()
+		}
+		wg.Wait()
+
+		if len(payments) < batchSize {
+			return
+		}
+	}
+}
+
+// authorize asks the provider to authorize the payment and moves its order on by the answer.
+// A payment the provider has already authorized is not authorized again, its order is only moved on.
+func (s *Service) authorize(ctx context.Context, p models.Payment) {
+	if p.ProviderRef != nil {
+		s.moveOn(ctx, p, *p.ProviderRef)
+		return
+	}
+
+	providerCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	ref, err := s.provider.Authorize(providerCtx, models.PaymentRequest{Reference: p.Reference(), Amount: p.Amount})
+	cancel()
+
+	switch {
+	case err == nil:
+		if err := s.repo.SaveAuthorization(ctx, p.ID, s.provider.Name(), ref); err != nil {
+			// The payment stays pending and is authorized again, so the authorization is voided not to charge twice.
+			s.log.Error(ctx, types.ActionDBQueryFailed, "failed to save payment authorization, voiding it", err, "order_number", p.OrderNumber)
+			s.void(ctx, p, ref)
+			return
+		}
+		s.moveOn(ctx, p, ref)
+
+	case errors.Is(err, models.ErrPaymentDeclined):
+		s.fail(ctx, p, types.PaymentDeclined, err)
+
+	case ctx.Err() != nil:
+		// Shutting down, the payment is retried when its lease passes.
+
+	case p.Attempts >= s.maxAttempts:
+		s.fail(ctx, p, types.PaymentFailed, err)
+
+	default:
+		s.log.Error(ctx, types.ActionPaymentFailed, "payment attempt failed, will retry", err, "order_number", p.OrderNumber, "attempt", p.Attempts)
+		if err := s.repo.Retry(ctx, p.ID, err.Error(), s.retryDelay); err != nil {
+			s.log.Error(ctx, types.ActionDBQueryFailed, "failed to record payment attempt", err, "order_number", p.OrderNumber)
+		}
+	}
+}
+
+// moveOn records the authorized payment and sends its order to the kitchen.
+func (s *Service) moveOn(ctx context.Context, p models.Payment, ref string) {
+	status, err := s.repo.Authorize(ctx, p.ID, s.provider.Name(), ref, servicename, func(order *models.CreateOrder) error {
+		return s.writer.PublishCreateOrder(ctx, order)
+	})
+	if err != nil {
+		// The payment stays pending with the saved reference, its order is moved on when the lease passes.
+		s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to record authorized payment", err, "order_number", p.OrderNumber)
+		return
+	}
+	s.log.Info(ctx, types.ActionPaymentAuthorized, "payment authorized", "order_number", p.OrderNumber, "location_id", p.LocationID, "amount", p.Amount, "order_status", status)
+}
+
+// void refunds the authorization which could not be saved.
+func (s *Service) void(ctx context.Context, p models.Payment, ref string) {
+	providerCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.timeout)
+	defer cancel()
+
+	if err := s.provider.Refund(providerCtx, ref, p.Amount); err != nil {
+		s.log.Error(ctx, types.ActionPaymentFailed, "failed to void payment authorization, refund it by hand", err,
+			"order_number", p.OrderNumber, "provider_ref", ref, "amount", p.Amount)
+	}
+}
+
+// fail records declined or failed payment, its order is cancelled.
+func (s *Service) fail(ctx context.Context, p models.Payment, status string, reason error) {
+	if err := s.repo.Fail(ctx, p.ID, status, reason.Error(), servicename); err != nil {
+		s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to record payment failure", err, "order_number", p.OrderNumber)
+		return
+	}
+	s.log.Error(ctx, types.ActionPaymentFailed, "payment "+status+", order cancelled", reason, "order_number", p.OrderNumber, "location_id", p.LocationID)
+}
+
+// refund refunds payment of a cancelled order. Failed refunds are retried up to max attempts,
+// then the payment is left 'refund_failed' to be refunded by hand.
+func (s *Service) refund(ctx context.Context, p models.Payment) {
+	if p.ProviderRef == nil {
+		// Nothing was charged.
+		if err := s.repo.Fail(ctx, p.ID, types.PaymentCancelled, "no provider reference, nothing to refund", servicename); err != nil {
+			s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to cancel payment", err, "order_number", p.OrderNumber)
+		}
+		return
+	}
+
+	providerCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	err := s.provider.Refund(providerCtx, *p.ProviderRef, p.Amount)
+	cancel()
+
+	if err != nil {
+		switch {
+		case ctx.Err() != nil:
+			// Shutting down, the refund is retried when its lease passes.
+
+		case p.Attempts >= s.maxAttempts:
+			if err := s.repo.Fail(ctx, p.ID, types.PaymentRefundFailed, err.Error(), servicename); err != nil {
+				s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to record refund failure", err, "order_number", p.OrderNumber)
+				return
+			}
+			s.log.Error(ctx, types.ActionPaymentFailed, "refund failed, refund it by hand", err,
+				"order_number", p.OrderNumber, "provider_ref", *p.ProviderRef, "amount", p.Amount)
+
+		default:
+			s.log.Error(ctx, types.ActionPaymentFailed, "refund failed, will retry", err, "order_number", p.OrderNumber, "attempt", p.Attempts)
+			if err := s.repo.Retry(ctx, p.ID, err.Error(), s.retryDelay); err != nil {
+				s.log.Error(ctx, types.ActionDBQueryFailed, "failed to record refund attempt", err, "order_number", p.OrderNumber)
+			}
+		}
+		return
+	}
+
+	if err := s.repo.Refunded(ctx, p.ID); err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to record refund", err, "order_number", p.OrderNumber)
+		return
+	}
+	s.log.Info(ctx, types.ActionPaymentRefunded, "payment refunded", "order_number", p.OrderNumber, "location_id", p.LocationID, "amount", p.Amount)
+}
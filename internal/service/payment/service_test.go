@@ -0,0 +1,187 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// repoStub records calls of the service, saveErr and authorizeErr fail the steps. status is the payment
+// status kept like in the database, orderCancelled tells the order was cancelled while the provider answered.
+type repoStub struct {
+	saveErr        error
+	authorizeErr   error
+	status         string
+	orderCancelled bool
+
+	saved      string
+	authorized string
+	failed     string
+	retried    bool
+	refunded   bool
+}
+
+func (r *repoStub) ClaimDue(context.Context, string, int, time.Duration) ([]models.Payment, error) {
+	return nil, nil
+}
+
+func (r *repoStub) SaveAuthorization(_ context.Context, _ int, _, providerRef string) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	r.saved = providerRef
+	return nil
+}
+
+func (r *repoStub) Authorize(_ context.Context, _ int, _, providerRef, _ string, _ func(order *models.CreateOrder) error) (string, error) {
+	if r.authorizeErr != nil {
+		return "", r.authorizeErr
+	}
+	if r.orderCancelled {
+		if r.status == types.PaymentPending || r.status == types.PaymentCancelled {
+			r.status = types.PaymentRefundPending
+		}
+		return types.StatusOrderCancelled, nil
+	}
+	r.authorized = providerRef
+	return types.StatusOrderReceived, nil
+}
+
+func (r *repoStub) Fail(_ context.Context, _ int, status, _, _ string) error {
+	r.failed = status
+	return nil
+}
+
+func (r *repoStub) Retry(context.Context, int, string, time.Duration) error {
+	r.retried = true
+	return nil
+}
+
+func (r *repoStub) Refunded(context.Context, int) error {
+	r.refunded = true
+	r.status = types.PaymentRefunded
+	return nil
+}
+
+// providerStub approves every payment with reference "ref", refundErr fails refunds.
+type providerStub struct {
+	refundErr error
+
+	authorizations int
+	refunds        []string
+}
+
+func (p *providerStub) Name() string { return "stub" }
+
+func (p *providerStub) Authorize(context.Context, models.PaymentRequest) (string, error) {
+	p.authorizations++
+	return "ref", nil
+}
+
+func (p *providerStub) Refund(_ context.Context, ref string, _ float64) error {
+	p.refunds = append(p.refunds, ref)
+	return p.refundErr
+}
+
+func newTestService(repo *repoStub, provider *providerStub) *Service {
+	return NewService(repo, provider, nil, time.Second, 3, time.Second, logger.InitLogger("payment-test", logger.LevelError))
+}
+
+func TestAuthorizedPaymentIsNotChargedTwice(t *testing.T) {
+	repo := &repoStub{authorizeErr: errors.New("order is locked")}
+	provider := &providerStub{}
+	s := newTestService(repo, provider)
+
+	p := models.Payment{ID: 1, Amount: 10}
+	s.authorize(context.Background(), p)
+	if repo.saved != "ref" || repo.authorized != "" {
+		t.Fatalf("saved %q, authorized %q", repo.saved, repo.authorized)
+	}
+
+	// The payment comes back pending with the saved reference.
+	repo.authorizeErr = nil
+	p.ProviderRef = &repo.saved
+	s.authorize(context.Background(), p)
+	if provider.authorizations != 1 {
+		t.Fatalf("provider authorized %d times, want 1", provider.authorizations)
+	}
+	if repo.authorized != "ref" {
+		t.Fatalf("authorized %q, want ref", repo.authorized)
+	}
+}
+
+func TestUnsavedAuthorizationIsVoided(t *testing.T) {
+	repo := &repoStub{saveErr: errors.New("connection lost")}
+	provider := &providerStub{}
+	s := newTestService(repo, provider)
+
+	s.authorize(context.Background(), models.Payment{ID: 1, Amount: 10})
+	if len(provider.refunds) != 1 || provider.refunds[0] != "ref" {
+		t.Fatalf("refunds %v, want [ref]", provider.refunds)
+	}
+	if repo.authorized != "" {
+		t.Fatal("order of unsaved authorization is moved on")
+	}
+}
+
+func TestRefundedPaymentOfCancelledOrderStaysRefunded(t *testing.T) {
+	repo := &repoStub{authorizeErr: errors.New("order is locked"), status: types.PaymentPending}
+	provider := &providerStub{}
+	s := newTestService(repo, provider)
+
+	p := models.Payment{ID: 1, Amount: 10}
+	s.authorize(context.Background(), p)
+
+	// The order is cancelled with the reference saved, the payment is refunded before its order is moved on.
+	repo.authorizeErr = nil
+	repo.orderCancelled = true
+	repo.status = types.PaymentRefundPending
+	p.ProviderRef = &repo.saved
+	s.refund(context.Background(), p)
+
+	s.authorize(context.Background(), p)
+	if repo.status != types.PaymentRefunded {
+		t.Fatalf("payment is %s, want %s", repo.status, types.PaymentRefunded)
+	}
+	if len(provider.refunds) != 1 {
+		t.Fatalf("refunded %d times, want 1", len(provider.refunds))
+	}
+}
+
+func TestRefund(t *testing.T) {
+	ref := "ref"
+
+	tests := []struct {
+		name         string
+		payment      models.Payment
+		refundErr    error
+		wantFailed   string
+		wantRetry    bool
+		wantRefunded bool
+	}{
+		{"refunded", models.Payment{ProviderRef: &ref, Attempts: 1}, nil, "", false, true},
+		{"retried", models.Payment{ProviderRef: &ref, Attempts: 2}, errors.New("timeout"), "", true, false},
+		{"last attempt", models.Payment{ProviderRef: &ref, Attempts: 3}, errors.New("timeout"), types.PaymentRefundFailed, false, false},
+		{"nothing charged", models.Payment{Attempts: 1}, nil, types.PaymentCancelled, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repoStub{}
+			s := newTestService(repo, &providerStub{refundErr: tt.refundErr})
+
+			s.refund(context.Background(), tt.payment)
+			if repo.failed != tt.wantFailed || repo.retried != tt.wantRetry {
+				t.Fatalf("failed %q retried %v, want failed %q retried %v", repo.failed, repo.retried, tt.wantFailed, tt.wantRetry)
+			}
+			if repo.refunded != tt.wantRefunded {
+				t.Fatalf("refunded %v, want %v", repo.refunded, tt.wantRefunded)
+			}
+		})
+	}
+}
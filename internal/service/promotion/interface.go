@@ -0,0 +1,14 @@
+package promotion
+
+import (
+	"context"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Repository contracts
+
+type PromotionRepo interface {
+	Create(ctx context.Context, p models.Promotion) (models.Promotion, error)
+	List(ctx context.Context) ([]models.Promotion, error)
+}
@@ -0,0 +1,56 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// Service manages promo codes. Codes are applied to orders by order.Service.
+type Service struct {
+	repo PromotionRepo
+
+	log logger.Logger
+}
+
+func NewService(repo PromotionRepo, log logger.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Create creates a promo code. Codes are unique.
+func (s *Service) Create(ctx context.Context, p models.Promotion) (models.Promotion, error) {
+	const op = "Service.Create"
+
+	promotion, err := s.repo.Create(ctx, p)
+	if err != nil {
+		if errors.Is(err, models.ErrPromotionExists) {
+			return models.Promotion{}, err
+		}
+
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to create promotion", err)
+		return models.Promotion{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	s.log.Info(ctx, types.ActionPromotionCreated, "promotion created", "code", promotion.Code, "kind", promotion.Kind)
+	return promotion, nil
+}
+
+// List returns all promo codes, newest first.
+func (s *Service) List(ctx context.Context) ([]models.Promotion, error) {
+	const op = "Service.List"
+
+	promotions, err := s.repo.List(ctx)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to list promotions", err)
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return promotions, nil
+}
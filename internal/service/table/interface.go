@@ -0,0 +1,18 @@
+package table
+
+import (
+	"context"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+)
+
+// Repository contracts
+
+type TableRepo interface {
+	Create(ctx context.Context, t models.Table) (models.Table, error)
+	List(ctx context.Context, locationID string, withOrders bool) ([]models.Table, error)
+	Open(ctx context.Context, locationID string, number, guests int) (models.Table, error)
+	Close(ctx context.Context, locationID string, number int) (models.TableBill, error)
+	Clean(ctx context.Context, locationID string, number int) (models.Table, error)
+	Bill(ctx context.Context, locationID string, number int) (models.TableBill, error)
+}
@@ -0,0 +1,150 @@
+package table
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
+	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/types"
+	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
+)
+
+// Service manages dining tables and their sessions. Dine-in orders are attached to sessions by the order repository.
+type Service struct {
+	repo TableRepo
+
+	log logger.Logger
+}
+
+func NewService(repo TableRepo, log logger.Logger) *Service {
+	return &Service{
+		repo: repo,
+		log:  log,
+	}
+}
+
+// Create adds a table to the location. Table numbers are unique within a location.
+func (s *Service) Create(ctx context.Context, t models.Table) (models.Table, error) {
+	const op = "Service.Create"
+
+	table, err := s.repo.Create(ctx, t)
+	if err != nil {
+		if errors.Is(err, models.ErrTableExists) {
+			return models.Table{}, err
+		}
+
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to create table", err)
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	s.log.Info(ctx, types.ActionTableChanged, "table created", "location_id", table.LocationID, "table", table.Number, "capacity", table.Capacity)
+	return table, nil
+}
+
+// List returns tables of the location with their open sessions.
+func (s *Service) List(ctx context.Context, locationID string) ([]models.Table, error) {
+	const op = "Service.List"
+
+	tables, err := s.repo.List(ctx, locationID, false)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to list tables", err)
+		return nil, fmt.Errorf("%s: %v", op, err)
+	}
+
+	if tables == nil {
+		tables = []models.Table{}
+	}
+
+	return tables, nil
+}
+
+// Floor returns the dining room of the location: tables, their sessions and orders.
+func (s *Service) Floor(ctx context.Context, locationID string) (models.Floor, error) {
+	const op = "Service.Floor"
+
+	tables, err := s.repo.List(ctx, locationID, true)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get floor", err)
+		return models.Floor{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return models.NewFloor(locationID, tables), nil
+}
+
+// Open seats guests at a free table.
+func (s *Service) Open(ctx context.Context, locationID string, number, guests int) (models.Table, error) {
+	const op = "Service.Open"
+
+	table, err := s.repo.Open(ctx, locationID, number, guests)
+	if err != nil {
+		if isTableError(err) {
+			return models.Table{}, err
+		}
+
+		s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to open table", err)
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	s.log.Info(ctx, types.ActionTableChanged, "table opened", "location_id", locationID, "table", number, "guests", guests)
+	return table, nil
+}
+
+// Close closes the session of the table and returns its total bill.
+func (s *Service) Close(ctx context.Context, locationID string, number int) (models.TableBill, error) {
+	const op = "Service.Close"
+
+	bill, err := s.repo.Close(ctx, locationID, number)
+	if err != nil {
+		if isTableError(err) {
+			return models.TableBill{}, err
+		}
+
+		s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to close table", err)
+		return models.TableBill{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	s.log.Info(ctx, types.ActionTableChanged, "table closed", "location_id", locationID, "table", number, "orders", len(bill.Orders), "total", bill.Total)
+	return bill, nil
+}
+
+// Clean marks the table cleaned and free again.
+func (s *Service) Clean(ctx context.Context, locationID string, number int) (models.Table, error) {
+	const op = "Service.Clean"
+
+	table, err := s.repo.Clean(ctx, locationID, number)
+	if err != nil {
+		if isTableError(err) {
+			return models.Table{}, err
+		}
+
+		s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to clean table", err)
+		return models.Table{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	s.log.Info(ctx, types.ActionTableChanged, "table cleaned", "location_id", locationID, "table", number)
+	return table, nil
+}
+
+// Bill returns the bill of the current or last session of the table.
+func (s *Service) Bill(ctx context.Context, locationID string, number int) (models.TableBill, error) {
+	const op = "Service.Bill"
+
+	bill, err := s.repo.Bill(ctx, locationID, number)
+	if err != nil {
+		if isTableError(err) {
+			return models.TableBill{}, err
+		}
+
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get table bill", err)
+		return models.TableBill{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return bill, nil
+}
+
+func isTableError(err error) bool {
+	return errors.Is(err, models.ErrTableNotFound) || errors.Is(err, models.ErrTableNotFree) ||
+		errors.Is(err, models.ErrTableClosed) || errors.Is(err, models.ErrTableNotCleaning) ||
+		errors.Is(err, models.ErrTableCapacity) || errors.Is(err, models.ErrTableNoSessions)
+}
@@ -2,6 +2,7 @@ package tracking
 
 import (
 	"context"
+	"time"
 
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
 )
@@ -9,10 +10,30 @@ import (
 // Repository contracts
 
 type StatusRepo interface {
-	GetCurrent(ctx context.Context, orderNumber string) (models.OrderStatus, error)
-	ListOrderHistory(ctx context.Context, orderNumber string) ([]models.OrderHistory, error)
+	GetCurrent(ctx context.Context, locationID, orderNumber string) (models.OrderStatus, error)
+	ListOrderHistory(ctx context.Context, locationID, orderNumber string) ([]models.OrderHistory, error)
+}
+
+type OrderRepo interface {
+	GetByNumber(ctx context.Context, locationID, orderNumber string) (models.Order, error)
+	List(ctx context.Context, filter models.OrderFilter) ([]models.Order, error)
+}
+
+type ETAEstimator interface {
+	Estimate(ctx context.Context, locationID, orderType string, queuedAt time.Time) (models.ETA, error)
+}
+
+type ShiftRepo interface {
+	List(ctx context.Context, locationID string) ([]models.Shift, error)
+	Replace(ctx context.Context, locationID, workerName string, shifts []models.Shift) error
 }
 
 type WorkerRepo interface {
-	List(ctx context.Context) ([]models.Worker, error)
+	List(ctx context.Context, locationID string) ([]models.Worker, error)
+	Get(ctx context.Context, locationID, name string) (models.Worker, error)
+	Sessions(ctx context.Context, locationID, name string, limit int) ([]models.WorkerSession, error)
+	// EndTimedOutSessions ends open sessions of workers of the location not seen for two of their heartbeat
+	// intervals, or for timeout if they did not report one. Sessions end as timed out when the worker was last seen.
+	EndTimedOutSessions(ctx context.Context, locationID string, timeout time.Duration) error
+	SetCommand(ctx context.Context, locationID, name, command string, onlineSince time.Time) (models.Worker, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/Temutjin2k/wheres-my-pizza/internal/domain/models"
@@ -11,28 +12,44 @@ import (
 	"github.com/Temutjin2k/wheres-my-pizza/pkg/logger"
 )
 
+// workerSessionsLimit is number of latest sessions returned with a worker.
+const workerSessionsLimit = 50
+
 type Service struct {
 	statusRepo   StatusRepo
 	workerRepo   WorkerRepo
-	heartbeatInt int
+	orderRepo    OrderRepo
+	shiftRepo    ShiftRepo
+	estimator    ETAEstimator
+	heartbeatInt atomic.Int64
 
 	log logger.Logger
 }
 
-func NewService(statusRepo StatusRepo, workerRepo WorkerRepo, heartbeatInt int, log logger.Logger) *Service {
-	return &Service{
-		statusRepo:   statusRepo,
-		workerRepo:   workerRepo,
-		heartbeatInt: heartbeatInt,
-		log:          log,
+func NewService(statusRepo StatusRepo, workerRepo WorkerRepo, orderRepo OrderRepo, shiftRepo ShiftRepo, estimator ETAEstimator, heartbeatInt int, log logger.Logger) *Service {
+	s := &Service{
+		statusRepo: statusRepo,
+		workerRepo: workerRepo,
+		orderRepo:  orderRepo,
+		shiftRepo:  shiftRepo,
+		estimator:  estimator,
+		log:        log,
 	}
+	s.heartbeatInt.Store(int64(heartbeatInt))
+
+	return s
+}
+
+// SetHeartbeatInterval changes interval used to detect offline workers.
+func (s *Service) SetHeartbeatInterval(heartbeatInt int) {
+	s.heartbeatInt.Store(int64(heartbeatInt))
 }
 
-// GetOrderStatus — возвращает статус заказа по его номеру.
-func (s *Service) GetOrderStatus(ctx context.Context, orderNumber string) (models.OrderStatus, error) {
+// GetOrderStatus — возвращает статус заказа локации по его номеру.
+func (s *Service) GetOrderStatus(ctx context.Context, locationID, orderNumber string) (models.OrderStatus, error) {
 	const op = "Service.GetOrderStatus"
 
-	statusInfo, err := s.statusRepo.GetCurrent(ctx, orderNumber)
+	statusInfo, err := s.statusRepo.GetCurrent(ctx, locationID, orderNumber)
 	if err != nil {
 		if errors.Is(err, models.ErrOrderNotFound) {
 			return models.OrderStatus{}, models.ErrOrderNotFound
@@ -42,15 +59,29 @@ func (s *Service) GetOrderStatus(ctx context.Context, orderNumber string) (model
 		return models.OrderStatus{}, fmt.Errorf("%s: %v", op, err)
 	}
 
+	// Заказ ещё в очереди — предсказываем время готовности. Без прогноза статус всё равно отдаётся.
+	if statusInfo.Status == types.StatusOrderReceived {
+		eta, err := s.estimator.Estimate(ctx, locationID, statusInfo.OrderType, statusInfo.QueuedAt)
+		if err != nil {
+			s.log.Error(ctx, types.ActionDBQueryFailed, "failed to estimate order ready time", err)
+			return statusInfo, nil
+		}
+		eta.AddTravel(statusInfo.TravelTime)
+		statusInfo.ETA = &eta
+		statusInfo.Completion = &eta.ReadyAt
+	}
+
 	return statusInfo, nil
 }
 
-// ListWorkers — возвращает список работников, задействованных в процессе.
-func (s *Service) ListWorkers(ctx context.Context) ([]models.Worker, error) {
+// ListWorkers — возвращает список работников локации, задействованных в процессе.
+func (s *Service) ListWorkers(ctx context.Context, locationID string) ([]models.Worker, error) {
 	const op = "Service.ListWorkers"
 
+	s.endTimedOutSessions(ctx, locationID)
+
 	now := time.Now()
-	workers, err := s.workerRepo.List(ctx)
+	workers, err := s.workerRepo.List(ctx, locationID)
 	if err != nil {
 		if errors.Is(err, models.ErrWorkerNotFound) {
 			return nil, models.ErrWorkerNotFound
@@ -60,22 +91,151 @@ func (s *Service) ListWorkers(ctx context.Context) ([]models.Worker, error) {
 		return nil, fmt.Errorf("%s: %v", op, err)
 	}
 
-	threshold := time.Duration(s.heartbeatInt) * time.Second
+	// Дежурство по сменам. Без смен список всё равно отдаётся.
+	shifts, err := s.shiftRepo.List(ctx, locationID)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get worker shifts", err)
+	}
+	byWorker := make(map[string][]models.Shift)
+	for _, shift := range shifts {
+		byWorker[shift.WorkerName] = append(byWorker[shift.WorkerName], shift)
+	}
+
 	for i := range workers {
-		if now.Sub(workers[i].LastSeen) > threshold {
-			workers[i].Status = types.WorkerOffline
-		}
-		continue
+		s.setLiveness(&workers[i], now)
+		workers[i].Duty = models.Duty(byWorker[workers[i].Name], now)
 	}
 
 	return workers, nil
 }
 
-// GetTrackingHistory — возвращает историю изменений по заказу.
-func (s *Service) GetTrackingHistory(ctx context.Context, orderNumber string) ([]models.OrderHistory, error) {
+// GetWorker — возвращает воркера локации с историей его сессий.
+func (s *Service) GetWorker(ctx context.Context, locationID, name string) (models.WorkerDetail, error) {
+	const op = "Service.GetWorker"
+
+	s.endTimedOutSessions(ctx, locationID)
+
+	worker, err := s.workerRepo.Get(ctx, locationID, name)
+	if err != nil {
+		if errors.Is(err, models.ErrWorkerNotFound) {
+			return models.WorkerDetail{}, models.ErrWorkerNotFound
+		}
+
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get worker", err)
+		return models.WorkerDetail{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	sessions, err := s.workerRepo.Sessions(ctx, locationID, name, workerSessionsLimit)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get worker sessions", err)
+		return models.WorkerDetail{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	now := time.Now()
+	s.setLiveness(&worker, now)
+
+	shifts, err := s.shiftRepo.List(ctx, locationID)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get worker shifts", err)
+	}
+	var own []models.Shift
+	for _, shift := range shifts {
+		if shift.WorkerName == name {
+			own = append(own, shift)
+		}
+	}
+	worker.Duty = models.Duty(own, now)
+
+	return models.WorkerDetail{Worker: worker, Sessions: sessions}, nil
+}
+
+// setLiveness — помечает offline воркера без heartbeat дольше двух своих интервалов,
+// или дольше интервала из конфига, если воркер его не сообщил.
+func (s *Service) setLiveness(worker *models.Worker, now time.Time) {
+	threshold := time.Duration(s.heartbeatInt.Load()) * time.Second
+	if worker.HeartbeatInterval > 0 {
+		threshold = 2 * time.Duration(worker.HeartbeatInterval) * time.Second
+	}
+
+	if now.Sub(worker.LastSeen) > threshold {
+		worker.Status = types.WorkerOffline
+		worker.ActiveOrders = 0
+		worker.CurrentOrder = nil
+	}
+}
+
+// endTimedOutSessions ends sessions of workers setLiveness takes as offline, so a worker which died without
+// coming back does not stay in session. The workers are still listed, only with an error logged.
+func (s *Service) endTimedOutSessions(ctx context.Context, locationID string) {
+	timeout := time.Duration(s.heartbeatInt.Load()) * time.Second
+	if err := s.workerRepo.EndTimedOutSessions(ctx, locationID, timeout); err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to end timed out worker sessions", err)
+	}
+}
+
+// ListShifts — возвращает смены воркеров локации и окна недели, не покрытые сменами, по типам заказов.
+func (s *Service) ListShifts(ctx context.Context, locationID string) (models.ShiftSchedule, error) {
+	const op = "Service.ListShifts"
+
+	shifts, err := s.shiftRepo.List(ctx, locationID)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get worker shifts", err)
+		return models.ShiftSchedule{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return models.ShiftSchedule{
+		Shifts:    shifts,
+		Uncovered: models.CoverageGaps(shifts, types.AllOrderTypes),
+	}, nil
+}
+
+// SetShifts — заменяет смены воркера и возвращает расписание локации с непокрытыми окнами.
+func (s *Service) SetShifts(ctx context.Context, locationID, workerName string, shifts []models.Shift) (models.ShiftSchedule, error) {
+	const op = "Service.SetShifts"
+
+	if err := s.shiftRepo.Replace(ctx, locationID, workerName, shifts); err != nil {
+		s.log.Error(ctx, types.ActionDBTransactionFailed, "failed to set worker shifts", err)
+		return models.ShiftSchedule{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	schedule, err := s.ListShifts(ctx, locationID)
+	if err != nil {
+		return models.ShiftSchedule{}, err
+	}
+
+	s.log.Info(ctx, types.ActionWorkerShift, "worker shifts changed", "worker-name", workerName, "location", locationID, "shifts", len(shifts))
+	for ot, gaps := range schedule.Uncovered {
+		s.log.Warn(ctx, types.ActionWorkerShift, "order type is not covered by shifts", "location", locationID, "order-type", ot, "gaps", len(gaps))
+	}
+
+	return schedule, nil
+}
+
+// CommandWorker — передаёт воркеру команду администратора. Воркер применяет её на следующем heartbeat,
+// кроме evict: запись сразу помечается offline, чтобы под этим именем мог зарегистрироваться новый воркер.
+func (s *Service) CommandWorker(ctx context.Context, locationID, name, command string) (models.Worker, error) {
+	const op = "Service.CommandWorker"
+
+	threshold := time.Duration(s.heartbeatInt.Load()) * time.Second
+	worker, err := s.workerRepo.SetCommand(ctx, locationID, name, command, time.Now().Add(-threshold))
+	if err != nil {
+		if errors.Is(err, models.ErrWorkerNotFound) || errors.Is(err, models.ErrWorkerOffline) {
+			return models.Worker{}, err
+		}
+
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to set worker command", err)
+		return models.Worker{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	s.log.Info(ctx, types.ActionWorkerCommand, "worker command given", "worker-name", name, "location", locationID, "command", command)
+	return worker, nil
+}
+
+// GetTrackingHistory — возвращает историю изменений по заказу локации.
+func (s *Service) GetTrackingHistory(ctx context.Context, locationID, orderNumber string) ([]models.OrderHistory, error) {
 	const op = "Service.GetTrackingHistory"
 
-	historyList, err := s.statusRepo.ListOrderHistory(ctx, orderNumber)
+	historyList, err := s.statusRepo.ListOrderHistory(ctx, locationID, orderNumber)
 	if err != nil {
 		if errors.Is(err, models.ErrOrderNotFound) {
 			return nil, models.ErrOrderNotFound
@@ -87,3 +247,57 @@ func (s *Service) GetTrackingHistory(ctx context.Context, orderNumber string) ([
 
 	return historyList, nil
 }
+
+// GetOrder — возвращает заказ локации со всеми позициями.
+func (s *Service) GetOrder(ctx context.Context, locationID, orderNumber string) (models.Order, error) {
+	const op = "Service.GetOrder"
+
+	order, err := s.orderRepo.GetByNumber(ctx, locationID, orderNumber)
+	if err != nil {
+		if errors.Is(err, models.ErrOrderNotFound) {
+			return models.Order{}, models.ErrOrderNotFound
+		}
+
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to get order", err)
+		return models.Order{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	return order, nil
+}
+
+// GetReceipt — чек заказа с позициями, скидками, налогами, сервисным сбором и чаевыми.
+func (s *Service) GetReceipt(ctx context.Context, locationID, orderNumber string) (models.Receipt, error) {
+	order, err := s.GetOrder(ctx, locationID, orderNumber)
+	if err != nil {
+		return models.Receipt{}, err
+	}
+
+	return models.NewReceipt(order), nil
+}
+
+// ListOrders — возвращает страницу заказов по фильтру.
+func (s *Service) ListOrders(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+	const op = "Service.ListOrders"
+
+	if filter.Limit <= 0 || filter.Limit > models.MaxOrdersLimit {
+		filter.Limit = models.DefaultOrdersLimit
+	}
+	limit := filter.Limit
+
+	// Fetch one more order to know if there is a next page
+	filter.Limit++
+	orders, err := s.orderRepo.List(ctx, filter)
+	if err != nil {
+		s.log.Error(ctx, types.ActionDBQueryFailed, "failed to list orders", err)
+		return models.OrderPage{}, fmt.Errorf("%s: %v", op, err)
+	}
+
+	page := models.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		page.Next = &models.OrderCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_workers_location;
+
+ALTER TABLE order_sequences DROP CONSTRAINT IF EXISTS order_sequences_pkey;
+DELETE FROM order_sequences WHERE location_id <> 'main';
+ALTER TABLE order_sequences ADD PRIMARY KEY (date);
+ALTER TABLE order_sequences DROP COLUMN IF EXISTS location_id;
+
+ALTER TABLE workers DROP CONSTRAINT IF EXISTS workers_location_name_key;
+ALTER TABLE workers ADD CONSTRAINT workers_name_key UNIQUE (name);
+ALTER TABLE workers DROP COLUMN IF EXISTS location_id;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_location_number_key;
+ALTER TABLE orders ADD CONSTRAINT orders_number_key UNIQUE (number);
+ALTER TABLE orders DROP COLUMN IF EXISTS location_id;
@@ -0,0 +1,23 @@
+-- Every restaurant location is a tenant. Existing data belongs to the 'main' location.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS "location_id" text not null default 'main';
+
+ALTER TABLE workers
+    ADD COLUMN IF NOT EXISTS "location_id" text not null default 'main';
+
+ALTER TABLE order_sequences
+    ADD COLUMN IF NOT EXISTS "location_id" text not null default 'main';
+
+-- Order numbers and worker names are unique per location.
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_number_key;
+ALTER TABLE orders ADD CONSTRAINT orders_location_number_key UNIQUE (location_id, number);
+
+ALTER TABLE workers DROP CONSTRAINT IF EXISTS workers_name_key;
+ALTER TABLE workers ADD CONSTRAINT workers_location_name_key UNIQUE (location_id, name);
+
+-- Order numbers are sequenced per location.
+ALTER TABLE order_sequences DROP CONSTRAINT IF EXISTS order_sequences_pkey;
+ALTER TABLE order_sequences ADD PRIMARY KEY (location_id, date);
+
+-- For ListWorkers scoped by location
+CREATE INDEX IF NOT EXISTS idx_workers_location ON workers(location_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_order_status_log_order_id;
+DROP INDEX IF EXISTS idx_order_items_order_id;
+DROP INDEX IF EXISTS idx_orders_location_customer_name;
+DROP INDEX IF EXISTS idx_orders_location_processed_by;
+DROP INDEX IF EXISTS idx_orders_location_type_created;
+DROP INDEX IF EXISTS idx_orders_location_status_created;
+DROP INDEX IF EXISTS idx_orders_location_created;
@@ -0,0 +1,16 @@
+-- Keyset pagination of GET /orders on (created_at, id), scoped by location
+CREATE INDEX IF NOT EXISTS idx_orders_location_created ON orders(location_id, created_at, id);
+
+-- Most selective filters of GET /orders
+CREATE INDEX IF NOT EXISTS idx_orders_location_status_created ON orders(location_id, status, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_location_type_created ON orders(location_id, type, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_location_processed_by ON orders(location_id, processed_by);
+
+-- Customer name prefix search (LIKE 'abc%')
+CREATE INDEX IF NOT EXISTS idx_orders_location_customer_name ON orders(location_id, customer_name text_pattern_ops);
+
+-- GET /orders/{order_number} loads items of the order
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
+
+-- History and status of an order
+CREATE INDEX IF NOT EXISTS idx_order_status_log_order_id ON order_status_log(order_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_order_status_log_order_status;
+DROP INDEX IF EXISTS idx_order_status_log_status_changed;
@@ -0,0 +1,5 @@
+-- Throughput: status changes of the window, e.g. all 'ready' transitions of the last day
+CREATE INDEX IF NOT EXISTS idx_order_status_log_status_changed ON order_status_log(status, changed_at) INCLUDE (order_id);
+
+-- Durations, workers and SLA: transitions of the orders created in the window
+CREATE INDEX IF NOT EXISTS idx_order_status_log_order_status ON order_status_log(order_id, status) INCLUDE (changed_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_orders_received_created;
+
+ALTER TABLE order_status_log DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,11 @@
+-- Effective priority of the order at the moment of the status change (priority aging promotes waiting orders)
+ALTER TABLE order_status_log ADD COLUMN IF NOT EXISTS priority integer;
+
+UPDATE order_status_log l
+SET priority = o.priority
+FROM orders o
+WHERE o.id = l.order_id
+  AND l.priority IS NULL;
+
+-- Aging looks for orders still waiting in the queue
+CREATE INDEX IF NOT EXISTS idx_orders_received_created ON orders(created_at) WHERE status = 'received';
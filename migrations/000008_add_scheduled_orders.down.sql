@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_orders_scheduled_release;
+DROP INDEX IF EXISTS idx_orders_received_queued;
+CREATE INDEX IF NOT EXISTS idx_orders_received_created ON orders(created_at) WHERE status = 'received';
+
+ALTER TABLE orders DROP COLUMN IF EXISTS queued_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS release_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS scheduled_for;
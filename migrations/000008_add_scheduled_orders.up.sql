@@ -0,0 +1,15 @@
+-- Orders for a future time. A scheduled order is published to the kitchen at release_at,
+-- which is scheduled_for minus expected cook time.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS scheduled_for timestamptz;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS release_at timestamptz;
+
+-- Time the order entered the kitchen queue: creation for regular orders, release for scheduled ones.
+-- Queue position and priority aging are based on it.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS queued_at timestamptz;
+UPDATE orders SET queued_at = created_at WHERE queued_at IS NULL AND status <> 'scheduled';
+
+DROP INDEX IF EXISTS idx_orders_received_created;
+CREATE INDEX IF NOT EXISTS idx_orders_received_queued ON orders(queued_at) WHERE status = 'received';
+
+-- Scheduler looks for scheduled orders due for release
+CREATE INDEX IF NOT EXISTS idx_orders_scheduled_release ON orders(release_at) WHERE status = 'scheduled';
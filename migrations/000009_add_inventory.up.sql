@@ -0,0 +1,31 @@
+-- Ingredients used by one unit of a menu item. Items without recipe are not stock tracked.
+CREATE TABLE IF NOT EXISTS recipes (
+    "item_name"  text          not null,
+    "ingredient" text          not null,
+    "quantity"   numeric(10,3) not null check (quantity > 0),
+    primary key (item_name, ingredient)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipes_item_lower ON recipes(lower(item_name));
+
+-- Ingredient stock of a location. Available stock is quantity - reserved.
+CREATE TABLE IF NOT EXISTS inventory (
+    "location_id"         text          not null,
+    "ingredient"          text          not null,
+    "unit"                text          not null default '',
+    "quantity"            numeric(12,3) not null default 0 check (quantity >= 0),
+    "reserved"            numeric(12,3) not null default 0 check (reserved >= 0),
+    "low_stock_threshold" numeric(12,3) not null default 0,
+    "updated_at"          timestamptz   not null default now(),
+    primary key (location_id, ingredient),
+    check (reserved <= quantity)
+);
+
+-- Stock reserved by an order until it is ready (consumed) or cancelled (released).
+CREATE TABLE IF NOT EXISTS inventory_reservations (
+    "order_id"    integer       not null references orders(id) on delete cascade,
+    "location_id" text          not null,
+    "ingredient"  text          not null,
+    "quantity"    numeric(12,3) not null,
+    primary key (order_id, ingredient)
+);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS station_status_log;
+
+ALTER TABLE workers DROP COLUMN IF EXISTS stations;
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE order_items DROP COLUMN IF EXISTS processed_by;
+ALTER TABLE order_items DROP COLUMN IF EXISTS status;
+ALTER TABLE order_items DROP COLUMN IF EXISTS station;
@@ -0,0 +1,21 @@
+-- Items of an order are cooked by the station they are routed to. Orders without stations
+-- are cooked whole and their items keep 'received' status.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS station text;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS status text not null default 'received';
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS processed_by text;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS updated_at timestamptz not null default now();
+
+-- Stations a kitchen worker cooks for
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS stations text not null default '';
+
+-- Progress of every station of an order, shown in the order history
+CREATE TABLE IF NOT EXISTS station_status_log (
+    "id"          serial        primary key,
+    "order_id"    integer       not null references orders(id) on delete cascade,
+    "station"     text          not null,
+    "status"      text          not null,
+    "changed_by"  text,
+    "changed_at"  timestamptz   not null    default now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_station_status_log_order ON station_status_log(order_id);
@@ -0,0 +1,2 @@
+ALTER TABLE workers DROP COLUMN IF EXISTS command_at;
+ALTER TABLE workers DROP COLUMN IF EXISTS command;
@@ -0,0 +1,4 @@
+-- Admin command waiting for the worker to pick it up on its heartbeat: pause, resume, drain or evict.
+-- Besides 'online' and 'offline', workers report 'paused' and 'draining' status.
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS command text;
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS command_at timestamptz;
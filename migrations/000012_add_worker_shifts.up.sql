@@ -0,0 +1,14 @@
+-- Weekly shifts of kitchen workers in local time of the location. A shift ending at or before
+-- its start runs over midnight. Empty order_types means the shift covers all order types.
+CREATE TABLE IF NOT EXISTS worker_shifts (
+    "id"          serial        primary key,
+    "location_id" text          not null,
+    "worker_name" text          not null,
+    "weekday"     smallint      not null check (weekday between 0 and 6),
+    "starts_at"   time          not null,
+    "ends_at"     time          not null,
+    "order_types" text          not null default '',
+    "created_at"  timestamptz   not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_worker_shifts_worker ON worker_shifts(location_id, worker_name);
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS worker_sessions;
+
+ALTER TABLE workers DROP COLUMN IF EXISTS current_order;
+ALTER TABLE workers DROP COLUMN IF EXISTS active_orders;
+ALTER TABLE workers DROP COLUMN IF EXISTS heartbeat_interval;
+ALTER TABLE workers DROP COLUMN IF EXISTS pid;
+ALTER TABLE workers DROP COLUMN IF EXISTS host;
+ALTER TABLE workers DROP COLUMN IF EXISTS version;
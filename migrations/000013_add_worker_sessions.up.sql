@@ -0,0 +1,26 @@
+-- Details reported by the worker on registration and every heartbeat.
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS version text not null default '';
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS host text not null default '';
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS pid integer not null default 0;
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS heartbeat_interval integer not null default 0;
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS active_orders integer not null default 0;
+ALTER TABLE workers ADD COLUMN IF NOT EXISTS current_order text;
+
+-- Every online period of a worker. Open sessions have no ended_at.
+-- end_reason: graceful, evicted or heartbeat_timeout.
+CREATE TABLE IF NOT EXISTS worker_sessions (
+    "id"               serial        primary key,
+    "location_id"      text          not null,
+    "worker_name"      text          not null,
+    "version"          text          not null default '',
+    "host"             text          not null default '',
+    "pid"              integer       not null default 0,
+    "order_types"      text          not null default '',
+    "stations"         text          not null default '',
+    "started_at"       timestamptz   not null default now(),
+    "ended_at"         timestamptz,
+    "end_reason"       text,
+    "orders_processed" integer       not null default 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_worker_sessions_worker ON worker_sessions(location_id, worker_name, started_at DESC);
@@ -0,0 +1,16 @@
+-- Customer accounts. Orders without customer stay anonymous with customer_name only.
+CREATE TABLE IF NOT EXISTS customers (
+    "id"              serial        primary key,
+    "name"            text          not null,
+    "phone"           text          unique,
+    "email"           text,
+    "default_address" text,
+    "created_at"      timestamptz   not null default now(),
+    "updated_at"      timestamptz   not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_customers_name_lower ON customers(lower(name));
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_id integer references customers(id) on delete set null;
+
+CREATE INDEX IF NOT EXISTS idx_orders_customer ON orders(customer_id, created_at) WHERE customer_id IS NOT NULL;
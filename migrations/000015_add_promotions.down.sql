@@ -0,0 +1,10 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS points_redeemed,
+    DROP COLUMN IF EXISTS points_earned,
+    DROP COLUMN IF EXISTS subtotal;
+
+ALTER TABLE customers DROP COLUMN IF EXISTS loyalty_points;
+
+DROP TABLE IF EXISTS order_discounts;
+
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,43 @@
+-- Promo codes. value is percent (0-100] for 'percent', amount for 'fixed'.
+-- 'buy_x_get_y' gives get_quantity of item_name free for every buy_quantity + get_quantity units.
+CREATE TABLE IF NOT EXISTS promotions (
+    "id"           serial        primary key,
+    "code"         text          not null unique,
+    "kind"         text          not null check (kind in ('percent', 'fixed', 'buy_x_get_y')),
+    "value"        decimal(10,2) not null default 0 check (value >= 0),
+    "item_name"    text,
+    "buy_quantity" integer       not null default 0 check (buy_quantity >= 0),
+    "get_quantity" integer       not null default 0 check (get_quantity >= 0),
+    "max_uses"     integer       check (max_uses > 0),
+    "uses"         integer       not null default 0 check (uses >= 0),
+    "starts_at"    timestamptz,
+    "expires_at"   timestamptz,
+    "created_at"   timestamptz   not null default now(),
+    check (max_uses IS NULL OR uses <= max_uses)
+);
+
+-- Discounts applied to an order, the line-item breakdown of subtotal - total_amount.
+CREATE TABLE IF NOT EXISTS order_discounts (
+    "id"           serial        primary key,
+    "order_id"     integer       not null references orders(id) on delete cascade,
+    "kind"         text          not null, -- promotion kind or 'loyalty'
+    "promotion_id" integer       references promotions(id) on delete set null,
+    "code"         text,
+    "item_name"    text,
+    "description"  text          not null default '',
+    "amount"       decimal(10,2) not null check (amount >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_discounts_order ON order_discounts(order_id);
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS loyalty_points integer not null default 0 check (loyalty_points >= 0);
+
+-- subtotal is the gross amount of the items, total_amount is net of discounts.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS subtotal        decimal(10,2),
+    ADD COLUMN IF NOT EXISTS points_earned   integer not null default 0,
+    ADD COLUMN IF NOT EXISTS points_redeemed integer not null default 0;
+
+UPDATE orders SET subtotal = total_amount WHERE subtotal IS NULL;
+
+ALTER TABLE orders ALTER COLUMN subtotal SET NOT NULL;
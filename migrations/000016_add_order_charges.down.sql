@@ -0,0 +1,8 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS tip_amount,
+    DROP COLUMN IF EXISTS service_charge,
+    DROP COLUMN IF EXISTS tax_amount;
+
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS tax_rate,
+    DROP COLUMN IF EXISTS category;
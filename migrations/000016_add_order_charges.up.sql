@@ -0,0 +1,10 @@
+-- Tax rate and category an item was taxed with, so receipts do not change when rates do.
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS category text          not null default 'general',
+    ADD COLUMN IF NOT EXISTS tax_rate numeric(5,2)  not null default 0 check (tax_rate >= 0);
+
+-- total_amount = subtotal - discounts + tax_amount + service_charge + tip_amount
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS tax_amount     decimal(10,2) not null default 0,
+    ADD COLUMN IF NOT EXISTS service_charge decimal(10,2) not null default 0,
+    ADD COLUMN IF NOT EXISTS tip_amount     decimal(10,2) not null default 0 check (tip_amount >= 0);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payments_due;
+
+DROP TABLE IF EXISTS payments;
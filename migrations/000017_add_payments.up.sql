@@ -0,0 +1,18 @@
+-- Payments of orders waiting in 'pending_payment' status. An order has at most one payment.
+CREATE TABLE IF NOT EXISTS payments (
+    "id"              serial        primary key,
+    "order_id"        integer       not null unique references orders(id) on delete cascade,
+    "status"          text          not null default 'pending' check (status in ('pending', 'authorized', 'declined', 'failed', 'cancelled', 'refund_pending', 'refunded')),
+    "amount"          decimal(10,2) not null check (amount >= 0),
+    "provider"        text          not null default '',
+    "provider_ref"    text,
+    "attempts"        integer       not null default 0,
+    "last_error"      text,
+    "next_attempt_at" timestamptz   not null default now(), -- also the lease of a payment-service working on it
+    "authorized_at"   timestamptz,
+    "refunded_at"     timestamptz,
+    "created_at"      timestamptz   not null default now(),
+    "updated_at"      timestamptz   not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_due ON payments(next_attempt_at) WHERE status IN ('pending', 'refund_pending');
@@ -0,0 +1,4 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS travel_seconds,
+    DROP COLUMN IF EXISTS delivery_fee,
+    DROP COLUMN IF EXISTS delivery_zone;
@@ -0,0 +1,5 @@
+-- Delivery zone of a delivery order, its fee is a part of total_amount.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS delivery_zone  text,
+    ADD COLUMN IF NOT EXISTS delivery_fee   decimal(10,2) not null default 0 check (delivery_fee >= 0),
+    ADD COLUMN IF NOT EXISTS travel_seconds integer       not null default 0 check (travel_seconds >= 0);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_orders_table_session;
+ALTER TABLE orders DROP COLUMN IF EXISTS table_session_id;
+
+DROP TABLE IF EXISTS table_sessions;
+DROP TABLE IF EXISTS tables;
@@ -0,0 +1,28 @@
+-- Dining tables of a location. Dine-in orders of a location with tables must be placed on a table with
+-- an open session; locations without tables accept any table number.
+CREATE TABLE IF NOT EXISTS tables (
+    "id"          serial        primary key,
+    "location_id" text          not null,
+    "number"      integer       not null check (number between 1 and 100),
+    "capacity"    integer       not null check (capacity > 0),
+    "status"      text          not null default 'free' check (status in ('free', 'occupied', 'needs_cleaning')),
+    "created_at"  timestamptz   not null default now(),
+    "updated_at"  timestamptz   not null default now(),
+    unique (location_id, number)
+);
+
+-- Guests seated at a table from opening to closing. A table has at most one open session.
+CREATE TABLE IF NOT EXISTS table_sessions (
+    "id"        serial      primary key,
+    "table_id"  integer     not null references tables(id) on delete cascade,
+    "guests"    integer     not null check (guests > 0),
+    "opened_at" timestamptz not null default now(),
+    "closed_at" timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_table_sessions_open ON table_sessions(table_id) WHERE closed_at IS NULL;
+
+-- Session a dine-in order belongs to, its orders make the combined bill.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS table_session_id integer references table_sessions(id);
+
+CREATE INDEX IF NOT EXISTS idx_orders_table_session ON orders(table_session_id) WHERE table_session_id IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Version of the order, incremented by every modification. Kitchen messages carry it.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS version integer not null default 1 check (version >= 1);
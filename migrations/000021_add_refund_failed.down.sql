@@ -0,0 +1,5 @@
+UPDATE payments SET status = 'refund_pending' WHERE status = 'refund_failed';
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments
+    ADD CONSTRAINT payments_status_check check (status in ('pending', 'authorized', 'declined', 'failed', 'cancelled', 'refund_pending', 'refunded'));
@@ -0,0 +1,5 @@
+-- Refunds that failed after all attempts are refunded by hand.
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments
+    ADD CONSTRAINT payments_status_check check (status in ('pending', 'authorized', 'declined', 'failed', 'cancelled', 'refund_pending', 'refunded', 'refund_failed'));
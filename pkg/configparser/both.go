@@ -1,5 +1,7 @@
 package configparser
 
+import "os"
+
 func LoadAndParseYaml(filepath string, v any) error {
 	if err := LoadYamlFile(filepath); err != nil {
 		return err
@@ -7,3 +9,18 @@ func LoadAndParseYaml(filepath string, v any) error {
 
 	return Parse(v)
 }
+
+// ParseAll reads the yaml file and fills in the struct from flags, environment,
+// yaml and default values. It returns the source of every field that was set.
+func ParseAll(filepath string, flags map[string]string, v any) (map[string]Source, error) {
+	yaml, err := ReadYamlFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSources(v, Sources{
+		Flags: flags,
+		YAML:  yaml,
+		Env:   os.LookupEnv,
+	})
+}
@@ -0,0 +1,71 @@
+package configparser
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadYamlList reads a YAML file which is a list of flat maps, e.g.
+//
+//	- name: large order
+//	  priority: 10
+//	  total_above: 100
+//
+// Keys are lower-cased and values are unquoted. Nested sections are not supported.
+func ReadYamlList(filename string) ([]map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open YAML file: %w", err)
+	}
+	defer file.Close()
+
+	var (
+		items   []map[string]string
+		current map[string]string
+		lineNum int
+	)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNum++
+		content := strings.TrimSpace(scanner.Text())
+
+		// Skip empty lines and comments
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+
+		// "- key: value" starts a new item
+		if rest, ok := strings.CutPrefix(content, "-"); ok {
+			current = make(map[string]string)
+			items = append(items, current)
+			content = strings.TrimSpace(rest)
+			if content == "" {
+				continue
+			}
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("line %d: expected list item starting with '-'", lineNum)
+		}
+
+		key, value, ok := strings.Cut(content, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", lineNum)
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, exists := current[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNum, key)
+		}
+		current[key] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading YAML file: %w", err)
+	}
+
+	return items, nil
+}
@@ -9,12 +9,33 @@ import (
 
 // LoadYamlFile reads a YAML file and loads variables into the environment
 func LoadYamlFile(filename string) error {
+	values, err := ReadYamlFile(filename)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range values {
+		// Set the environment variable
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("could not set env var %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// ReadYamlFile reads a YAML file and returns its values flattened to env-style keys.
+// Nested sections are joined with '_' and upper-cased, so `rabbitmq.reconnect.delay`
+// becomes `RABBITMQ_RECONNECT_DELAY`.
+func ReadYamlFile(filename string) (map[string]string, error) {
 	file, err := os.Open(filename)
 	if err != nil {
-		return fmt.Errorf("could not open YAML file: %w", err)
+		return nil, fmt.Errorf("could not open YAML file: %w", err)
 	}
 	defer file.Close()
 
+	values := make(map[string]string)
+
 	scanner := bufio.NewScanner(file)
 	currentIndent := 0
 	prefixStack := []string{}
@@ -23,7 +44,7 @@ func LoadYamlFile(filename string) error {
 		line := strings.TrimRight(scanner.Text(), " ")
 
 		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+		if line == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
 			continue
 		}
 
@@ -72,16 +93,13 @@ func LoadYamlFile(filename string) error {
 				value = "true" // Default value for empty YAML fields
 			}
 
-			// Set the environment variable
-			if err := os.Setenv(fullKey, value); err != nil {
-				return fmt.Errorf("could not set env var %s: %w", fullKey, err)
-			}
+			values[fullKey] = value
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading YAML file: %w", err)
+		return nil, fmt.Errorf("error reading YAML file: %w", err)
 	}
 
-	return nil
+	return values, nil
 }
@@ -8,89 +8,118 @@ import (
 	"time"
 )
 
+// Source tells where the effective value of a field came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceYAML    Source = "yaml"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Sources holds raw values for every config source. Precedence is flag > env > yaml > default.
+type Sources struct {
+	Flags map[string]string // explicitly set flags, by flag name
+	YAML  map[string]string // flattened yaml values, by env-style key
+	Env   func(key string) (string, bool)
+}
+
 // Parse fills in the struct from environment variables and default values
 func Parse(v any) error {
+	_, err := ParseSources(v, Sources{Env: os.LookupEnv})
+	return err
+}
+
+// ParseSources fills in the struct from the given sources and returns the source
+// of every field that was set, keyed by the field path (e.g. "Services.Order.MaxConcurrent").
+func ParseSources(v any, src Sources) (map[string]Source, error) {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("expected pointer to struct")
+		return nil, fmt.Errorf("expected pointer to struct")
 	}
-	return parseStruct(rv.Elem())
-}
-
-func parseStruct(rv reflect.Value) error {
-	rt := rv.Type()
 
-	for i := range rv.NumField() {
-		field := rv.Field(i)
-		fieldType := rt.Field(i)
-
-		envTag := fieldType.Tag.Get("env")
-		defaultTag := fieldType.Tag.Get("default")
+	fields, err := schema(rv.Elem(), "")
+	if err != nil {
+		return nil, err
+	}
 
-		// If it's a nested struct and doesn't have `env`, parse recursively
-		if field.Kind() == reflect.Struct && envTag == "" {
-			if err := parseStruct(field); err != nil {
-				return err
-			}
-			continue
+	origins := make(map[string]Source, len(fields))
+	for _, f := range fields {
+		val, origin := lookup(f, src)
+		if val == "" {
+			continue // still empty? Skip.
 		}
 
-		if envTag == "" {
-			continue
+		if err := setValue(f.value, val); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Path, err)
 		}
+		origins[f.Path] = origin
+	}
 
-		val, ok := os.LookupEnv(envTag)
+	return origins, nil
+}
 
-		if !ok || val == "" {
-			val = defaultTag // fallback to default if no env var
-		}
-		if val == "" {
-			continue // still empty? Skip.
+// lookup resolves raw value of the field using source precedence.
+func lookup(f Field, src Sources) (string, Source) {
+	if f.Flag != "" {
+		if val, ok := src.Flags[f.Flag]; ok {
+			return val, SourceFlag
 		}
+	}
 
-		if !field.CanSet() {
-			return fmt.Errorf("cannot set field %s", fieldType.Name)
+	if src.Env != nil {
+		if val, ok := src.Env(f.Env); ok && val != "" {
+			return val, SourceEnv
 		}
+	}
+
+	if val, ok := src.YAML[f.Env]; ok && val != "" {
+		return val, SourceYAML
+	}
+
+	return f.Default, SourceDefault
+}
 
-		if fieldType.Type == reflect.TypeOf(time.Duration(0)) {
-			dur, err := time.ParseDuration(val)
-			if err != nil {
-				return fmt.Errorf("failed to parse duration for %s: %v", envTag, err)
-			}
-			field.Set(reflect.ValueOf(dur))
-			continue
+func setValue(field reflect.Value, val string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		dur, err := time.ParseDuration(val)
+		if err != nil {
+			return fmt.Errorf("failed to parse duration: %v", err)
 		}
+		field.Set(reflect.ValueOf(dur))
+		return nil
+	}
 
-		switch field.Kind() {
-		case reflect.String:
-			field.SetString(val)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			n, err := strconv.ParseInt(val, 10, 64)
-			if err != nil {
-				return fmt.Errorf("failed to parse int for %s: %v", envTag, err)
-			}
-			field.SetInt(n)
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			n, err := strconv.ParseUint(val, 10, 64)
-			if err != nil {
-				return fmt.Errorf("failed to parse uint for %s: %v", envTag, err)
-			}
-			field.SetUint(n)
-		case reflect.Bool:
-			b, err := strconv.ParseBool(val)
-			if err != nil {
-				return fmt.Errorf("failed to parse bool for %s: %v", envTag, err)
-			}
-			field.SetBool(b)
-		case reflect.Float32, reflect.Float64:
-			f, err := strconv.ParseFloat(val, 64)
-			if err != nil {
-				return fmt.Errorf("failed to parse float for %s: %v", envTag, err)
-			}
-			field.SetFloat(f)
-		default:
-			return fmt.Errorf("unsupported kind %s for field %s", field.Kind(), fieldType.Name)
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse int: %v", err)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse uint: %v", err)
+		}
+		field.SetUint(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return fmt.Errorf("failed to parse bool: %v", err)
+		}
+		field.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse float: %v", err)
 		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported kind %s", field.Kind())
 	}
 
 	return nil
@@ -0,0 +1,113 @@
+package configparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Field describes a single configurable value. It is built from struct tags:
+//
+//	env     - environment variable name, also the flattened yaml key (required)
+//	flag    - command line flag name (optional)
+//	default - default value (optional)
+//	usage   - help text (optional)
+//	secret  - "true" if the value must be masked when printed (optional)
+//	reload  - "true" if the value can be changed without restart (optional)
+type Field struct {
+	Path       string
+	Env        string
+	Flag       string
+	Default    string
+	Usage      string
+	Secret     bool
+	Reloadable bool
+
+	value reflect.Value
+}
+
+// Schema returns all configurable fields of the struct pointed by v.
+func Schema(v any) ([]Field, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected pointer to struct")
+	}
+	return schema(rv.Elem(), "")
+}
+
+func schema(rv reflect.Value, prefix string) ([]Field, error) {
+	rt := rv.Type()
+
+	var fields []Field
+	for i := range rv.NumField() {
+		field := rv.Field(i)
+		fieldType := rt.Field(i)
+
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		path := fieldType.Name
+		if prefix != "" {
+			path = prefix + "." + fieldType.Name
+		}
+
+		envTag := fieldType.Tag.Get("env")
+
+		// If it's a nested struct and doesn't have `env`, parse recursively
+		if field.Kind() == reflect.Struct && envTag == "" {
+			nested, err := schema(field, path)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+
+		if envTag == "" {
+			continue
+		}
+
+		if !field.CanSet() {
+			return nil, fmt.Errorf("cannot set field %s", path)
+		}
+
+		fields = append(fields, Field{
+			Path:       path,
+			Env:        envTag,
+			Flag:       fieldType.Tag.Get("flag"),
+			Default:    fieldType.Tag.Get("default"),
+			Usage:      fieldType.Tag.Get("usage"),
+			Secret:     fieldType.Tag.Get("secret") == "true",
+			Reloadable: fieldType.Tag.Get("reload") == "true",
+			value:      field,
+		})
+	}
+
+	return fields, nil
+}
+
+// Value returns current value of the field.
+func (f Field) Value() any {
+	return f.value.Interface()
+}
+
+// Set copies value of the same field from other field.
+func (f Field) Set(other Field) {
+	f.value.Set(other.value)
+}
+
+// Section returns the top level group of the field, e.g. "Services.Kitchen" for
+// "Services.Kitchen.Prefetch" and "" for top level fields.
+func (f Field) Section() string {
+	idx := strings.LastIndex(f.Path, ".")
+	if idx < 0 {
+		return ""
+	}
+	return f.Path[:idx]
+}
+
+// Name returns the field name without its section.
+func (f Field) Name() string {
+	return f.Path[strings.LastIndex(f.Path, ".")+1:]
+}
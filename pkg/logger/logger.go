@@ -23,11 +23,13 @@ type Logger interface {
 	Info(ctx context.Context, action, msg string, args ...any)
 	Warn(ctx context.Context, action, msg string, args ...any)
 	Error(ctx context.Context, action, msg string, err error, args ...any)
+	SetLevel(logLevel string)
 	GetSlogLogger() *slog.Logger
 }
 
 type logger struct {
 	slog     *slog.Logger
+	level    *slog.LevelVar
 	service  string
 	hostname string
 }
@@ -40,18 +42,7 @@ func InitLogger(serviceName, logLevel string) Logger {
 	}
 
 	level := new(slog.LevelVar)
-	switch logLevel {
-	case LevelDebug:
-		level.Set(slog.LevelDebug)
-	case LevelInfo:
-		level.Set(slog.LevelInfo)
-	case LevelWarn:
-		level.Set(slog.LevelWarn)
-	case LevelError:
-		level.Set(slog.LevelError)
-	default:
-		level.Set(slog.LevelInfo)
-	}
+	level.Set(parseLevel(logLevel))
 
 	// Custom handler to add request_id and rename message field
 	handler := &contextHandler{
@@ -81,11 +72,27 @@ func InitLogger(serviceName, logLevel string) Logger {
 
 	return &logger{
 		slog:     base,
+		level:    level,
 		service:  serviceName,
 		hostname: hostname,
 	}
 }
 
+func parseLevel(logLevel string) slog.Level {
+	switch logLevel {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
 // Context handler to inject request_id
 type contextHandler struct {
 	handler slog.Handler
@@ -135,6 +142,11 @@ func (l *logger) Error(ctx context.Context, action, msg string, err error, args
 	l.slog.ErrorContext(ctx, msg, attrs...)
 }
 
+// SetLevel changes logger level at runtime.
+func (l *logger) SetLevel(logLevel string) {
+	l.level.Set(parseLevel(logLevel))
+}
+
 func (l *logger) GetSlogLogger() *slog.Logger {
 	return l.slog
 }
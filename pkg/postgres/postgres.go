@@ -14,13 +14,13 @@ type PostgreDB struct {
 }
 
 type Config struct {
-	Host         string `env:"POSTGRES_HOST"`
-	Port         string `env:"POSTGRES_PORT"`
-	User         string `env:"POSTGRES_USER"`
-	Password     string `env:"POSTGRES_PASSWORD"`
-	DBName       string `env:"POSTGRES_DATABASE"`
-	MaxOpenConns int32  `env:"POSTGRES_MAX_OPEN_CONN" default:"25"`
-	MaxIdleTime  string `env:"POSTGRES_MAX_IDLE_TIME" default:"15m"`
+	Host         string `env:"POSTGRES_HOST" flag:"postgres-host" usage:"PostgreSQL host"`
+	Port         string `env:"POSTGRES_PORT" flag:"postgres-port" usage:"PostgreSQL port"`
+	User         string `env:"POSTGRES_USER" flag:"postgres-user" usage:"PostgreSQL user"`
+	Password     string `env:"POSTGRES_PASSWORD" flag:"postgres-password" secret:"true" usage:"PostgreSQL password"`
+	DBName       string `env:"POSTGRES_DATABASE" flag:"postgres-database" usage:"PostgreSQL database name"`
+	MaxOpenConns int32  `env:"POSTGRES_MAX_OPEN_CONN" flag:"postgres-max-open-conn" default:"25" usage:"Max open connections in the pool"`
+	MaxIdleTime  string `env:"POSTGRES_MAX_IDLE_TIME" flag:"postgres-max-idle-time" default:"15m" usage:"Max idle time of a pooled connection"`
 }
 
 func (c Config) GetDsn() string {
@@ -20,10 +20,10 @@ type RabbitMQ struct {
 }
 
 type Config struct {
-	Host     string `env:"RABBITMQ_HOST" default:"localhost"`
-	Port     string `env:"RABBITMQ_PORT" default:"5672"`
-	User     string `env:"RABBITMQ_USER" default:"guest"`
-	Password string `env:"RABBITMQ_PASSWORD" default:"guest"`
+	Host     string `env:"RABBITMQ_HOST" flag:"rabbitmq-host" default:"localhost" usage:"RabbitMQ host"`
+	Port     string `env:"RABBITMQ_PORT" flag:"rabbitmq-port" default:"5672" usage:"RabbitMQ port"`
+	User     string `env:"RABBITMQ_USER" flag:"rabbitmq-user" default:"guest" usage:"RabbitMQ user"`
+	Password string `env:"RABBITMQ_PASSWORD" flag:"rabbitmq-password" default:"guest" secret:"true" usage:"RabbitMQ password"`
 }
 
 func (c Config) GetDSN() string {
@@ -1,51 +1,114 @@
 package semaphore
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 // Semaphore implements a classic counting semaphore pattern for limiting concurrency.
+// Unlike a buffered channel its capacity can be changed at runtime with Resize.
 type Semaphore struct {
-	sem chan struct{}
+	mu   sync.Mutex
+	max  int
+	used int
+	free chan struct{} // closed and replaced each time a permit may have become available
 }
 
 // NewSemaphore creates a new Semaphore with the specified maximum concurrency limit.
 // The max parameter determines how many concurrent Acquire operations can succeed.
 func NewSemaphore(max int) *Semaphore {
 	return &Semaphore{
-		sem: make(chan struct{}, max),
+		max:  max,
+		free: make(chan struct{}),
 	}
 }
 
 // Acquire blocks until a semaphore permit is available.
 // If the semaphore is at max capacity, it will wait until Release is called.
 func (s *Semaphore) Acquire() {
-	s.sem <- struct{}{} // Send to channel (takes a slot)
+	for {
+		free, ok := s.tryAcquire()
+		if ok {
+			return
+		}
+		<-free
+	}
 }
 
 // Release frees a semaphore permit, allowing another Acquire to succeed.
 // It must be called after Acquire to prevent deadlocks.
 func (s *Semaphore) Release() {
-	<-s.sem // Receive from channel (frees a slot)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.used > 0 {
+		s.used--
+	}
+	s.notify()
 }
 
 // TryAcquire attempts to acquire a permit within the specified timeout.
 // Returns true if the permit was acquired, false if the timeout elapsed.
 func (s *Semaphore) TryAcquire(timeout time.Duration) bool {
-	select {
-	case s.sem <- struct{}{}:
-		return true
-	case <-time.After(timeout):
-		return false
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		free, ok := s.tryAcquire()
+		if ok {
+			return true
+		}
+
+		select {
+		case <-free:
+		case <-timer.C:
+			return false
+		}
 	}
 }
 
+// Resize changes the maximum number of permits. When shrinking, permits already in use
+// are not revoked: new Acquire calls wait until usage drops below the new limit.
+func (s *Semaphore) Resize(max int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.max = max
+	s.notify()
+}
+
 // Available returns the number of permits currently available (not in use).
 // This is calculated as (total capacity) - (currently used permits).
 func (s *Semaphore) Available() int {
-	return cap(s.sem) - len(s.sem)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return max(s.max-s.used, 0)
 }
 
 // Used returns the number of permits currently in use.
 // This is equivalent to the number of active Acquire operations not yet Released.
 func (s *Semaphore) Used() int {
-	return len(s.sem)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.used
+}
+
+// tryAcquire takes a permit if possible, otherwise returns channel to wait on.
+func (s *Semaphore) tryAcquire() (<-chan struct{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.used < s.max {
+		s.used++
+		return nil, true
+	}
+	return s.free, false
+}
+
+// notify wakes up all waiters. Must be called with s.mu held.
+func (s *Semaphore) notify() {
+	close(s.free)
+	s.free = make(chan struct{})
 }